@@ -306,3 +306,507 @@
   [ "$status" -eq 1 ]
   [[ "$output" =~ "2 tests, 1 passed, 0 warnings, 1 failure" ]]
 }
+
+@test "Evaluate rules in _test.rego files unless --exclude-test-files is set" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  msg := "denied by a test file"\n}\n' > "$dir/policy_test.rego"
+
+  run ./conftest test -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "denied by a test file" ]]
+
+  run ./conftest test --exclude-test-files -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+}
+
+@test "Pass when failures are within --max-failures" {
+  run ./conftest test --max-failures 4 -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "4 failures, the maximum is 4" ]]
+}
+
+@test "Fail when failures exceed --max-failures" {
+  run ./conftest test --max-failures 3 -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "4 failures, the maximum is 3" ]]
+}
+
+@test "Fail when warnings are in a --fail-on-warn-namespace" {
+  run ./conftest test --fail-on-warn-namespace main -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+
+  run ./conftest test --fail-on-warn-namespace other -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+}
+
+@test "Only report results of --min-severity, but keep the exit code" {
+  run ./conftest test --min-severity high --no-color -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" != *"FAIL"* ]]
+
+  run ./conftest test --min-severity high --default-severity critical --no-color -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "FAIL - examples/kubernetes/deployment.yaml" ]]
+}
+
+@test "Fail when --rule does not exist" {
+  run ./conftest test --rule deny_missing -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "deny_missing" ]]
+}
+
+@test "Can list rules with --list-rules" {
+  run ./conftest test --list-rules -p examples/kubernetes/policy
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "main.deny (failure)" ]]
+  [[ "$output" =~ "main.warn (warning)" ]]
+}
+
+@test "Can print the configuration with --print-config" {
+  run env CONFTEST_NAMESPACE=kubernetes CONFTEST_WEBHOOK_TOKEN=secret ./conftest test --print-config
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "namespace = " ]]
+  [[ "$output" =~ "kubernetes" ]]
+  [[ "$output" =~ "webhook-token = <redacted>" ]]
+  [[ "$output" != *"secret"* ]]
+}
+
+@test "Can set the run id with --run-id and CONFTEST_RUN_ID" {
+  run ./conftest test --run-id 81237 -o json -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "\"run_id\": \"81237\"" ]]
+
+  run env CONFTEST_RUN_ID=4711 ./conftest test -o json -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "\"run_id\": \"4711\"" ]]
+}
+
+@test "Can write the status with --status-file" {
+  dir="$(mktemp -d)"
+  run ./conftest test --status-file "$dir/status.json" -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$(cat "$dir/status.json")" =~ "\"status\": \"failures\"" ]]
+}
+
+@test "Fail with --require-tests when no tests were run" {
+  run ./conftest test --require-tests --namespace notpresent -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "no tests were run" ]]
+}
+
+@test "Can stream YAML documents with --stream" {
+  run bash -c "printf -- '---\nkind: Service\nmetadata:\n  name: a\n---\nkind: Deployment\nmetadata:\n  name: b\n...\n' | ./conftest test --stream -o jsonl -p examples/kubernetes/policy -"
+  [ "$status" -eq 1 ]
+  [ "${#lines[@]}" -eq 2 ]
+  [[ "${lines[0]}" =~ "\"document\":0" ]]
+  [[ "${lines[1]}" =~ "\"document\":1" ]]
+}
+
+@test "Can stream NDJSON documents with --stream" {
+  run bash -c "printf '{\"kind\": \"Service\", \"metadata\": {\"name\": \"a\"}}\n\n{\"kind\": \"Service\", \"metadata\": {\"name\": \"b\"}}' | ./conftest test --stream --parser json -o jsonl -p examples/kubernetes/policy -"
+  [ "$status" -eq 0 ]
+  [ "${#lines[@]}" -eq 2 ]
+}
+
+@test "Fail with --stream when a document cannot be parsed" {
+  run bash -c "printf 'kind: Service\n---\nkind: [\n' | ./conftest test --stream -o jsonl -p examples/kubernetes/policy -"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "parse document 1" ]]
+}
+
+@test "Skip files that are not one of the allowed parsers in directories" {
+  dir="$(mktemp -d)"
+  cp examples/kubernetes/service.yaml "$dir/service.yaml"
+  printf '# Policies\n' > "$dir/README.md"
+  printf '{"kind": "Service"}\n' > "$dir/config.json"
+
+  run ./conftest test --parser yaml,hcl2 -p examples/kubernetes/policy "$dir"
+  [ "$status" -eq 0 ]
+  [[ ! "$output" =~ "not one of the allowed parsers" ]]
+}
+
+@test "Fail with --strict-input on unrecognized files" {
+  run ./conftest test --strict-input -p examples/kubernetes/policy LICENSE
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "LICENSE is not a recognized file type" ]]
+}
+
+@test "Skip unrecognized files in directories with --strict-input" {
+  dir="$(mktemp -d)"
+  cp examples/kubernetes/service.yaml "$dir/service.yaml"
+  cp LICENSE "$dir/LICENSE"
+  printf '# Policies\n' > "$dir/README.md"
+
+  run ./conftest test --strict-input --verbose -p examples/kubernetes/policy "$dir"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "SKIP - $dir/LICENSE - no parser for the file type" ]]
+  [[ "$output" =~ "SKIP - $dir/README.md - no parser for the file type" ]]
+}
+
+@test "Fail with --strict-yaml on duplicate keys" {
+  dir="$(mktemp -d)"
+  printf 'kind: Service\nkind: Deployment\n' > "$dir/duplicate.yaml"
+
+  run ./conftest test -p examples/kubernetes/policy "$dir/duplicate.yaml"
+  [ "$status" -eq 1 ]
+
+  run ./conftest test --strict-yaml -p examples/kubernetes/policy "$dir/duplicate.yaml"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "duplicate keys" ]]
+}
+
+@test "Can render the trace as JSON with --trace-format" {
+  run ./conftest test --trace --trace-format json -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "\"op\":\"Enter\"" ]]
+}
+
+@test "Report skipped files with --verbose" {
+  run ./conftest test --verbose -p examples/kubernetes/policy examples/kubernetes/
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "SKIP - examples/kubernetes/policy/" ]]
+}
+
+@test "Show the impact of a change with --what-if" {
+  dir="$(mktemp -d)"
+  printf '[{"op": "replace", "path": "/kind", "value": "Deployment"}]\n' > "$dir/change.json"
+
+  run ./conftest test --what-if "$dir/change.json" --no-color -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "+ FAIL" ]]
+  [[ "$output" =~ "- WARN" ]]
+}
+
+@test "Can merge overlays with --overlay" {
+  dir="$(mktemp -d)"
+  printf 'kind: Deployment\n' > "$dir/overlay.yaml"
+
+  run ./conftest test --overlay "$dir/overlay.yaml" -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Found deployment hello-kubernetes but deployments are not allowed" ]]
+}
+
+@test "Can combine files into groups with --combine-by" {
+  run ./conftest test -p examples/combine/policy examples/combine/team.yaml examples/combine/user1.yaml examples/combine/user2.yaml --combine-by dir --no-color
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Combined/" ]]
+}
+
+@test "Can combine files of different formats with --combine-keyed" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  count(input.dockerfile) == 1\n  count(input.yaml) == 1\n  msg := "keyed"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test --combine-keyed -p "$dir" examples/docker/Dockerfile examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "keyed" ]]
+}
+
+@test "Can report combined results per file with --split-by-file" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[{"msg": "deployment", "file": input[i].path}] {\n  input[i].contents.kind == "Deployment"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test --combine --split-by-file --no-color -p "$dir" examples/kubernetes/service.yaml examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "FAIL - examples/kubernetes/deployment.yaml - main - deployment" ]]
+}
+
+@test "Can load data per group with --group-data" {
+  dir="$(mktemp -d)"
+  mkdir -p "$dir/policy" "$dir/groups/examples/kubernetes"
+  printf 'package main\n\ndeny[msg] {\n  data.forbidden[_] == input[_].contents.kind\n  msg := "forbidden kind"\n}\n' > "$dir/policy/policy.rego"
+  printf '{"forbidden": ["Service"]}\n' > "$dir/groups/examples/kubernetes/forbidden.json"
+
+  run ./conftest test --combine-by dir --group-data "$dir/groups" -p "$dir/policy" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "forbidden kind" ]]
+
+  run ./conftest test --group-data "$dir/groups" -p "$dir/policy" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "--group-data requires --combine-by" ]]
+}
+
+@test "Warn about large combined inputs with --combine-size-warning" {
+  dir="$(mktemp -d)"
+  head -c 2000000 /dev/zero | tr '\0' 'a' | sed 's/^/key: /' > "$dir/large.yaml"
+
+  run ./conftest test --combine --combine-size-warning 1 -p examples/kubernetes/policy "$dir/large.yaml"
+  [[ "$output" =~ "WARN - combine -" ]]
+
+  run ./conftest test --combine --combine-size-warning 0 -p examples/kubernetes/policy "$dir/large.yaml"
+  [[ "$output" != *"WARN - combine -"* ]]
+}
+
+@test "Can parse CSV files without a header with --csv-no-header" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  input[_][0] == "root"\n  msg := "root user"\n}\n' > "$dir/policy.rego"
+  printf 'root,0\nweb,1000\n' > "$dir/users.csv"
+
+  run ./conftest test --csv-no-header -p "$dir" "$dir/users.csv"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "root user" ]]
+}
+
+@test "Reject remote Dhall imports with --dhall-no-remote" {
+  dir="$(mktemp -d)"
+  printf 'https://example.com/config.dhall\n' > "$dir/config.dhall"
+
+  run ./conftest test --dhall-no-remote -p examples/kubernetes/policy "$dir/config.dhall"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "is not allowed" ]]
+}
+
+@test "Can expand labels with --expand-labels" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  entry := input.metadata.labelEntries[_]\n  entry.prefix == "app.kubernetes.io"\n  entry.name == "part-of"\n  msg := entry.value\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test --expand-labels -p "$dir" examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "wordpress" ]]
+}
+
+@test "Can normalize CIDR blocks with --normalize-cidr" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  input.spec.ingress[_].from[_].ipBlock.cidr == "10.0.0.0/8"\n  msg := "normalized"\n}\n' > "$dir/policy.rego"
+  printf 'kind: NetworkPolicy\nspec:\n  ingress:\n  - from:\n    - ipBlock:\n        cidr: 10.0.0.1/8\n' > "$dir/networkpolicy.yaml"
+
+  run ./conftest test -p "$dir" "$dir/networkpolicy.yaml"
+  [ "$status" -eq 0 ]
+
+  run ./conftest test --normalize-cidr -p "$dir" "$dir/networkpolicy.yaml"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "normalized" ]]
+}
+
+@test "Fail on compiler warnings with --fail-on-compile-warning" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  unused := 1\n  input.kind == "Deployment"\n  msg := "deployment"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+
+  run ./conftest test --fail-on-compile-warning -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "compiler warnings" ]]
+}
+
+@test "Can group the output by rule with --group-by" {
+  run ./conftest test --group-by rule -o json -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "\"rule\"" ]]
+
+  run ./conftest test --group-by unknown -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "unknown group by" ]]
+}
+
+@test "Can route Helm files to namespaces with --helm-namespaces" {
+  dir="$(mktemp -d)"
+  printf 'package values\n\ndeny[msg] {\n  input.replicaCount > 1\n  msg := "too many replicas"\n}\n' > "$dir/values.rego"
+  printf 'replicaCount: 3\n' > "$dir/values.yaml"
+
+  run ./conftest test --helm-namespaces values=values -p "$dir" "$dir/values.yaml"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "too many replicas" ]]
+}
+
+@test "Can add the Helm source to the metadata with --helm-source-comments" {
+  run bash -c "printf -- '---\n# Source: chart/templates/deployment.yaml\n' | cat - examples/kubernetes/deployment.yaml | ./conftest test --helm-source-comments -o json -p examples/kubernetes/policy -"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "\"source\": \"chart/templates/deployment.yaml\"" ]]
+}
+
+@test "Can import Jsonnet libraries with --jsonnet-path" {
+  dir="$(mktemp -d)"
+  mkdir -p "$dir/lib"
+  printf '{ kind: "Deployment", metadata: { name: "lib" } }\n' > "$dir/lib/deployment.libsonnet"
+  printf 'import "deployment.libsonnet"\n' > "$dir/deployment.jsonnet"
+
+  run ./conftest test -p examples/kubernetes/policy "$dir/deployment.jsonnet"
+  [ "$status" -eq 1 ]
+
+  run ./conftest test --jsonnet-path "$dir/lib" -p examples/kubernetes/policy "$dir/deployment.jsonnet"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Found deployment lib but deployments are not allowed" ]]
+}
+
+@test "Can import libraries with --lib" {
+  dir="$(mktemp -d)"
+  mkdir -p "$dir/policy" "$dir/lib"
+  printf 'package lib.kubernetes\n\nis_service {\n  input.kind == "Service"\n}\n\ndeny[msg] {\n  msg := "library rules are not evaluated"\n}\n' > "$dir/lib/kubernetes.rego"
+  printf 'package main\n\nimport data.lib.kubernetes\n\ndeny[msg] {\n  kubernetes.is_service\n  msg := "services are not allowed"\n}\n' > "$dir/policy/policy.rego"
+
+  run ./conftest test --lib "$dir/lib" -p "$dir/policy" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "services are not allowed" ]]
+  [[ "$output" != *"library rules are not evaluated"* ]]
+}
+
+@test "Can override rules with --namespace-fallback" {
+  dir="$(mktemp -d)"
+  printf 'package team\n\ndeny_service[msg] {\n  msg := "team rule"\n}\n' > "$dir/team.rego"
+  printf 'package common\n\ndeny_service[msg] {\n  msg := "common rule"\n}\n' > "$dir/common.rego"
+
+  run ./conftest test --namespace team,common --namespace-fallback -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "team rule" ]]
+  [[ "$output" != *"common rule"* ]]
+}
+
+@test "Can inline nested CloudFormation stacks with --nested-stacks" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  input.Resources[_].NestedTemplate.Resources[_].Type == "AWS::EC2::Instance"\n  msg := "nested instance"\n}\n' > "$dir/policy.rego"
+  printf 'Resources:\n  Network:\n    Type: AWS::CloudFormation::Stack\n    Properties:\n      TemplateURL: https://s3.amazonaws.com/bucket/nested.yaml\n' > "$dir/root.yaml"
+  printf 'Resources:\n  Instance:\n    Type: AWS::EC2::Instance\n' > "$dir/nested.yaml"
+
+  run ./conftest test --nested-stacks "$dir" -p "$dir" "$dir/root.yaml"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "nested instance" ]]
+}
+
+@test "Can silence deprecation warnings with --no-deprecation-warnings" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\nviolation[msg] {\n  input.kind == "Deployment"\n  msg := "deployment"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "DEPRECATED -" ]]
+
+  run ./conftest test --no-deprecation-warnings -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" != *"DEPRECATED -"* ]]
+}
+
+@test "Warn when spans cannot be exported with --otel-endpoint" {
+  run ./conftest test --otel-endpoint http://127.0.0.1:1 -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "WARN - export spans -" ]]
+}
+
+@test "Can write reports with --output-dir" {
+  dir="$(mktemp -d)"
+  run ./conftest test -o junit --output-dir "$dir" -p examples/kubernetes/policy examples/kubernetes/deployment.yaml examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [ -f "$dir/examples_kubernetes_deployment.yaml.xml" ]
+  [ -f "$dir/examples_kubernetes_service.yaml.xml" ]
+}
+
+@test "Can output a JUnit suite per file with --junit-suite-per-file" {
+  run ./conftest test -o junit --junit-suite-per-file -p examples/kubernetes/policy examples/kubernetes/deployment.yaml examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "name=\"examples/kubernetes/deployment.yaml\"" ]]
+  [[ "$output" =~ "name=\"examples/kubernetes/service.yaml\"" ]]
+}
+
+@test "Can read the policy from stdin with --policy-stdin" {
+  run bash -c "printf 'package main\n\ndeny[msg] {\n  input.kind == \"Service\"\n  msg := \"from stdin\"\n}\n' | ./conftest test --policy-stdin examples/kubernetes/service.yaml"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "from stdin" ]]
+}
+
+@test "Can resolve includes with --resolve-includes" {
+  dir="$(mktemp -d)"
+  printf '{"kind": "Deployment", "metadata": {"name": "included"}}\n' > "$dir/common.json"
+  printf '{"$ref": "common.json"}\n' > "$dir/deployment.json"
+
+  run ./conftest test -p examples/kubernetes/policy "$dir/deployment.json"
+  [ "$status" -eq 0 ]
+
+  run ./conftest test --resolve-includes -p examples/kubernetes/policy "$dir/deployment.json"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "Found deployment included but deployments are not allowed" ]]
+}
+
+@test "Reject remote includes unless --allow-remote-includes is set" {
+  dir="$(mktemp -d)"
+  printf '{"$ref": "http://127.0.0.1:1/common.json"}\n' > "$dir/deployment.json"
+
+  run ./conftest test --resolve-includes -p examples/kubernetes/policy "$dir/deployment.json"
+  [ "$status" -eq 1 ]
+
+  run ./conftest test --resolve-includes --allow-remote-includes -p examples/kubernetes/policy "$dir/deployment.json"
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "127.0.0.1:1" ]]
+}
+
+@test "Report builtin errors with --show-builtin-errors" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  config := json.unmarshal("{not json}")\n  msg := "invalid config"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+
+  run ./conftest test --show-builtin-errors --no-color -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "ERROR - examples/kubernetes/service.yaml - main" ]]
+}
+
+@test "Can show the policy root with --show-policy-root" {
+  run ./conftest test --show-policy-root -o json -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "\"policy_root\": \"examples/kubernetes/policy\"" ]]
+}
+
+@test "Can show the policy source with --show-policy-source" {
+  run ./conftest test --show-policy-source -o json -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "\"policy_source\"" ]]
+  [[ "$output" =~ "examples/kubernetes/policy/details.rego" ]]
+}
+
+@test "Can load policies from a bundle with --bundle" {
+  dir="$(mktemp -d)"
+  printf '{}\n' > "$dir/.manifest"
+  printf 'package main\n\ndeny[msg] {\n  input.kind == "Service"\n  msg := "denied by the bundle"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test --bundle "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "denied by the bundle" ]]
+}
+
+@test "Restrict builtins with --capabilities" {
+  dir="$(mktemp -d)"
+  printf 'package main\n\ndeny[msg] {\n  http.send({"method": "get", "url": "http://127.0.0.1:1"})\n  msg := "sent"\n}\n' > "$dir/policy.rego"
+
+  run ./conftest test --capabilities safe -p "$dir" examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "http.send" ]]
+}
+
+@test "Can add the author of failures with --blame" {
+  git rev-parse --is-inside-work-tree || skip "not a git checkout"
+
+  run ./conftest test --blame -o json -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "\"blame\"" ]]
+}
+
+@test "Fail when the webhook cannot be reached unless --webhook-no-fail is set" {
+  run ./conftest test --webhook http://127.0.0.1:1 -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+
+  run ./conftest test --webhook http://127.0.0.1:1 --webhook-no-fail --webhook-content-type application/x-ndjson -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "1 warning" ]]
+}
+
+@test "Only send results to the webhook with --webhook-only" {
+  run ./conftest test --webhook http://127.0.0.1:1 --webhook-no-fail --webhook-only --webhook-token secret --no-color -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 0 ]
+  [[ "$output" != *"1 warning"* ]]
+}
+
+@test "Fail when --webhook-user is not a username and password" {
+  run ./conftest test --webhook http://127.0.0.1:1 --webhook-user admin -p examples/kubernetes/policy examples/kubernetes/service.yaml
+  [ "$status" -eq 1 ]
+  [[ "$output" =~ "webhook user must be given as <username>:<password>" ]]
+}
+
+@test "Skip .conf files that are neither INI nor YAML in directories" {
+  dir="$(mktemp -d)"
+  printf 'user nginx;\nworker_processes auto;\n' > "$dir/nginx.conf"
+  printf '[server]\nport = 8080\n' > "$dir/app.conf"
+
+  run ./conftest test --verbose -p examples/kubernetes/policy "$dir"
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "nginx.conf - the contents are not in a format that the parser for the file type parses" ]]
+}
@@ -5,11 +5,13 @@ go 1.16
 require (
 	cloud.google.com/go v0.60.0 // indirect
 	cuelang.org/go v0.4.0
-	github.com/BurntSushi/toml v0.3.1
+	github.com/BurntSushi/toml v0.4.1
 	github.com/KeisukeYamashita/go-vcl v0.4.0
 	github.com/aws/aws-sdk-go v1.36.30 // indirect
 	github.com/basgys/goxml2json v1.1.0
+	github.com/containerd/containerd v1.4.4
 	github.com/deislabs/oras v0.11.1
+	github.com/emicklei/proto v1.9.1
 	github.com/ghodss/yaml v1.0.0
 	github.com/go-akka/configuration v0.0.0-20200606091224-a002c0330665
 	github.com/go-ini/ini v1.62.0
@@ -28,12 +30,15 @@ require (
 	github.com/spf13/cobra v1.1.3
 	github.com/spf13/viper v1.7.1
 	github.com/tmccombs/hcl2json v0.3.1
+	github.com/yosuke-furukawa/json5 v0.1.1
 	go.opencensus.io v0.22.4 // indirect
 	golang.org/x/crypto v0.0.0-20210220033148-5ea612d1eb83 // indirect
 	golang.org/x/lint v0.0.0-20201208152925-83fdc39ff7b5 // indirect
 	golang.org/x/tools v0.1.2-0.20210512205948-8287d5da45e4 // indirect
 	google.golang.org/api v0.29.0 // indirect
 	google.golang.org/genproto v0.0.0-20200707001353-8e8330bf89df // indirect
+	gopkg.in/yaml.v2 v2.4.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 	olympos.io/encoding/edn v0.0.0-20201019073823-d3554ca0b0a3
 	rsc.io/letsencrypt v0.0.3 // indirect
 )
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/open-policy-agent/conftest/plugin"
 
@@ -29,10 +30,9 @@ func NewDefaultCommand() *cobra.Command {
 
 	cmd.SetVersionTemplate(`{{.Version}}`)
 
-	viper.SetEnvPrefix("CONFTEST")
 	viper.SetConfigName("conftest")
 	viper.AddConfigPath(".")
-	viper.AutomaticEnv()
+	configureEnv(viper.GetViper())
 
 	logger := log.New(os.Stdout, "", log.LstdFlags)
 	ctx := context.Background()
@@ -88,3 +88,12 @@ func loadPlugins(ctx context.Context) ([]*cobra.Command, error) {
 
 	return cmds, nil
 }
+
+// configureEnv makes the options readable from environment variables that are prefixed
+// with CONFTEST_, where the dashes of the option are underscores (e.g. CONFTEST_RUN_ID
+// for run-id). Flags that are set take precedence over the environment variables.
+func configureEnv(v *viper.Viper) {
+	v.SetEnvPrefix("CONFTEST")
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+}
@@ -6,8 +6,13 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"os"
+	"os/exec"
 	"strings"
+	"time"
 
+	"github.com/containerd/containerd/images"
+	"github.com/containerd/containerd/remotes"
 	auth "github.com/deislabs/oras/pkg/auth/docker"
 	"github.com/deislabs/oras/pkg/content"
 	orascontext "github.com/deislabs/oras/pkg/context"
@@ -42,6 +47,22 @@ The policy location defaults to the policy directory in the local folder.
 The location can be overridden with the '--policy' flag, e.g.:
 
 	$ conftest push --policy <my-directory> url
+
+When the upload of a large bundle fails, for example because the connection dropped,
+the '--retries' flag retries the push. The registry skips the layers that were already
+uploaded by an earlier attempt, so only the missing layers are uploaded again, e.g.:
+
+	$ conftest push --retries 3 instrumenta.azurecr.io/my-registry:v1
+
+The '--sign' flag signs the pushed bundle with cosign (https://github.com/sigstore/cosign),
+which needs to be installed and on the PATH, so that consumers can verify where the bundle came from.
+The bundle is signed with the key given with the '--key' flag, or with keyless signing
+through an OIDC provider when no key is given. cosign signs the bundle in the registry,
+so the bundle is pushed before it is signed. When signing fails, the bundle stays pushed
+without a signature, and the command fails with the reference of the unsigned bundle, e.g.:
+
+	$ conftest push --sign --key cosign.key instrumenta.azurecr.io/my-registry:v1
+	$ conftest push --sign instrumenta.azurecr.io/my-registry:v1
 `
 
 const (
@@ -58,8 +79,11 @@ func NewPushCommand(ctx context.Context, logger *log.Logger) *cobra.Command {
 		Short: "Push OPA bundles to an OCI registry",
 		Long:  pushDesc,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			if err := viper.BindPFlag("policy", cmd.Flags().Lookup("policy")); err != nil {
-				return fmt.Errorf("bind flag: %w", err)
+			flagNames := []string{"key", "policy", "retries", "sign"}
+			for _, name := range flagNames {
+				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
+					return fmt.Errorf("bind flag: %w", err)
+				}
 			}
 
 			return nil
@@ -92,8 +116,22 @@ func NewPushCommand(ctx context.Context, logger *log.Logger) *cobra.Command {
 				repository = repository + ":latest"
 			}
 
+			if viper.GetString("key") != "" && !viper.GetBool("sign") {
+				return errors.New("the key flag can only be used together with the sign flag")
+			}
+
+			// cosign signs the bundle in the registry, so it can only run after the push.
+			// Checking what signing needs before pushing avoids pushing a bundle that
+			// cannot be signed at all, but a bundle is still pushed unsigned when
+			// signing itself fails.
+			if viper.GetBool("sign") {
+				if err := checkSigning(viper.GetString("key")); err != nil {
+					return fmt.Errorf("sign: %w", err)
+				}
+			}
+
 			logger.Printf("pushing bundle to: %s", repository)
-			manifest, err := pushBundle(ctx, repository, viper.GetString("policy"))
+			manifest, err := pushBundle(ctx, logger, repository, viper.GetString("policy"), viper.GetInt("retries"), viper.GetBool("sign"), viper.GetString("key"))
 			if err != nil {
 				return fmt.Errorf("push bundle: %w", err)
 			}
@@ -104,11 +142,14 @@ func NewPushCommand(ctx context.Context, logger *log.Logger) *cobra.Command {
 	}
 
 	cmd.Flags().StringP("policy", "p", "policy", "Directory to push as a bundle")
+	cmd.Flags().Int("retries", 0, "Number of times to retry the push when it fails, skipping the layers that were already uploaded")
+	cmd.Flags().Bool("sign", false, "Sign the pushed bundle with cosign, which must be on the PATH, using keyless signing unless a key is given")
+	cmd.Flags().String("key", "", "Path or KMS URI of the cosign key to sign the bundle with")
 
 	return &cmd
 }
 
-func pushBundle(ctx context.Context, repository string, path string) (*ocispec.Descriptor, error) {
+func pushBundle(ctx context.Context, logger *log.Logger, repository string, path string, retries int, sign bool, key string) (*ocispec.Descriptor, error) {
 	cli, err := auth.NewClient()
 	if err != nil {
 		return nil, fmt.Errorf("get auth client: %w", err)
@@ -125,13 +166,120 @@ func pushBundle(ctx context.Context, repository string, path string) (*ocispec.D
 		return nil, fmt.Errorf("building layers: %w", err)
 	}
 
-	extraOpts := []oras.PushOpt{oras.WithConfigMediaType(openPolicyAgentConfigMediaType)}
-	manifest, err := oras.Push(ctx, resolver, repository, memoryStore, layers, extraOpts...)
+	extraOpts := []oras.PushOpt{
+		oras.WithConfigMediaType(openPolicyAgentConfigMediaType),
+		oras.WithPushBaseHandler(layerStatusHandler(logger)),
+	}
+
+	// Before uploading a layer, the registry is asked whether it already has a blob
+	// with the same digest. This means that a retried push only uploads the layers
+	// that were not uploaded by an earlier attempt.
+	for attempt := 0; ; attempt++ {
+		manifest, err := oras.Push(ctx, resolver, repository, memoryStore, layers, extraOpts...)
+		if err == nil {
+			if sign {
+				if err := signBundle(ctx, logger, resolver, repository, manifest, key); err != nil {
+					return nil, fmt.Errorf("sign: the bundle was pushed as %s, but is not signed: %w", repositoryWithDigest(repository, manifest.Digest.String()), err)
+				}
+			}
+
+			return &manifest, nil
+		}
+
+		if attempt >= retries {
+			return nil, fmt.Errorf("pushing manifest: %w", err)
+		}
+
+		delay := time.Duration(attempt+1) * time.Second
+		logger.Printf("push failed, retrying in %s (attempt %d of %d): %v", delay, attempt+2, retries+1, err)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("pushing manifest: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// checkSigning returns an error when a bundle cannot be signed with the given key,
+// because cosign is not installed or the key file does not exist. Keys that are a
+// KMS URI, such as awskms:///alias/conftest, are resolved by cosign itself.
+func checkSigning(key string) error {
+	if key != "" && !strings.Contains(key, "://") {
+		if _, err := os.Stat(key); err != nil {
+			return fmt.Errorf("key: %w", err)
+		}
+	}
+
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return fmt.Errorf("cosign must be installed and on the PATH to sign bundles: %w", err)
+	}
+
+	return nil
+}
+
+// signBundle signs the pushed manifest with cosign, which stores the signature next
+// to the bundle in the repository. The signature is signed with the given key, or
+// with keyless signing when no key is given, where cosign gets a certificate for
+// the identity of an OIDC provider. The reference and digest of the signature are
+// logged, so that they can be recorded.
+func signBundle(ctx context.Context, logger *log.Logger, resolver remotes.Resolver, repository string, manifest ocispec.Descriptor, key string) error {
+	reference := repositoryWithDigest(repository, manifest.Digest.String())
+
+	args := []string{"sign", "--yes"}
+	if key != "" {
+		args = append(args, "--key", key)
+	}
+	args = append(args, reference)
+
+	// Keyless signing can ask the user to authenticate in the browser, and a key
+	// can ask for its password, so cosign can interact with the terminal.
+	cmd := exec.CommandContext(ctx, "cosign", args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run cosign sign: %w", err)
+	}
+
+	out, err := exec.CommandContext(ctx, "cosign", "triangulate", reference).Output()
+	if err != nil {
+		return fmt.Errorf("run cosign triangulate: %w", err)
+	}
+
+	signature := strings.TrimSpace(string(out))
+	_, descriptor, err := resolver.Resolve(ctx, signature)
 	if err != nil {
-		return nil, fmt.Errorf("pushing manifest: %w", err)
+		return fmt.Errorf("resolve signature: %w", err)
 	}
 
-	return &manifest, nil
+	logger.Printf("signed bundle with signature: %s (digest: %s)", signature, descriptor.Digest)
+	return nil
+}
+
+// repositoryWithDigest replaces the tag of the repository with the given digest,
+// so that the exact manifest that was pushed is referenced, rather than the tag.
+func repositoryWithDigest(repository string, digest string) string {
+	slash := strings.LastIndex(repository, "/")
+	if colon := strings.LastIndex(repository, ":"); colon > slash {
+		repository = repository[:colon]
+	}
+
+	return repository + "@" + digest
+}
+
+// layerStatusHandler logs every layer of the bundle, as well as its config and manifest,
+// when it is pushed, so that the progress of a push and of its retries can be followed.
+func layerStatusHandler(logger *log.Logger) images.HandlerFunc {
+	return func(ctx context.Context, descriptor ocispec.Descriptor) ([]ocispec.Descriptor, error) {
+		name := descriptor.Annotations[ocispec.AnnotationTitle]
+		if name == "" {
+			name = descriptor.MediaType
+		}
+
+		logger.Printf("pushing %s (%s, %d bytes)", name, descriptor.Digest, descriptor.Size)
+		return nil, nil
+	}
 }
 
 func buildLayers(ctx context.Context, memoryStore *content.Memorystore, path string) ([]ocispec.Descriptor, error) {
@@ -0,0 +1,170 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/containerd/containerd/remotes"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestRepositoryWithDigest(t *testing.T) {
+	const digest = "sha256:3b1f"
+
+	testCases := []struct {
+		repository string
+		expected   string
+	}{
+		{repository: "instrumenta.azurecr.io/my-registry:v1", expected: "instrumenta.azurecr.io/my-registry@sha256:3b1f"},
+		{repository: "instrumenta.azurecr.io/my-registry", expected: "instrumenta.azurecr.io/my-registry@sha256:3b1f"},
+		{repository: "localhost:5000/repo", expected: "localhost:5000/repo@sha256:3b1f"},
+		{repository: "localhost:5000/repo:latest", expected: "localhost:5000/repo@sha256:3b1f"},
+		{repository: "localhost:5000/org/repo:v1", expected: "localhost:5000/org/repo@sha256:3b1f"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.repository, func(t *testing.T) {
+			if actual := repositoryWithDigest(testCase.repository, digest); actual != testCase.expected {
+				t.Errorf("unexpected reference. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+// fakeResolver resolves every reference to a descriptor with the same digest.
+type fakeResolver struct {
+	resolved []string
+}
+
+func (r *fakeResolver) Resolve(ctx context.Context, ref string) (string, ocispec.Descriptor, error) {
+	r.resolved = append(r.resolved, ref)
+	return ref, ocispec.Descriptor{Digest: "sha256:9c2e"}, nil
+}
+
+func (r *fakeResolver) Fetcher(ctx context.Context, ref string) (remotes.Fetcher, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeResolver) Pusher(ctx context.Context, ref string) (remotes.Pusher, error) {
+	return nil, errors.New("not implemented")
+}
+
+// writeFakeCosign writes a cosign script to the PATH that records its arguments,
+// and fails when signing if fail is set.
+func writeFakeCosign(t *testing.T, fail bool) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake cosign is a shell script")
+	}
+
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	exitCode := "0"
+	if fail {
+		exitCode = "1"
+	}
+
+	script := `#!/bin/sh
+echo "$@" >> "` + argsFile + `"
+if [ "$1" = "triangulate" ]; then
+	echo "localhost:5000/repo:sha256-3b1f.sig"
+	exit 0
+fi
+exit ` + exitCode + "\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "cosign"), []byte(script), 0700); err != nil {
+		t.Fatalf("write cosign: %v", err)
+	}
+
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", dir+string(os.PathListSeparator)+path)
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+
+	return argsFile
+}
+
+func TestSignBundle(t *testing.T) {
+	argsFile := writeFakeCosign(t, false)
+
+	var logs bytes.Buffer
+	resolver := fakeResolver{}
+	manifest := ocispec.Descriptor{Digest: "sha256:3b1f"}
+	if err := signBundle(context.Background(), log.New(&logs, "", 0), &resolver, "localhost:5000/repo", manifest, "cosign.key"); err != nil {
+		t.Fatalf("sign bundle: %v", err)
+	}
+
+	args, err := ioutil.ReadFile(argsFile)
+	if err != nil {
+		t.Fatalf("read cosign arguments: %v", err)
+	}
+
+	expected := "sign --yes --key cosign.key localhost:5000/repo@sha256:3b1f\ntriangulate localhost:5000/repo@sha256:3b1f\n"
+	if string(args) != expected {
+		t.Errorf("unexpected cosign arguments. expected %q actual %q", expected, string(args))
+	}
+
+	if len(resolver.resolved) != 1 || resolver.resolved[0] != "localhost:5000/repo:sha256-3b1f.sig" {
+		t.Errorf("expected the signature to be resolved, resolved %v", resolver.resolved)
+	}
+
+	if !strings.Contains(logs.String(), "localhost:5000/repo:sha256-3b1f.sig (digest: sha256:9c2e)") {
+		t.Errorf("expected the signature to be logged, got: %s", logs.String())
+	}
+}
+
+func TestSignBundleFails(t *testing.T) {
+	writeFakeCosign(t, true)
+
+	resolver := fakeResolver{}
+	manifest := ocispec.Descriptor{Digest: "sha256:3b1f"}
+	err := signBundle(context.Background(), log.New(ioutil.Discard, "", 0), &resolver, "localhost:5000/repo:v1", manifest, "")
+	if err == nil {
+		t.Fatal("expected an error when cosign fails")
+	}
+
+	if len(resolver.resolved) != 0 {
+		t.Errorf("expected no signature to be resolved, resolved %v", resolver.resolved)
+	}
+}
+
+func TestCheckSigning(t *testing.T) {
+	emptyDir := t.TempDir()
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", emptyDir)
+	t.Cleanup(func() { os.Setenv("PATH", path) })
+
+	keyFile := filepath.Join(t.TempDir(), "cosign.key")
+	if err := ioutil.WriteFile(keyFile, []byte("key"), 0600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	testCases := []struct {
+		desc     string
+		key      string
+		expected string
+	}{
+		{desc: "missing key file", key: filepath.Join(emptyDir, "missing.key"), expected: "key:"},
+		{desc: "missing cosign with a key file", key: keyFile, expected: "cosign must be installed"},
+		{desc: "missing cosign with a KMS key", key: "awskms:///alias/conftest", expected: "cosign must be installed"},
+		{desc: "missing cosign with keyless signing", expected: "cosign must be installed"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.desc, func(t *testing.T) {
+			err := checkSigning(testCase.key)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("unexpected error. expected %q actual %v", testCase.expected, err)
+			}
+		})
+	}
+}
@@ -2,12 +2,22 @@ package commands
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/open-policy-agent/conftest/internal/runner"
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/policy"
 	"github.com/open-policy-agent/opa/ast"
 	"github.com/open-policy-agent/opa/storage"
 	"github.com/spf13/cobra"
@@ -30,6 +40,16 @@ The location can be overridden with the '--policy' flag, e.g.:
 
 	$ conftest test --policy <my-directory> <input-file(s)/input-folder>
 
+A single policy can also be read from standard input with the '--policy-stdin' flag, 
+in which case the input cannot be read from standard input, e.g.:
+
+	$ cat policy.rego | conftest test --policy-stdin <input-file>
+
+Policies and data can also be loaded from bundles built with 'opa build', either as 
+a tarball or as a directory, with the '--bundle' flag, e.g.:
+
+	$ conftest test --bundle bundle.tar.gz <input-file>
+
 Some policies are dependant on external data. This data is loaded in seperatly 
 from policies. The location of any data directory or file can be specified with 
 the '--data' flag. If a directory is specified, it will be recursively searched for 
@@ -42,6 +62,13 @@ under 'policy/exceptions/my_data.yaml', and we execute the following command:
 
 The data is available under 'import data.exceptions'.
 
+Policies can import shared libraries that live outside of the policy directory.
+The location of these libraries can be specified with the '--lib' flag. Libraries
+are compiled together with the policies, but the rules inside of them are never
+evaluated directly, e.g.:
+
+	$ conftest test --policy <my-directory> --lib <library-directory> <input-file>
+
 The test command supports the '--output' flag to specify the type, e.g.:
 
 	$ conftest test -o table -p examples/kubernetes/policy examples/kubernetes/deployment.yaml
@@ -64,10 +91,29 @@ It expects one or more urls to fetch the latest policies from, e.g.:
 
 See the pull command for more details on supported protocols for fetching policies.
 
+Policies from third parties can be sandboxed by restricting the builtins they are 
+allowed to use with the '--capabilities' flag. The 'safe' profile disables the 
+builtins that make network calls or read the environment (http.send and opa.runtime). 
+A path to an OPA capabilities JSON file can be given instead. Policies that use a 
+builtin that is not allowed fail to compile.
+
+	$ conftest test --capabilities safe <input-file>
+
+The templates of nested CloudFormation stacks can be inlined into the stack resources 
+of the root template with the '--nested-stacks' flag, which is given the directory 
+that contains the nested templates, e.g.:
+
+	$ conftest test --nested-stacks templates templates/root.yaml
+
 When debugging policies it can be useful to use a more verbose policy evaluation output. By using the '--trace' flag
 the output will include a detailed trace of how the policy was evaluated, e.g.
 
 	$ conftest test --trace <input-file>
+
+The trace is rendered as text by default. To build tooling on top of the trace, use 
+'--trace-format json' to render each trace event as a JSON object instead, e.g.
+
+	$ conftest test --trace --trace-format json <input-file>
 `
 
 // TestRun stores the compiler and store for a test run.
@@ -83,7 +129,7 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 		Short: "Test your configuration files using Open Policy Agent",
 		Long:  testDesc,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			flagNames := []string{"all-namespaces", "combine", "data", "fail-on-warn", "ignore", "namespace", "no-color", "no-fail", "suppress-exceptions", "output", "parser", "policy", "trace", "update"}
+			flagNames := []string{"all-namespaces", "allow-remote-includes", "blame", "bundle", "capabilities", "combine", "combine-by", "combine-keyed", "combine-size-warning", "csv-no-header", "data", "default-severity", "dhall-no-remote", "exclude-test-files", "expand-labels", "fail-on-compile-warning", "fail-on-warn", "fail-on-warn-namespace", "group-by", "group-data", "helm-namespaces", "helm-source-comments", "ignore", "jsonnet-path", "junit-suite-per-file", "lib", "list-rules", "max-failures", "min-severity", "namespace", "namespace-fallback", "nested-stacks", "no-color", "no-deprecation-warnings", "no-fail", "normalize-cidr", "suppress-exceptions", "otel-endpoint", "output", "output-dir", "overlay", "parser", "policy", "policy-stdin", "print-config", "require-tests", "resolve-includes", "rule", "run-id", "show-builtin-errors", "show-policy-root", "show-policy-source", "split-by-file", "status-file", "stream", "strict-input", "strict-yaml", "trace", "trace-format", "update", "verbose", "webhook", "webhook-content-type", "webhook-no-fail", "webhook-only", "webhook-token", "webhook-user", "what-if"}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
 					return fmt.Errorf("bind flag: %w", err)
@@ -94,9 +140,8 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 		},
 
 		RunE: func(cmd *cobra.Command, fileList []string) error {
-			if len(fileList) < 1 {
-				cmd.Usage() //nolint
-				return fmt.Errorf("missing required arguments")
+			if viper.GetBool("print-config") {
+				return printConfig(os.Stdout, viper.AllSettings(), viper.GetString("output"))
 			}
 
 			var runner runner.TestRunner
@@ -104,52 +149,513 @@ func NewTestCommand(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("unmarshal parameters: %w", err)
 			}
 
+			// Bundles are an alternative to the policy directory, so the default policy
+			// directory is only used alongside bundles when it was set explicitly.
+			if len(runner.Bundle) > 0 && !viper.IsSet("policy") {
+				runner.Policy = nil
+			}
+
+			if viper.GetBool("list-rules") {
+				rules, err := runner.Rules(ctx)
+				if err != nil {
+					return fmt.Errorf("list rules: %w", err)
+				}
+
+				return printRules(os.Stdout, rules, runner.Output)
+			}
+
+			if len(fileList) < 1 {
+				cmd.Usage() //nolint
+				return fmt.Errorf("missing required arguments")
+			}
+
+			if runner.GroupBy != output.GroupByFile && runner.GroupBy != output.GroupByRule && runner.GroupBy != output.GroupByNamespace {
+				return fmt.Errorf("unknown group by: %v", runner.GroupBy)
+			}
+
+			if runner.WhatIf != "" && runner.Output != output.OutputStandard && runner.Output != output.OutputJSON {
+				return fmt.Errorf("what-if only supports the %s and %s outputs", output.OutputStandard, output.OutputJSON)
+			}
+
+			// Every invocation has a run id, so that the results of a single run
+			// can be grouped by the systems that the results are sent to.
+			if runner.RunID == "" {
+				runID, err := newRunID()
+				if err != nil {
+					return fmt.Errorf("new run id: %w", err)
+				}
+
+				runner.RunID = runID
+			}
+
+			if runner.Stream {
+				return streamTest(ctx, runner, fileList)
+			}
+
 			results, err := runner.Run(ctx, fileList)
 			if err != nil {
+				if err := writeStatusFile(runner.StatusFile, errorStatus(err)); err != nil {
+					return fmt.Errorf("write status file: %w", err)
+				}
+
 				return fmt.Errorf("running test: %w", err)
 			}
 
-			outputter := output.Get(runner.Output, output.Options{NoColor: runner.NoColor, SuppressExceptions: runner.SuppressExceptions, Tracing: runner.Trace})
-			if err := outputter.Output(results); err != nil {
-				return fmt.Errorf("output results: %w", err)
+			// The severity only filters the results that are reported, the exit
+			// code is still determined by all of the results.
+			reported := results
+			if runner.MinSeverity != "" {
+				reported, err = output.FilterBySeverity(results, runner.MinSeverity, runner.DefaultSeverity)
+				if err != nil {
+					return fmt.Errorf("filter by severity: %w", err)
+				}
 			}
 
-			// When the no-fail parameter is set, there is no need to figure out the error code
-			// as we always want to return zero.
-			if runner.NoFail {
+			// In what-if mode, the findings that the patch would introduce or resolve
+			// are reported instead of the results, and only the introduced findings
+			// determine the exit code.
+			if runner.WhatIf != "" {
+				patched := runner.WhatIfResults
+				if runner.MinSeverity != "" {
+					patched, err = output.FilterBySeverity(patched, runner.MinSeverity, runner.DefaultSeverity)
+					if err != nil {
+						return fmt.Errorf("filter by severity: %w", err)
+					}
+				}
+
+				whatIf := output.DiffResults(reported, patched)
+				if err := output.OutputWhatIf(os.Stdout, whatIf, runner.Output, runner.NoColor); err != nil {
+					return fmt.Errorf("output what-if: %w", err)
+				}
+
+				exitCode := output.ExitCodeWithOptions(whatIf.IntroducedResults(), output.ExitCodeOptions{NoFail: runner.NoFail, FailOnWarn: runner.FailOnWarn, FailOnWarnNamespaces: runner.FailOnWarnNamespace})
+				if exitCode > 0 {
+					os.Exit(exitCode)
+				}
+
 				return nil
 			}
 
-			var exitCode int
-			if runner.FailOnWarn {
-				exitCode = output.ExitCodeFailOnWarn(results)
-			} else {
-				exitCode = output.ExitCode(results)
+			outputOptions := output.Options{NoColor: runner.NoColor, SuppressExceptions: runner.SuppressExceptions, Tracing: runner.Trace, GroupBy: runner.GroupBy, JUnitSuitePerFile: runner.JUnitSuitePerFile}
+			outputter := output.Get(runner.Output, outputOptions)
+			if runner.OutputDir != "" {
+				outputter = output.NewDirectory(runner.OutputDir, runner.Output, outputOptions)
+			}
+
+			if runner.Webhook != "" {
+				outputter, err = newWebhook(runner, outputter)
+				if err != nil {
+					return fmt.Errorf("webhook: %w", err)
+				}
+			}
+
+			if err := outputter.Output(reported); err != nil {
+				return fmt.Errorf("output results: %w", err)
+			}
+
+			// The skipped files are written to stderr, so that they do not
+			// interfere with outputs that are meant to be machine readable.
+			if runner.Verbose {
+				reportSkippedFiles(os.Stderr, runner.Skipped)
+			}
+
+			reportEmptyFiles(os.Stderr, runner.EmptyFiles)
+			reportCombinedSize(os.Stderr, runner.CombinedSize, runner.CombineSizeWarning)
+			reportNestedStackWarnings(os.Stderr, runner.NestedStackWarnings)
+			reportInvalidMessages(os.Stderr, runner.InvalidMessages)
+
+			// Deprecation warnings are informational, so they are also written to
+			// stderr and do not affect the exit code.
+			if !runner.NoDeprecationWarnings {
+				reportDeprecations(os.Stderr, runner.Deprecations)
+			}
+
+			// Exporting the spans is best effort, so that an unavailable collector
+			// does not fail the run.
+			if runner.OtelEndpoint != "" {
+				client := &http.Client{Timeout: 10 * time.Second}
+				if err := output.ExportSpans(ctx, client, runner.OtelEndpoint, results, runner.Timings); err != nil {
+					fmt.Fprintln(os.Stderr, "WARN - export spans -", err)
+				}
+			}
+
+			// When no tests ran at all, the policies did not evaluate anything, which
+			// would otherwise be reported as a successful run.
+			status := output.Status(results)
+			requireTestsFailed := runner.RequireTests && output.TestCount(results) == 0
+			if requireTestsFailed {
+				status = output.StatusError
+			}
+
+			if err := writeStatusFile(runner.StatusFile, status); err != nil {
+				return fmt.Errorf("write status file: %w", err)
+			}
+
+			if requireTestsFailed {
+				return fmt.Errorf("no tests were run: no warn or deny rules were evaluated, which usually means that the tested namespaces do not contain any rules, or that none of the input files could be tested")
+			}
+
+			// The failure count is reported against the maximum, so that the
+			// burn-down of the allowed failures can be tracked.
+			if runner.MaxFailures > 0 {
+				fmt.Fprintf(os.Stderr, "%d failures, the maximum is %d\n", output.FailureCount(results), runner.MaxFailures)
+			}
+
+			exitCode := output.ExitCodeWithOptions(results, output.ExitCodeOptions{NoFail: runner.NoFail, FailOnWarn: runner.FailOnWarn, FailOnWarnNamespaces: runner.FailOnWarnNamespace, MaxFailures: runner.MaxFailures})
+			if exitCode > 0 {
+				os.Exit(exitCode)
 			}
 
-			os.Exit(exitCode)
 			return nil
 		},
 	}
 
+	cmd.Flags().Bool("fail-on-compile-warning", false, "Return an error when the compiler reports warnings for the policies, such as unused variables")
 	cmd.Flags().Bool("fail-on-warn", false, "Return a non-zero exit code if warnings or errors are found")
+	cmd.Flags().StringSlice("fail-on-warn-namespace", []string{}, "Consider the warnings of the given namespaces, and the namespaces nested in them, as failures")
 	cmd.Flags().Bool("no-fail", false, "Return an exit code of zero even if a policy fails")
 	cmd.Flags().Bool("no-color", false, "Disable color when printing")
+	cmd.Flags().Bool("verbose", false, "Report the files that were skipped when walking directories")
+	cmd.Flags().Bool("no-deprecation-warnings", false, "Do not warn about rules that use a deprecated name, such as violation")
 	cmd.Flags().Bool("suppress-exceptions", false, "Do not include exceptions in output")
 	cmd.Flags().Bool("all-namespaces", false, "Test policies found in all namespaces")
+	cmd.Flags().Bool("namespace-fallback", false, "Treat the namespaces as a fallback chain, where a rule in a namespace overrides the rules with the same name in the namespaces after it")
+	cmd.Flags().Bool("exclude-test-files", false, "Do not evaluate the rules found in _test.rego files")
+	cmd.Flags().Bool("normalize-cidr", false, "Normalize the CIDR blocks of Kubernetes NetworkPolicy ipBlock fields before evaluation")
+	cmd.Flags().Bool("helm-source-comments", false, "Add the path in the # Source comment that precedes each YAML document, as rendered by helm template, to the metadata of its results")
+	cmd.Flags().Bool("list-rules", false, "Print the warn, deny and exception rules of the namespaces, along with the policy files that define them, without testing any files")
+	cmd.Flags().Bool("require-tests", false, "Return an error if no tests were run, for example because the namespaces do not contain any rules")
+	cmd.Flags().Bool("dhall-no-remote", false, "Do not allow Dhall files to import expressions from URLs")
+	cmd.Flags().Bool("resolve-includes", false, "Replace the includes of YAML and JSON files, such as $ref objects and !include tags, with the files that they include")
+	cmd.Flags().Bool("allow-remote-includes", false, "Allow includes to refer to files on HTTP servers when resolving includes")
+	cmd.Flags().StringSlice("jsonnet-path", []string{}, "A list of library directories in which the imports of Jsonnet files are looked up")
+	cmd.Flags().Bool("csv-no-header", false, "Parse CSV files without a header row, where every row is a list of its fields")
+	cmd.Flags().Bool("strict-input", false, "Return an error for files that are not recognized by their name or extension, rather than parsing them as YAML")
+	cmd.Flags().Bool("strict-yaml", false, "Report duplicate keys in YAML files as an error instead of keeping the value of the last key")
+	cmd.Flags().Bool("expand-labels", false, "Add the labels and annotations of Kubernetes resources as lists sorted by key")
+	cmd.Flags().Bool("blame", false, "Add the author and commit that last changed the line of each failure, from git blame, to the metadata of the failure")
 
 	cmd.Flags().BoolP("trace", "", false, "Enable more verbose trace output for Rego queries")
+	cmd.Flags().String("trace-format", policy.TraceFormatPretty, fmt.Sprintf("Format of the trace output - valid options are: %s", policy.TraceFormats()))
 	cmd.Flags().BoolP("combine", "", false, "Combine all config files to be evaluated together")
-
+	cmd.Flags().String("combine-by", "", "Combine the config files into groups, either by directory (dir) or by a JSONPath evaluated against each document, and evaluate each group together")
+	cmd.Flags().String("group-data", "", "Directory with a data directory for each group of --combine-by, named after the key of the group, that is loaded alongside --data")
+	cmd.Flags().Bool("policy-stdin", false, "Read a single Rego policy from standard input instead of the policy directory")
+	cmd.Flags().Bool("print-config", false, "Print the effective configuration after applying the flags, environment variables and configuration file, and exit")
+	cmd.Flags().Bool("show-builtin-errors", false, "Report the errors that occur when evaluating a rule as errors of the rule, and continue with the other rules")
+	cmd.Flags().Bool("show-policy-source", false, "Include the policy files that define the rule of each result in the json output")
+	cmd.Flags().Bool("show-policy-root", false, "Add the policy path or bundle that the rule of each result was loaded from to the policy_root key of its metadata")
+	cmd.Flags().Bool("combine-keyed", false, "Combine all config files into a single document keyed by the parser of each file (e.g. dockerfile or yaml)")
+	cmd.Flags().Int("combine-size-warning", 100, "Warn when the files that are combined are larger than this many megabytes in total, 0 disables the warning")
+	cmd.Flags().Bool("split-by-file", false, "Report combined results per file, using the file metadata of each result")
+
+	cmd.Flags().Int("max-failures", 0, "Only return a non-zero exit code when the number of failures is greater than the given number")
 	cmd.Flags().String("ignore", "", "A regex pattern which can be used for ignoring paths")
-	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations. Valid parsers: %s", parser.Parsers()))
+	cmd.Flags().String("rule", "", "Only evaluate the rule with the given name (e.g. deny_latest_tag)")
+	cmd.Flags().String("nested-stacks", "", "Inline the templates of nested CloudFormation stacks, which are looked up in the given directory")
+	cmd.Flags().String("capabilities", "", "Restrict the builtins available to policies, either the 'safe' profile or a path to an OPA capabilities JSON file")
+	cmd.Flags().String("parser", "", fmt.Sprintf("Parser to use to parse the configurations, a list of <extension>=<parser> overrides, or a list of the parsers that files are allowed to be detected as. Valid parsers: %s", parser.Parsers()))
 
 	cmd.Flags().StringP("output", "o", output.OutputStandard, fmt.Sprintf("Output format for conftest results - valid options are: %s", output.Outputs()))
+	cmd.Flags().String("status-file", "", fmt.Sprintf("Write the status of the run as JSON to the given file - the status is one of: %s", output.Statuses()))
+	cmd.Flags().String("webhook", "", "Send the results in JSON format to the given URL with a POST request, in addition to the output")
+	cmd.Flags().String("webhook-content-type", output.DefaultWebhookContentType, "Content type of the requests sent to the webhook")
+	cmd.Flags().String("webhook-token", "", "Token that is sent as a bearer token in the Authorization header of the requests sent to the webhook")
+	cmd.Flags().String("webhook-user", "", "Username and password, separated by a colon, that are used to authenticate to the webhook with basic authentication")
+	cmd.Flags().Bool("webhook-only", false, "Only send the results to the webhook, without writing them to stdout")
+	cmd.Flags().Bool("webhook-no-fail", false, "Report the errors of the webhook as a warning instead of failing the run")
+	cmd.Flags().String("run-id", "", "The id of the run in the JSON and SARIF outputs, to group the results of a single run. A random UUID is generated when not set")
+	cmd.Flags().Bool("stream", false, "Read the documents from standard input one at a time, and output the results of every document as soon as it has been evaluated")
+	cmd.Flags().String("what-if", "", "Apply the JSON patch in the given file to every input file, and report the failures and warnings that the patch would introduce or resolve")
+	cmd.Flags().String("otel-endpoint", "", "Export the results as OpenTelemetry spans to the given OTLP/HTTP endpoint (e.g. http://localhost:4318)")
+	cmd.Flags().String("output-dir", "", "Write a report per input file to the given directory instead of writing the results to stdout")
+	cmd.Flags().String("min-severity", "", fmt.Sprintf("Only report the warnings, failures and exceptions with at least the given severity, read from the severity field of their metadata - valid options are: %s", output.Severities()))
+	cmd.Flags().String("default-severity", output.SeverityLow, "Severity of the results that do not have a severity, when filtering with --min-severity")
+	cmd.Flags().Bool("junit-suite-per-file", false, "Write a test suite for every file in the junit output, with the warnings as skipped tests")
+	cmd.Flags().String("group-by", output.GroupByFile, fmt.Sprintf("Group the results by file, by rule or by namespace in the stdout and json outputs - valid options are: %s, %s, %s", output.GroupByFile, output.GroupByRule, output.GroupByNamespace))
 
 	cmd.Flags().StringSliceP("policy", "p", []string{"policy"}, "Path to the Rego policy files directory")
 	cmd.Flags().StringSliceP("update", "u", []string{}, "A list of URLs can be provided to the update flag, which will download before the tests run")
 	cmd.Flags().StringSliceP("namespace", "n", []string{"main"}, "Test policies in a specific namespace")
 	cmd.Flags().StringSliceP("data", "d", []string{}, "A list of paths from which data for the rego policies will be recursively loaded")
+	cmd.Flags().StringSlice("bundle", []string{}, "A list of paths to OPA bundles, either directories or tarballs built with opa build, to load policies and data from")
+	cmd.Flags().StringSlice("helm-namespaces", []string{}, "Evaluate Helm chart values files and rendered manifests against their own namespace, given as <type>=<namespace> where the type is values or manifests")
+	cmd.Flags().StringSlice("overlay", []string{}, "A list of files that are merged into every input file in order, as a JSON merge patch, before the input is evaluated")
+	cmd.Flags().StringSlice("lib", []string{}, "A list of paths to Rego libraries that can be imported by the policies, but whose rules are not evaluated")
 
 	return &cmd
 }
+
+func reportSkippedFiles(w io.Writer, skipped []runner.SkippedFile) {
+	var ignored int
+	var unsupported int
+	for _, file := range skipped {
+		switch file.Reason {
+		case runner.SkipReasonIgnored:
+			ignored++
+			fmt.Fprintln(w, "SKIP -", file.Path, "- matches the ignore pattern")
+		case runner.SkipReasonUnsupported:
+			unsupported++
+			fmt.Fprintln(w, "SKIP -", file.Path, "- no parser for the file type")
+		case runner.SkipReasonUnrecognized:
+			unsupported++
+			fmt.Fprintln(w, "SKIP -", file.Path, "- the contents are not in a format that the parser for the file type parses")
+		}
+	}
+
+	var pluralSuffix string
+	if len(skipped) != 1 {
+		pluralSuffix = "s"
+	}
+
+	fmt.Fprintf(w, "%v file%s skipped, %v ignored, %v unsupported\n", len(skipped), pluralSuffix, ignored, unsupported)
+}
+
+func reportEmptyFiles(w io.Writer, emptyFiles []string) {
+	for _, file := range emptyFiles {
+		fmt.Fprintln(w, "WARN -", file, "- the file is empty and is not combined")
+	}
+}
+
+// reportCombinedSize warns when the combined input is larger than the threshold
+// in megabytes, since combining keeps all of the files in memory at once. A
+// threshold of zero disables the warning.
+func reportCombinedSize(w io.Writer, size int64, threshold int) {
+	if threshold <= 0 || size <= int64(threshold)*1024*1024 {
+		return
+	}
+
+	fmt.Fprintf(w, "WARN - combine - the combined input is %d MB, which is more than %d MB: all of the files are held in memory at once, consider --combine-by to evaluate smaller groups of files\n", size/(1024*1024), threshold)
+}
+
+func reportNestedStackWarnings(w io.Writer, warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintln(w, "WARN -", warning)
+	}
+}
+
+func reportInvalidMessages(w io.Writer, messages []policy.InvalidMessage) {
+	for _, message := range messages {
+		fmt.Fprintf(w, "WARN - %s - returned %s, which is not a message: rules should return a string or an object with a msg field\n", message.Query, message.Value)
+	}
+}
+
+func reportDeprecations(w io.Writer, deprecations []policy.Deprecation) {
+	for _, deprecation := range deprecations {
+		fmt.Fprintf(w, "DEPRECATED - %s - rule %s in namespace %s is deprecated, rename it to %s\n", deprecation.Location, deprecation.Rule, deprecation.Namespace, deprecation.Replacement)
+	}
+}
+
+// errorStatus returns the status of a run that returned the given error.
+func errorStatus(err error) string {
+	var parseErr *runner.ParseError
+	switch {
+	case errors.Is(err, runner.ErrNoInput):
+		return output.StatusNoInput
+	case errors.As(err, &parseErr):
+		return output.StatusParseError
+	default:
+		return output.StatusError
+	}
+}
+
+// writeStatusFile writes the given status as a JSON object to the file at
+// the given path. Nothing is written when the path is empty.
+func writeStatusFile(path string, status string) error {
+	if path == "" {
+		return nil
+	}
+
+	out, err := json.MarshalIndent(map[string]string{"status": status}, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal status: %w", err)
+	}
+
+	if err := ioutil.WriteFile(path, append(out, '\n'), 0644); err != nil {
+		return fmt.Errorf("write file: %w", err)
+	}
+
+	return nil
+}
+
+// streamTest evaluates the documents that are read from standard input as they
+// arrive, and outputs the results of every document before the next document is
+// read. The exit code is determined by the results of all of the documents, once
+// the end of the input has been reached.
+func streamTest(ctx context.Context, runner runner.TestRunner, fileList []string) error {
+	if len(fileList) != 1 || fileList[0] != "-" {
+		return fmt.Errorf("stream only supports reading from standard input, given as -")
+	}
+
+	if runner.Output != output.OutputStandard && runner.Output != output.OutputJSONLines {
+		return fmt.Errorf("stream only supports the %s and %s outputs", output.OutputStandard, output.OutputJSONLines)
+	}
+
+	if runner.OutputDir != "" || runner.Webhook != "" {
+		return fmt.Errorf("stream cannot be used with an output directory or a webhook")
+	}
+
+	outputOptions := output.Options{NoColor: runner.NoColor, SuppressExceptions: runner.SuppressExceptions, Tracing: runner.Trace, GroupBy: runner.GroupBy, JUnitSuitePerFile: runner.JUnitSuitePerFile}
+	outputter := output.Get(runner.Output, outputOptions)
+
+	// Only the failures, warnings and errors of every document are kept for the
+	// exit code, so that the memory usage does not grow with the successes.
+	var results []output.CheckResult
+	emit := func(documentResults []output.CheckResult) error {
+		reported := documentResults
+		if runner.MinSeverity != "" {
+			var err error
+			reported, err = output.FilterBySeverity(documentResults, runner.MinSeverity, runner.DefaultSeverity)
+			if err != nil {
+				return fmt.Errorf("filter by severity: %w", err)
+			}
+		}
+
+		if err := outputter.Output(reported); err != nil {
+			return fmt.Errorf("output results: %w", err)
+		}
+
+		for _, result := range documentResults {
+			results = append(results, output.CheckResult{
+				FileName:  result.FileName,
+				Namespace: result.Namespace,
+				Successes: result.Successes,
+				Failures:  result.Failures,
+				Warnings:  result.Warnings,
+				Errors:    result.Errors,
+			})
+		}
+
+		return nil
+	}
+
+	if err := runner.RunStream(ctx, os.Stdin, emit); err != nil {
+		if err := writeStatusFile(runner.StatusFile, errorStatus(err)); err != nil {
+			return fmt.Errorf("write status file: %w", err)
+		}
+
+		return fmt.Errorf("running test: %w", err)
+	}
+
+	reportInvalidMessages(os.Stderr, runner.InvalidMessages)
+
+	if err := writeStatusFile(runner.StatusFile, output.Status(results)); err != nil {
+		return fmt.Errorf("write status file: %w", err)
+	}
+
+	exitCode := output.ExitCodeWithOptions(results, output.ExitCodeOptions{NoFail: runner.NoFail, FailOnWarn: runner.FailOnWarn, FailOnWarnNamespaces: runner.FailOnWarnNamespace, MaxFailures: runner.MaxFailures})
+	if exitCode > 0 {
+		os.Exit(exitCode)
+	}
+
+	return nil
+}
+
+// newWebhook wraps the given outputter with a webhook that is configured
+// from the webhook options of the runner.
+func newWebhook(runner runner.TestRunner, outputter output.Outputter) (*output.Webhook, error) {
+	if runner.WebhookOnly {
+		outputter = nil
+	}
+
+	webhook := output.NewWebhook(outputter, runner.Webhook)
+	webhook.Client = &http.Client{Timeout: 10 * time.Second}
+	webhook.ContentType = runner.WebhookContentType
+	webhook.Token = runner.WebhookToken
+	webhook.IgnoreErrors = runner.WebhookNoFail
+	webhook.ErrorWriter = os.Stderr
+
+	if runner.WebhookUser != "" {
+		credentials := strings.SplitN(runner.WebhookUser, ":", 2)
+		if len(credentials) != 2 {
+			return nil, fmt.Errorf("webhook user must be given as <username>:<password>")
+		}
+
+		webhook.Username = credentials[0]
+		webhook.Password = credentials[1]
+	}
+
+	return webhook, nil
+}
+
+// printRules prints the given rules with the policy files that define them,
+// or as a JSON array when the output is json.
+func printRules(w io.Writer, rules []policy.RuleDefinition, format string) error {
+	if format == output.OutputJSON {
+		if rules == nil {
+			rules = []policy.RuleDefinition{}
+		}
+
+		out, err := json.MarshalIndent(rules, "", "\t")
+		if err != nil {
+			return fmt.Errorf("marshal rules: %w", err)
+		}
+
+		fmt.Fprintln(w, string(out))
+		return nil
+	}
+
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%s.%s (%s) - %s\n", rule.Namespace, rule.Rule, rule.Kind, strings.Join(rule.Sources, ", "))
+	}
+
+	return nil
+}
+
+// credentialSettings are the settings that contain credentials, whose values
+// are redacted when the configuration is printed.
+var credentialSettings = []string{"webhook-token", "webhook-user"}
+
+// redactedValue replaces the value of a credential setting that is set.
+const redactedValue = "<redacted>"
+
+// printConfig prints the given settings as sorted key/value pairs,
+// or as a JSON object when the output is json. Credentials are redacted.
+func printConfig(w io.Writer, settings map[string]interface{}, format string) error {
+	redacted := make(map[string]interface{}, len(settings))
+	for key, value := range settings {
+		redacted[key] = value
+	}
+
+	for _, key := range credentialSettings {
+		if value, ok := redacted[key]; ok && value != "" {
+			redacted[key] = redactedValue
+		}
+	}
+	settings = redacted
+
+	if format == output.OutputJSON {
+		out, err := json.MarshalIndent(settings, "", "\t")
+		if err != nil {
+			return fmt.Errorf("marshal config: %w", err)
+		}
+
+		fmt.Fprintln(w, string(out))
+		return nil
+	}
+
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s = %v\n", key, settings[key])
+	}
+
+	return nil
+}
+
+// newRunID returns a random (version 4) UUID.
+func newRunID() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", fmt.Errorf("read random: %w", err)
+	}
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestPrintConfigRedactsCredentials(t *testing.T) {
+	for _, format := range []string{output.OutputStandard, output.OutputJSON} {
+		t.Run(format, func(t *testing.T) {
+			settings := map[string]interface{}{
+				"webhook":       "https://example.com/results",
+				"webhook-token": "s3cr3t-token",
+				"webhook-user":  "ci:hunter2",
+			}
+
+			var buf bytes.Buffer
+			if err := printConfig(&buf, settings, format); err != nil {
+				t.Fatalf("print config: %v", err)
+			}
+
+			for _, secret := range []string{"s3cr3t-token", "hunter2"} {
+				if strings.Contains(buf.String(), secret) {
+					t.Errorf("expected %q to be redacted, got: %s", secret, buf.String())
+				}
+			}
+
+			if !strings.Contains(buf.String(), "https://example.com/results") {
+				t.Errorf("expected the other settings to be printed, got: %s", buf.String())
+			}
+
+			if settings["webhook-token"] != "s3cr3t-token" {
+				t.Errorf("the settings should not be modified: %v", settings)
+			}
+		})
+	}
+}
+
+func TestNewRunID(t *testing.T) {
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+
+	first, err := newRunID()
+	if err != nil {
+		t.Fatalf("new run id: %v", err)
+	}
+
+	second, err := newRunID()
+	if err != nil {
+		t.Fatalf("new run id: %v", err)
+	}
+
+	for _, runID := range []string{first, second} {
+		if !uuidV4.MatchString(runID) {
+			t.Errorf("expected a version 4 UUID, got %q", runID)
+		}
+	}
+
+	if first == second {
+		t.Errorf("expected different run ids, got %q twice", first)
+	}
+}
+
+func TestRunIDFromEnvironment(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      string
+		flag     string
+		expected string
+	}{
+		{name: "not set", expected: ""},
+		{name: "environment variable", env: "from-env", expected: "from-env"},
+		{name: "flag", flag: "from-flag", expected: "from-flag"},
+		{name: "flag takes precedence", env: "from-env", flag: "from-flag", expected: "from-flag"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if testCase.env != "" {
+				os.Setenv("CONFTEST_RUN_ID", testCase.env)
+				defer os.Unsetenv("CONFTEST_RUN_ID")
+			}
+
+			v := viper.New()
+			configureEnv(v)
+
+			cmd := cobra.Command{}
+			cmd.Flags().String("run-id", "", "")
+			if err := v.BindPFlag("run-id", cmd.Flags().Lookup("run-id")); err != nil {
+				t.Fatalf("bind flag: %v", err)
+			}
+
+			if testCase.flag != "" {
+				if err := cmd.Flags().Set("run-id", testCase.flag); err != nil {
+					t.Fatalf("set flag: %v", err)
+				}
+			}
+
+			if actual := v.GetString("run-id"); actual != testCase.expected {
+				t.Errorf("unexpected run id. expected %q, actual %q", testCase.expected, actual)
+			}
+		})
+	}
+}
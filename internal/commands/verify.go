@@ -3,10 +3,12 @@ package commands
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
 	"github.com/open-policy-agent/conftest/internal/runner"
 	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/policy"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -53,6 +55,22 @@ When debugging policies it can be useful to use a more verbose policy evaluation
 the output will include a detailed trace of how the policy was evaluated, e.g.
 
 	$ conftest verify --trace
+
+The trace is rendered as text by default. To build tooling on top of the trace, use 
+'--trace-format json' to render each trace event as a JSON object instead, e.g.
+
+	$ conftest verify --trace --trace-format json
+
+To make sure that every rule is tested, the '--require-tests' flag fails the command 
+when a warn or deny rule is not referenced by any test, and lists the untested rules, e.g.
+
+	$ conftest verify --require-tests
+
+To find slow tests, the '--bench' flag benchmarks each test instead of reporting 
+pass or fail results. The tests are listed from slowest to fastest, as a table or 
+as JSON when '--output json' is given, e.g.
+
+	$ conftest verify --bench --output json
 `
 
 // NewVerifyCommand creates a new verify command which allows users
@@ -63,7 +81,7 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 		Short: "Verify Rego unit tests",
 		Long:  verifyDesc,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			flagNames := []string{"data", "no-color", "output", "policy", "trace"}
+			flagNames := []string{"bench", "data", "no-color", "output", "policy", "require-tests", "trace", "trace-format"}
 			for _, name := range flagNames {
 				if err := viper.BindPFlag(name, cmd.Flags().Lookup(name)); err != nil {
 					return fmt.Errorf("bind flag: %w", err)
@@ -78,6 +96,19 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("unmarshal parameters: %w", err)
 			}
 
+			if runner.Bench {
+				results, err := runner.RunBenchmarks(ctx)
+				if err != nil {
+					return fmt.Errorf("running benchmarks: %w", err)
+				}
+
+				if err := output.OutputBenchmarks(os.Stdout, runner.Output, results); err != nil {
+					return fmt.Errorf("output benchmarks: %w", err)
+				}
+
+				return nil
+			}
+
 			results, err := runner.Run(ctx)
 			if err != nil {
 				return fmt.Errorf("running verification: %w", err)
@@ -88,6 +119,13 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 				return fmt.Errorf("output results: %w", err)
 			}
 
+			// The untested rules are written to stderr, so that they do not
+			// interfere with outputs that are meant to be machine readable.
+			if len(runner.UntestedRules) > 0 {
+				reportUntestedRules(os.Stderr, runner.UntestedRules)
+				return fmt.Errorf("%d rules do not have a test", len(runner.UntestedRules))
+			}
+
 			exitCode := output.ExitCode(results)
 			if exitCode > 0 {
 				os.Exit(exitCode)
@@ -99,6 +137,9 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 
 	cmd.Flags().Bool("no-color", false, "Disable color when printing")
 	cmd.Flags().Bool("trace", false, "Enable more verbose trace output for Rego queries")
+	cmd.Flags().String("trace-format", policy.TraceFormatPretty, fmt.Sprintf("Format of the trace output - valid options are: %s", policy.TraceFormats()))
+	cmd.Flags().Bool("bench", false, "Benchmark the Rego unit tests and report the slowest tests first")
+	cmd.Flags().Bool("require-tests", false, "Return an error if a warn or deny rule is not referenced by any test")
 
 	cmd.Flags().StringP("output", "o", output.OutputStandard, fmt.Sprintf("Output format for conftest results - valid options are: %s", output.Outputs()))
 
@@ -107,3 +148,9 @@ func NewVerifyCommand(ctx context.Context) *cobra.Command {
 
 	return &cmd
 }
+
+func reportUntestedRules(w io.Writer, untested []policy.UntestedRule) {
+	for _, rule := range untested {
+		fmt.Fprintf(w, "UNTESTED - %s - rule %s in namespace %s is not referenced by any test\n", rule.Location, rule.Rule, rule.Namespace)
+	}
+}
@@ -0,0 +1,161 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// blame is the author and commit that last changed a line or a file.
+type blame struct {
+	Author string
+	Email  string
+	Commit string
+}
+
+// addBlame adds the author and commit that last changed the line of every failure
+// to the blame field of the metadata of the failure, using git blame. The line
+// is read from the line field of the metadata. When a failure does not have a
+// line, the last commit that changed the file is used instead. Failures are left
+// as they are when git is not available, the file is not part of a git repository,
+// or the line cannot be blamed.
+func addBlame(ctx context.Context, results []output.CheckResult) {
+	cache := make(map[string]*blame)
+	lookup := func(path string, line int) *blame {
+		key := fmt.Sprintf("%s:%d", path, line)
+		if b, ok := cache[key]; ok {
+			return b
+		}
+
+		var b *blame
+		if line > 0 {
+			b = blameLine(ctx, path, line)
+		} else {
+			b = blameFile(ctx, path)
+		}
+
+		cache[key] = b
+		return b
+	}
+
+	for _, result := range results {
+		if info, err := os.Stat(result.FileName); err != nil || info.IsDir() {
+			continue
+		}
+
+		for i, failure := range result.Failures {
+			line := metadataLine(failure.Metadata)
+
+			b := lookup(result.FileName, line)
+			if b == nil {
+				continue
+			}
+
+			if result.Failures[i].Metadata == nil {
+				result.Failures[i].Metadata = make(map[string]interface{})
+			}
+
+			if _, ok := result.Failures[i].Metadata["blame"]; !ok {
+				result.Failures[i].Metadata["blame"] = map[string]interface{}{
+					"author": b.Author,
+					"email":  b.Email,
+					"commit": b.Commit,
+				}
+			}
+		}
+	}
+}
+
+// metadataLine returns the line in the line field of the metadata,
+// or zero when the metadata does not have a valid line.
+func metadataLine(metadata map[string]interface{}) int {
+	var line int
+	switch value := metadata["line"].(type) {
+	case json.Number:
+		n, _ := value.Int64()
+		line = int(n)
+	case float64:
+		line = int(value)
+	case int:
+		line = value
+	}
+
+	if line < 0 {
+		return 0
+	}
+
+	return line
+}
+
+// blameLine returns who last changed the given line of the file, or nil when
+// the line cannot be blamed.
+func blameLine(ctx context.Context, path string, line int) *blame {
+	out, err := git(ctx, path, "blame", "--porcelain", "-L", fmt.Sprintf("%d,%d", line, line), "--", filepath.Base(path))
+	if err != nil {
+		return nil
+	}
+
+	var b blame
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case b.Commit == "":
+			// The first line of the porcelain format is the commit, followed by
+			// the line numbers and the number of lines in the group.
+			fields := strings.Fields(text)
+			if len(fields) == 0 {
+				return nil
+			}
+			b.Commit = fields[0]
+		case strings.HasPrefix(text, "author "):
+			b.Author = strings.TrimPrefix(text, "author ")
+		case strings.HasPrefix(text, "author-mail "):
+			b.Email = strings.Trim(strings.TrimPrefix(text, "author-mail "), "<>")
+		}
+	}
+
+	// Lines that have not been committed yet are blamed on a commit of zeros.
+	if b.Commit == "" || strings.Trim(b.Commit, "0") == "" {
+		return nil
+	}
+
+	return &b
+}
+
+// blameFile returns who last committed a change to the file, or nil when the
+// file has not been committed.
+func blameFile(ctx context.Context, path string) *blame {
+	out, err := git(ctx, path, "log", "-1", "--format=%an%x00%ae%x00%H", "--", filepath.Base(path))
+	if err != nil {
+		return nil
+	}
+
+	fields := strings.Split(strings.TrimSpace(string(out)), "\x00")
+	if len(fields) != 3 {
+		return nil
+	}
+
+	return &blame{Author: fields[0], Email: fields[1], Commit: fields[2]}
+}
+
+// git runs git with the given arguments in the directory of the given path.
+func git(ctx context.Context, path string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = filepath.Dir(path)
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("run git %s: %w", args[0], err)
+	}
+
+	return out, nil
+}
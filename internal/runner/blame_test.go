@@ -0,0 +1,143 @@
+package runner
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+)
+
+// initRepository creates a git repository with a committed deployment.yaml,
+// and returns the path of the file along with the hash of the commit.
+func initRepository(t *testing.T) (string, string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	run := func(args ...string) string {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_CONFIG_NOSYSTEM=1", "HOME="+dir)
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %s: %v: %s", args[0], err, out)
+		}
+
+		return strings.TrimSpace(string(out))
+	}
+
+	path := filepath.Join(dir, "deployment.yaml")
+	if err := ioutil.WriteFile(path, []byte("kind: Deployment\nmetadata:\n  name: web\n"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	run("init", "--quiet")
+	run("add", "deployment.yaml")
+	run("-c", "user.name=Jane Doe", "-c", "user.email=jane@example.com", "commit", "--quiet", "--no-gpg-sign", "-m", "Add deployment")
+
+	return path, run("rev-parse", "HEAD")
+}
+
+func TestBlameLine(t *testing.T) {
+	path, commit := initRepository(t)
+
+	expected := &blame{Author: "Jane Doe", Email: "jane@example.com", Commit: commit}
+	if actual := blameLine(context.Background(), path, 2); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected blame. expected %v actual %v", expected, actual)
+	}
+
+	if actual := blameLine(context.Background(), path, 10); actual != nil {
+		t.Errorf("expected no blame for a line past the end of the file, got %v", actual)
+	}
+}
+
+func TestBlameLineUncommitted(t *testing.T) {
+	path, _ := initRepository(t)
+
+	if err := ioutil.WriteFile(path, []byte("kind: Deployment\nmetadata:\n  name: api\n"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	if actual := blameLine(context.Background(), path, 3); actual != nil {
+		t.Errorf("expected no blame for an uncommitted line, got %v", actual)
+	}
+}
+
+func TestBlameFile(t *testing.T) {
+	path, commit := initRepository(t)
+
+	expected := &blame{Author: "Jane Doe", Email: "jane@example.com", Commit: commit}
+	if actual := blameFile(context.Background(), path); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected blame. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestAddBlame(t *testing.T) {
+	path, commit := initRepository(t)
+
+	results := []output.CheckResult{
+		{
+			FileName: path,
+			Failures: []output.Result{
+				{Message: "line", Metadata: map[string]interface{}{"line": float64(3)}},
+				{Message: "file"},
+			},
+		},
+	}
+
+	addBlame(context.Background(), results)
+
+	expected := map[string]interface{}{
+		"author": "Jane Doe",
+		"email":  "jane@example.com",
+		"commit": commit,
+	}
+	for _, failure := range results[0].Failures {
+		if !reflect.DeepEqual(failure.Metadata["blame"], expected) {
+			t.Errorf("unexpected blame for %v. expected %v actual %v", failure.Message, expected, failure.Metadata["blame"])
+		}
+	}
+}
+
+func TestAddBlameNotRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "deployment.yaml")
+	if err := ioutil.WriteFile(path, []byte("kind: Deployment\n"), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	// Keep git from finding a repository in a parent directory of the temporary directory.
+	ceiling := os.Getenv("GIT_CEILING_DIRECTORIES")
+	os.Setenv("GIT_CEILING_DIRECTORIES", filepath.Dir(dir))
+	t.Cleanup(func() { os.Setenv("GIT_CEILING_DIRECTORIES", ceiling) })
+
+	results := []output.CheckResult{
+		{
+			FileName: path,
+			Failures: []output.Result{
+				{Message: "line", Metadata: map[string]interface{}{"line": float64(1)}},
+				{Message: "file"},
+			},
+		},
+	}
+
+	addBlame(context.Background(), results)
+
+	for _, failure := range results[0].Failures {
+		if _, ok := failure.Metadata["blame"]; ok {
+			t.Errorf("expected no blame for %v outside of a repository, got %v", failure.Message, failure.Metadata["blame"])
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// helmRoute routes Helm values files and rendered manifests to the namespaces that
+// they are evaluated against. Files that are neither are evaluated against the
+// namespaces that are being tested.
+type helmRoute struct {
+	namespaces map[string]string
+	fileTypes  map[string]string
+}
+
+// newHelmRoute creates a route from a list of <type>=<namespace> pairs
+// (e.g. values=values,manifests=kubernetes) for the given configurations.
+func newHelmRoute(mappings []string, configurations map[string]interface{}) (*helmRoute, error) {
+	namespaces := make(map[string]string)
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid mapping %v: expected <type>=<namespace>", mapping)
+		}
+
+		if parts[0] != parser.HelmValues && parts[0] != parser.HelmManifests {
+			return nil, fmt.Errorf("invalid mapping %v: type must be %v or %v", mapping, parser.HelmValues, parser.HelmManifests)
+		}
+
+		namespaces[parts[0]] = parts[1]
+	}
+
+	fileTypes := make(map[string]string)
+	for path, config := range configurations {
+		fileTypes[path] = parser.HelmFileType(path, config)
+	}
+
+	route := helmRoute{
+		namespaces: namespaces,
+		fileTypes:  fileTypes,
+	}
+
+	return &route, nil
+}
+
+// allNamespaces returns the given namespaces together with
+// the namespaces that files are routed to.
+func (h *helmRoute) allNamespaces(namespaces []string) []string {
+	all := append([]string{}, namespaces...)
+	for _, fileType := range []string{parser.HelmValues, parser.HelmManifests} {
+		namespace, ok := h.namespaces[fileType]
+		if !ok || contains(all, namespace) {
+			continue
+		}
+
+		all = append(all, namespace)
+	}
+
+	return all
+}
+
+// configurations returns the configurations that are evaluated against the given
+// namespace. The tested namespaces are the namespaces that are being tested, which
+// evaluate all of the files that are not routed to another namespace.
+func (h *helmRoute) configurations(configurations map[string]interface{}, namespace string, testedNamespaces []string) map[string]interface{} {
+	routed := make(map[string]interface{})
+	for path, config := range configurations {
+		target, ok := h.namespaces[h.fileTypes[path]]
+		if ok && target == namespace {
+			routed[path] = config
+		}
+
+		if !ok && contains(testedNamespaces, namespace) {
+			routed[path] = config
+		}
+	}
+
+	return routed
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,167 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
+	"github.com/open-policy-agent/conftest/policy"
+)
+
+// RunStream reads documents from the reader one at a time, and evaluates every
+// document as soon as it has been read. The results of a document are passed to
+// emit before the next document is read, so the results are emitted in the order
+// of the documents, and a slow evaluation slows down the reading of the stream.
+//
+// YAML documents are separated by --- lines, while JSON documents are read one
+// per line (NDJSON), depending on the parser of the runner. Every result has the
+// index of its document in the stream. RunStream returns when the reader is at its
+// end, emit returns an error, or the context is canceled.
+func (t *TestRunner) RunStream(ctx context.Context, r io.Reader, emit func([]output.CheckResult) error) error {
+	if t.Combine || t.CombineBy != "" || t.CombineKeyed || t.WhatIf != "" || len(t.HelmNamespaces) > 0 || len(t.Overlay) > 0 {
+		return fmt.Errorf("streaming cannot be used with options that evaluate multiple files together")
+	}
+
+	parserName := t.Parser
+	if parserName == "" {
+		parserName = parser.YAML
+	}
+
+	if parserName != parser.YAML && parserName != parser.JSON {
+		return fmt.Errorf("streaming only supports the %s and %s parsers", parser.YAML, parser.JSON)
+	}
+
+	documentParser, err := parser.New(parserName)
+	if err != nil {
+		return fmt.Errorf("new parser: %w", err)
+	}
+
+	engine, err := t.newEngine(ctx)
+	if err != nil {
+		return err
+	}
+
+	namespaces, err := t.namespaces(engine)
+	if err != nil {
+		return err
+	}
+
+	if t.Rule != "" {
+		engine.SetRule(t.Rule)
+	}
+
+	split := splitYAMLDocument
+	if parserName == parser.JSON {
+		split = splitJSONLine
+	}
+
+	reader := bufio.NewReader(r)
+	var index int
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		document, readErr := split(reader)
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("read document %d: %w", index, readErr)
+		}
+
+		// Separators without any content in between, such as a leading ---,
+		// do not count as documents of the stream.
+		if strings.TrimSpace(document) != "" {
+			var config interface{}
+			if err := documentParser.Unmarshal([]byte(document), &config); err != nil {
+				return fmt.Errorf("parse document %d: %w", index, err)
+			}
+
+			results, err := t.checkDocument(ctx, engine, namespaces, config, index)
+			if err != nil {
+				return fmt.Errorf("check document %d: %w", index, err)
+			}
+
+			if err := emit(results); err != nil {
+				return err
+			}
+
+			index++
+		}
+
+		if readErr == io.EOF {
+			t.InvalidMessages = engine.InvalidMessages()
+			return nil
+		}
+	}
+}
+
+// checkDocument evaluates a single document of a stream against the namespaces.
+func (t *TestRunner) checkDocument(ctx context.Context, engine *policy.Engine, namespaces []string, config interface{}, index int) ([]output.CheckResult, error) {
+	configurations := map[string]interface{}{"-": config}
+	if t.NormalizeCIDR {
+		parser.NormalizeCIDRs(configurations)
+	}
+
+	if t.ExpandLabels {
+		parser.ExpandLabels(configurations)
+	}
+
+	var results []output.CheckResult
+	for _, namespace := range namespaces {
+		result, err := engine.Check(ctx, configurations, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("query rule: %w", err)
+		}
+
+		results = append(results, result...)
+	}
+
+	for _, result := range results {
+		for _, r := range [][]output.Result{result.Warnings, result.Failures, result.Exceptions, result.Errors} {
+			for i := range r {
+				document := index
+				r[i].Document = &document
+			}
+		}
+	}
+
+	if len(namespaces) > 1 {
+		output.AddNamespaceMetadata(results)
+	}
+
+	if t.RunID != "" {
+		output.SetRunID(results, t.RunID)
+	}
+
+	return results, nil
+}
+
+// splitYAMLDocument reads the next YAML document, which ends at a --- or ...
+// line or at the end of the reader. The separator is not part of the document.
+func splitYAMLDocument(reader *bufio.Reader) (string, error) {
+	var document strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		trimmed := strings.TrimRight(line, " \t\r\n")
+		if trimmed == "---" || trimmed == "..." {
+			if err == io.EOF {
+				return document.String(), io.EOF
+			}
+
+			return document.String(), nil
+		}
+
+		document.WriteString(line)
+		if err != nil {
+			return document.String(), err
+		}
+	}
+}
+
+// splitJSONLine reads the next line, which contains a single JSON document.
+func splitJSONLine(reader *bufio.Reader) (string, error) {
+	return reader.ReadString('\n')
+}
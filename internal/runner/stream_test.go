@@ -0,0 +1,203 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/conftest/output"
+	"github.com/open-policy-agent/conftest/parser"
+)
+
+// readAll splits every document from the input with the given split function.
+func readAll(t *testing.T, input string, split func(*bufio.Reader) (string, error)) []string {
+	reader := bufio.NewReader(strings.NewReader(input))
+
+	var documents []string
+	for {
+		document, err := split(reader)
+		if err != nil && err != io.EOF {
+			t.Fatalf("split: %v", err)
+		}
+
+		documents = append(documents, document)
+		if err == io.EOF {
+			return documents
+		}
+	}
+}
+
+func TestSplitYAMLDocument(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "separators",
+			input:    "a: 1\n---\nb: 2\n...\nc: 3\n",
+			expected: []string{"a: 1\n", "b: 2\n", "c: 3\n"},
+		},
+		{
+			name:     "leading separator",
+			input:    "---\na: 1\n",
+			expected: []string{"", "a: 1\n"},
+		},
+		{
+			name:     "trailing document without a newline",
+			input:    "a: 1\n---\nb: 2",
+			expected: []string{"a: 1\n", "b: 2"},
+		},
+		{
+			name:     "trailing separator without a newline",
+			input:    "a: 1\n---",
+			expected: []string{"a: 1\n"},
+		},
+		{
+			name:     "separator with trailing whitespace",
+			input:    "a: 1\n--- \r\nb: 2\n",
+			expected: []string{"a: 1\n", "b: 2\n"},
+		},
+		{
+			name:     "separator inside a block scalar",
+			input:    "a: |\n  ---x\n",
+			expected: []string{"a: |\n  ---x\n"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := readAll(t, testCase.input, splitYAMLDocument); !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("unexpected documents. expected %q actual %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSplitJSONLine(t *testing.T) {
+	input := "{\"a\": 1}\n\n{\"b\": 2}"
+
+	expected := []string{"{\"a\": 1}\n", "\n", "{\"b\": 2}"}
+	if actual := readAll(t, input, splitJSONLine); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("unexpected documents. expected %q actual %q", expected, actual)
+	}
+}
+
+// writeStreamPolicy writes a policy that denies deployments, and returns its directory.
+func writeStreamPolicy(t *testing.T) string {
+	policyDir := t.TempDir()
+	policy := `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := sprintf("%s is a deployment", [input.name])
+}`
+	if err := ioutil.WriteFile(filepath.Join(policyDir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	return policyDir
+}
+
+func TestRunStream(t *testing.T) {
+	policyDir := writeStreamPolicy(t)
+
+	testCases := []struct {
+		name     string
+		parser   string
+		input    string
+		expected [][]string
+	}{
+		{
+			name:     "yaml",
+			parser:   parser.YAML,
+			input:    "---\nkind: Deployment\nname: web\n---\nkind: Service\nname: web\n...\nkind: Deployment\nname: api",
+			expected: [][]string{{"web is a deployment"}, nil, {"api is a deployment"}},
+		},
+		{
+			name:     "ndjson",
+			parser:   parser.JSON,
+			input:    "{\"kind\": \"Deployment\", \"name\": \"web\"}\n\n{\"kind\": \"Service\", \"name\": \"web\"}\n",
+			expected: [][]string{{"web is a deployment"}, nil},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			runner := TestRunner{
+				Policy:    []string{policyDir},
+				Namespace: []string{"main"},
+				Parser:    testCase.parser,
+			}
+
+			// Every call of emit holds the results of a single document, and the
+			// failures must have the index of that document.
+			var actual [][]string
+			emit := func(results []output.CheckResult) error {
+				var messages []string
+				for _, result := range results {
+					for _, failure := range result.Failures {
+						if failure.Document == nil || *failure.Document != len(actual) {
+							t.Errorf("unexpected document index for %q in document %d: %v", failure.Message, len(actual), failure.Document)
+						}
+
+						messages = append(messages, failure.Message)
+					}
+				}
+
+				actual = append(actual, messages)
+				return nil
+			}
+
+			if err := runner.RunStream(context.Background(), strings.NewReader(testCase.input), emit); err != nil {
+				t.Fatalf("run stream: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("unexpected results. expected %q actual %q", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestRunStreamErrors(t *testing.T) {
+	policyDir := writeStreamPolicy(t)
+
+	runner := TestRunner{
+		Policy:    []string{policyDir},
+		Namespace: []string{"main"},
+	}
+
+	var emitted int
+	emitErr := errors.New("emit failed")
+	emit := func(results []output.CheckResult) error {
+		emitted++
+		return emitErr
+	}
+
+	input := "kind: Deployment\nname: web\n---\nkind: Deployment\nname: api\n"
+	if err := runner.RunStream(context.Background(), strings.NewReader(input), emit); !errors.Is(err, emitErr) {
+		t.Errorf("expected the error of emit, got: %v", err)
+	}
+
+	if emitted != 1 {
+		t.Errorf("expected the stream to stop after the first document, emitted %d", emitted)
+	}
+
+	invalid := "kind: Deployment\n---\nkind: [\n"
+	noop := func(results []output.CheckResult) error { return nil }
+	if err := runner.RunStream(context.Background(), strings.NewReader(invalid), noop); err == nil || !strings.Contains(err.Error(), "parse document 1") {
+		t.Errorf("expected an error for the invalid second document, got: %v", err)
+	}
+
+	runner.Combine = true
+	if err := runner.RunStream(context.Background(), strings.NewReader(input), noop); err == nil {
+		t.Error("expected an error when streaming is combined with --combine")
+	}
+}
@@ -2,10 +2,14 @@ package runner
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"time"
 
 	"github.com/open-policy-agent/conftest/downloader"
 	"github.com/open-policy-agent/conftest/output"
@@ -13,89 +17,632 @@ import (
 	"github.com/open-policy-agent/conftest/policy"
 )
 
+// ErrNoInput is returned by Run when the given paths do not contain any
+// files to test.
+var ErrNoInput = errors.New("no files found")
+
+// ParseError is returned by Run when the configurations could not be parsed.
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("parse configurations: %v", e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
 // TestRunner is the runner for the Test command, executing
 // Rego policy checks against configuration files.
 type TestRunner struct {
 	Trace              bool
+	TraceFormat        string `mapstructure:"trace-format"`
 	Policy             []string
+	PolicyStdin        bool `mapstructure:"policy-stdin"`
+	Bundle             []string
+	Libraries          []string `mapstructure:"lib"`
+	JsonnetPath        []string `mapstructure:"jsonnet-path"`
+	HelmNamespaces     []string `mapstructure:"helm-namespaces"`
+	Overlay            []string
+	Capabilities       string
+	ExcludeTestFiles   bool `mapstructure:"exclude-test-files"`
 	Data               []string
 	Update             []string
 	Ignore             string
 	Parser             string
+	StrictYAML         bool `mapstructure:"strict-yaml"`
+	StrictInput        bool `mapstructure:"strict-input"`
+	HelmSourceComments bool `mapstructure:"helm-source-comments"`
+	DhallNoRemote      bool `mapstructure:"dhall-no-remote"`
+	CSVNoHeader        bool `mapstructure:"csv-no-header"`
+	ResolveIncludes    bool `mapstructure:"resolve-includes"`
+	RemoteIncludes     bool `mapstructure:"allow-remote-includes"`
 	Namespace          []string
+	Rule               string
 	AllNamespaces      bool `mapstructure:"all-namespaces"`
+	NamespaceFallback  bool `mapstructure:"namespace-fallback"`
 	FailOnWarn         bool `mapstructure:"fail-on-warn"`
 	NoColor            bool `mapstructure:"no-color"`
+	JUnitSuitePerFile  bool `mapstructure:"junit-suite-per-file"`
 	NoFail             bool `mapstructure:"no-fail"`
 	SuppressExceptions bool `mapstructure:"suppress-exceptions"`
+	NormalizeCIDR      bool `mapstructure:"normalize-cidr"`
+	ExpandLabels       bool `mapstructure:"expand-labels"`
+	Blame              bool
 	Combine            bool
+	CombineBy          string `mapstructure:"combine-by"`
+	GroupData          string `mapstructure:"group-data"`
+	CombineKeyed       bool   `mapstructure:"combine-keyed"`
+	CombineSizeWarning int    `mapstructure:"combine-size-warning"`
+	SplitByFile        bool   `mapstructure:"split-by-file"`
 	Output             string
+	OutputDir          string `mapstructure:"output-dir"`
+	GroupBy            string `mapstructure:"group-by"`
+	MinSeverity        string `mapstructure:"min-severity"`
+	DefaultSeverity    string `mapstructure:"default-severity"`
+	NestedStacks       string `mapstructure:"nested-stacks"`
+	StatusFile         string `mapstructure:"status-file"`
+	OtelEndpoint       string `mapstructure:"otel-endpoint"`
+	WhatIf             string `mapstructure:"what-if"`
+	RunID              string `mapstructure:"run-id"`
+	Stream             bool
+	Webhook            string
+	WebhookContentType string `mapstructure:"webhook-content-type"`
+	WebhookToken       string `mapstructure:"webhook-token"`
+	WebhookUser        string `mapstructure:"webhook-user"`
+	WebhookOnly        bool   `mapstructure:"webhook-only"`
+	WebhookNoFail      bool   `mapstructure:"webhook-no-fail"`
+	Verbose            bool
+	ShowPolicySource   bool `mapstructure:"show-policy-source"`
+	ShowPolicyRoot     bool `mapstructure:"show-policy-root"`
+	ShowBuiltinErrors  bool `mapstructure:"show-builtin-errors"`
+
+	NoDeprecationWarnings bool     `mapstructure:"no-deprecation-warnings"`
+	MaxFailures           int      `mapstructure:"max-failures"`
+	FailOnWarnNamespace   []string `mapstructure:"fail-on-warn-namespace"`
+	FailOnCompileWarning  bool     `mapstructure:"fail-on-compile-warning"`
+	RequireTests          bool     `mapstructure:"require-tests"`
+
+	// Skipped contains the files that were skipped when walking
+	// the directories to test. It is populated by Run.
+	Skipped []SkippedFile `mapstructure:"-"`
+
+	// EmptyFiles contains the files that were not combined, because
+	// they only contain empty documents. It is populated by Run.
+	EmptyFiles []string `mapstructure:"-"`
+
+	// NestedStackWarnings contains the nested CloudFormation stacks that
+	// could not be resolved. It is populated by Run.
+	NestedStackWarnings []string `mapstructure:"-"`
+
+	// Deprecations contains the rules of the evaluated namespaces that
+	// use a deprecated name. It is populated by Run.
+	Deprecations []policy.Deprecation `mapstructure:"-"`
+
+	// InvalidMessages contains the rules that returned a value that is
+	// not a message. It is populated by Run and RunStream.
+	InvalidMessages []policy.InvalidMessage `mapstructure:"-"`
+
+	// Timings contains how long the evaluation of every file took. It is
+	// only populated by Run when an OpenTelemetry endpoint is set.
+	Timings []output.Timing `mapstructure:"-"`
+
+	// CombinedSize is the total size in bytes of the files that were
+	// combined into a single input. It is populated by Run when combining.
+	CombinedSize int64 `mapstructure:"-"`
+
+	// WhatIfResults contains the results of the input with the what-if
+	// patch applied. It is only populated by Run when a patch is set.
+	WhatIfResults []output.CheckResult `mapstructure:"-"`
 }
 
 // Run executes the TestRunner, verifying all Rego policies against the given
 // list of configuration files.
 func (t *TestRunner) Run(ctx context.Context, fileList []string) ([]output.CheckResult, error) {
-	files, err := parseFileList(fileList, t.Ignore)
+	if t.TraceFormat != "" && t.TraceFormat != policy.TraceFormatPretty && t.TraceFormat != policy.TraceFormatJSON {
+		return nil, fmt.Errorf("unknown trace format: %v", t.TraceFormat)
+	}
+
+	if t.GroupData != "" && t.CombineBy == "" {
+		return nil, fmt.Errorf("--group-data requires --combine-by")
+	}
+
+	if t.PolicyStdin {
+		for _, file := range fileList {
+			if file == "-" {
+				return nil, fmt.Errorf("the policy and the input cannot both be read from standard input")
+			}
+		}
+	}
+
+	files, skipped, err := parseFileList(fileList, t.Ignore, t.Parser, t.StrictInput)
 	if err != nil {
 		return nil, fmt.Errorf("parse files: %w", err)
 	}
+	t.Skipped = skipped
 
-	var configurations map[string]interface{}
-	if t.Parser != "" {
-		configurations, err = parser.ParseConfigurationsAs(files, t.Parser)
-	} else {
-		configurations, err = parser.ParseConfigurations(files)
+	parserOptions := parser.Options{
+		StrictYAML:         t.StrictYAML,
+		HelmSourceComments: t.HelmSourceComments,
+		DhallNoRemote:      t.DhallNoRemote,
+		CSVNoHeader:        t.CSVNoHeader,
+		JsonnetPath:        t.JsonnetPath,
+		ResolveIncludes:    t.ResolveIncludes,
+		RemoteIncludes:     t.RemoteIncludes,
+		StrictInput:        t.StrictInput,
 	}
+
+	configurations, sources, err := parser.ParseConfigurationsWithSources(files, t.Parser, parserOptions)
 	if err != nil {
-		return nil, fmt.Errorf("parse configurations: %w", err)
+		return nil, &ParseError{Err: err}
+	}
+
+	if len(t.Overlay) > 0 {
+		overlayConfigurations, err := parser.ParseConfigurationsWithOptions(t.Overlay, t.Parser, parserOptions)
+		if err != nil {
+			return nil, &ParseError{Err: fmt.Errorf("overlays: %w", err)}
+		}
+
+		var overlays []interface{}
+		for _, overlay := range t.Overlay {
+			if _, ok := overlayConfigurations[overlay].(map[string]interface{}); !ok {
+				return nil, &ParseError{Err: fmt.Errorf("overlay %v must be a single object", overlay)}
+			}
+
+			overlays = append(overlays, overlayConfigurations[overlay])
+		}
+
+		if err := parser.ApplyOverlays(configurations, overlays); err != nil {
+			return nil, &ParseError{Err: err}
+		}
+	}
+
+	// Empty documents, such as the document of an empty YAML file, would show
+	// up as null values in the combined input, so they are not combined.
+	t.EmptyFiles = nil
+	t.CombinedSize = 0
+	if t.Combine || t.CombineBy != "" || t.CombineKeyed {
+		t.EmptyFiles = parser.RemoveEmptyConfigurations(configurations)
+		t.CombinedSize = filesSize(files)
+	}
+
+	if t.NormalizeCIDR {
+		parser.NormalizeCIDRs(configurations)
+	}
+
+	if t.ExpandLabels {
+		parser.ExpandLabels(configurations)
+	}
+
+	t.NestedStackWarnings = nil
+	if t.NestedStacks != "" {
+		t.NestedStackWarnings = parser.ResolveNestedStacks(configurations, t.NestedStacks)
+	}
+
+	// The what-if patch is applied to the input as it is evaluated, so the
+	// patched input is only evaluated file by file.
+	var patchedConfigurations map[string]interface{}
+	if t.WhatIf != "" {
+		if t.Combine || t.CombineBy != "" || t.CombineKeyed {
+			return nil, fmt.Errorf("what-if cannot be used when combining files")
+		}
+
+		patchedConfigurations, err = applyWhatIf(configurations, t.WhatIf, parserOptions)
+		if err != nil {
+			return nil, fmt.Errorf("what-if: %w", err)
+		}
+	}
+
+	engine, err := t.newEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := t.namespaces(engine)
+	if err != nil {
+		return nil, err
+	}
+
+	// Helm values files and rendered manifests can be evaluated against their own
+	// namespaces, in which case every namespace only evaluates the files routed to it.
+	testedNamespaces := namespaces
+	var route *helmRoute
+	if len(t.HelmNamespaces) > 0 {
+		if t.Combine || t.CombineBy != "" || t.CombineKeyed {
+			return nil, fmt.Errorf("helm namespaces cannot be used when combining files")
+		}
+
+		route, err = newHelmRoute(t.HelmNamespaces, configurations)
+		if err != nil {
+			return nil, fmt.Errorf("helm namespaces: %w", err)
+		}
+
+		namespaces = route.allNamespaces(namespaces)
+	}
+
+	// A rule that does not exist in any of the namespaces is most likely a typo,
+	// which would otherwise silently result in nothing being evaluated.
+	if t.Rule != "" {
+		engine.SetRule(t.Rule)
+
+		var found bool
+		for _, namespace := range namespaces {
+			if len(engine.Rules(namespace)) > 0 {
+				found = true
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("no rule named %v found in namespaces %v", t.Rule, namespaces)
+		}
+	}
+
+	t.Deprecations = nil
+	for _, namespace := range namespaces {
+		t.Deprecations = append(t.Deprecations, engine.Deprecations(namespace)...)
+	}
+
+	t.Timings = nil
+	t.WhatIfResults = nil
+	var results []output.CheckResult
+	for _, namespace := range namespaces {
+		start := time.Now()
+		if t.CombineKeyed {
+			combined, err := parser.CombineConfigurationsByParser(configurations, t.Parser)
+			if err != nil {
+				return nil, fmt.Errorf("combine configurations by parser: %w", err)
+			}
+
+			result, err := engine.Check(ctx, combined, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("check combined by parser: %w", err)
+			}
+
+			t.addTimings(result, start)
+			results = append(results, result...)
+		} else if t.CombineBy != "" {
+			result, err := engine.CheckCombinedBy(ctx, configurations, namespace, t.CombineBy)
+			if err != nil {
+				return nil, fmt.Errorf("check combined by: %w", err)
+			}
+
+			t.addTimings(result, start)
+			results = append(results, result...)
+		} else if t.Combine {
+			result, err := engine.CheckCombined(ctx, configurations, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("check combined: %w", err)
+			}
+
+			t.addTimings([]output.CheckResult{result}, start)
+			results = append(results, result)
+		} else {
+			configs := configurations
+			if route != nil {
+				configs = route.configurations(configurations, namespace, testedNamespaces)
+			}
+
+			result, err := t.check(ctx, engine, configs, namespace)
+			if err != nil {
+				return nil, fmt.Errorf("query rule: %w", err)
+			}
+
+			results = append(results, result...)
+
+			if patchedConfigurations != nil {
+				patchedConfigs := patchedConfigurations
+				if route != nil {
+					patchedConfigs = route.configurations(patchedConfigurations, namespace, testedNamespaces)
+				}
+
+				patchedResult, err := engine.Check(ctx, patchedConfigs, namespace)
+				if err != nil {
+					return nil, fmt.Errorf("query rule with what-if patch: %w", err)
+				}
+
+				t.WhatIfResults = append(t.WhatIfResults, patchedResult...)
+			}
+		}
+	}
+
+	// When multiple namespaces are evaluated together, the namespace of every
+	// result is kept in its metadata, for outputs that do not group by namespace.
+	if len(namespaces) > 1 {
+		output.AddNamespaceMetadata(results)
+		output.AddNamespaceMetadata(t.WhatIfResults)
+	}
+
+	if t.HelmSourceComments {
+		addSources(results, sources)
+	}
+
+	// Combined results can optionally be reported per file, based on the file
+	// that each result was attributed to by the policy.
+	if (t.Combine || t.CombineBy != "" || t.CombineKeyed) && t.SplitByFile {
+		results = output.SplitByFile(results)
+	}
+
+	if t.Blame {
+		addBlame(ctx, results)
+	}
+
+	if t.RunID != "" {
+		output.SetRunID(results, t.RunID)
 	}
 
+	t.InvalidMessages = engine.InvalidMessages()
+
+	return results, nil
+}
+
+// filesSize returns the total size of the given files. The size of the files on
+// disk is used as an estimate of the size of the input, since measuring the parsed
+// input would use even more memory. Files that cannot be read, such as standard
+// input, are not counted.
+func filesSize(files []string) int64 {
+	var size int64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			continue
+		}
+
+		size += info.Size()
+	}
+
+	return size
+}
+
+// newEngine loads the policies into a new engine that is configured
+// with the options of the runner.
+func (t *TestRunner) newEngine(ctx context.Context) (*policy.Engine, error) {
 	// When there are policies to download, they are currently placed in the first
 	// directory that appears in the list of policies.
 	if len(t.Update) > 0 {
+		if len(t.Policy) == 0 {
+			return nil, fmt.Errorf("updating policies requires a policy directory")
+		}
+
 		if err := downloader.Download(ctx, t.Policy[0], t.Update); err != nil {
 			return nil, fmt.Errorf("update policies: %w", err)
 		}
 	}
 
-	engine, err := policy.LoadWithData(ctx, t.Policy, t.Data)
+	options := policy.Options{
+		Libraries:             t.Libraries,
+		ExcludeTestFiles:      t.ExcludeTestFiles,
+		Bundles:               t.Bundle,
+		FailOnCompileWarnings: t.FailOnCompileWarning,
+		GroupData:             t.GroupData,
+	}
+
+	if t.Capabilities != "" {
+		capabilities, err := policy.LoadCapabilities(t.Capabilities)
+		if err != nil {
+			return nil, fmt.Errorf("load capabilities: %w", err)
+		}
+
+		options.Capabilities = capabilities
+	}
+
+	engine, err := t.loadEngine(ctx, options)
 	if err != nil {
 		return nil, fmt.Errorf("load: %w", err)
 	}
 
 	if t.Trace {
 		engine.EnableTracing()
+		engine.SetTraceFormat(t.TraceFormat)
+	}
+
+	if t.ShowPolicySource {
+		engine.EnablePolicySource()
 	}
 
+	if t.ShowPolicyRoot {
+		engine.EnablePolicyRoot()
+	}
+
+	if t.ShowBuiltinErrors {
+		engine.EnableBuiltinErrors()
+	}
+
+	if t.Output == output.OutputRaw {
+		engine.EnableRawResults()
+	}
+
+	return engine, nil
+}
+
+// namespaces returns the namespaces that are evaluated, and sets up the
+// namespace fallback of the engine when it is enabled.
+func (t *TestRunner) namespaces(engine *policy.Engine) ([]string, error) {
 	namespaces := t.Namespace
 	if t.AllNamespaces {
 		namespaces = engine.Namespaces()
 	}
 
-	var results []output.CheckResult
+	// The order of all of the namespaces is alphabetical rather than chosen by
+	// the user, so it cannot be used as the order of precedence.
+	if t.NamespaceFallback {
+		if t.AllNamespaces {
+			return nil, fmt.Errorf("namespace fallback cannot be used with all namespaces")
+		}
+
+		engine.SetNamespaceFallback(t.Namespace)
+	}
+
+	return namespaces, nil
+}
+
+// Rules returns the rules that are evaluated in the namespaces of the
+// runner, without loading or evaluating any configuration files.
+func (t *TestRunner) Rules(ctx context.Context) ([]policy.RuleDefinition, error) {
+	engine, err := t.newEngine(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces, err := t.namespaces(engine)
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Rule != "" {
+		engine.SetRule(t.Rule)
+	}
+
+	var rules []policy.RuleDefinition
 	for _, namespace := range namespaces {
-		if t.Combine {
-			result, err := engine.CheckCombined(ctx, configurations, namespace)
-			if err != nil {
-				return nil, fmt.Errorf("check combined: %w", err)
+		rules = append(rules, engine.RuleDefinitions(namespace)...)
+	}
+
+	return rules, nil
+}
+
+// applyWhatIf returns a copy of the configurations where the JSON patch in the
+// file at the given path is applied to every configuration.
+func applyWhatIf(configurations map[string]interface{}, path string, options parser.Options) (map[string]interface{}, error) {
+	patches, err := parser.ParseConfigurationsWithOptions([]string{path}, "", options)
+	if err != nil {
+		return nil, fmt.Errorf("parse patch: %w", err)
+	}
+
+	operations, ok := patches[path].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("patch must be a list of JSON patch operations")
+	}
+
+	patched := make(map[string]interface{})
+	for file, config := range configurations {
+		patchedConfig, err := parser.ApplyPatch(config, operations)
+		if err != nil {
+			return nil, fmt.Errorf("apply patch to %v: %w", file, err)
+		}
+
+		patched[file] = patchedConfig
+	}
+
+	return patched, nil
+}
+
+// check evaluates the configurations against the namespace. When the evaluation is
+// timed, every file is evaluated on its own, so that the timing of each file is known.
+func (t *TestRunner) check(ctx context.Context, engine *policy.Engine, configs map[string]interface{}, namespace string) ([]output.CheckResult, error) {
+	if t.OtelEndpoint == "" {
+		return engine.Check(ctx, configs, namespace)
+	}
+
+	var paths []string
+	for path := range configs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var results []output.CheckResult
+	for _, path := range paths {
+		start := time.Now()
+		result, err := engine.Check(ctx, map[string]interface{}{path: configs[path]}, namespace)
+		if err != nil {
+			return nil, err
+		}
+
+		t.addTimings(result, start)
+		results = append(results, result...)
+	}
+
+	return results, nil
+}
+
+// addTimings records that the evaluation of the given results started at the
+// given time and ended now, when the evaluation is timed.
+func (t *TestRunner) addTimings(results []output.CheckResult, start time.Time) {
+	if t.OtelEndpoint == "" {
+		return
+	}
+
+	end := time.Now()
+	for _, result := range results {
+		t.Timings = append(t.Timings, output.Timing{
+			FileName:  result.FileName,
+			Namespace: result.Namespace,
+			Start:     start,
+			End:       end,
+		})
+	}
+}
+
+// addSources adds the Source comment of the document that produced each result
+// to the metadata of the result, unless the policy already set a source.
+func addSources(results []output.CheckResult, sources map[string][]string) {
+	add := func(results []output.Result, fileSources []string) {
+		for r := range results {
+			var index int
+			if results[r].Document != nil {
+				index = *results[r].Document
 			}
 
-			results = append(results, result)
-		} else {
-			result, err := engine.Check(ctx, configurations, namespace)
-			if err != nil {
-				return nil, fmt.Errorf("query rule: %w", err)
+			if index >= len(fileSources) || fileSources[index] == "" {
+				continue
 			}
 
-			results = append(results, result...)
+			if results[r].Metadata == nil {
+				results[r].Metadata = make(map[string]interface{})
+			}
+
+			if _, ok := results[r].Metadata["source"]; !ok {
+				results[r].Metadata["source"] = fileSources[index]
+			}
 		}
 	}
 
-	return results, nil
+	for _, result := range results {
+		fileSources, ok := sources[result.FileName]
+		if !ok {
+			continue
+		}
+
+		add(result.Warnings, fileSources)
+		add(result.Failures, fileSources)
+		add(result.Exceptions, fileSources)
+		add(result.Errors, fileSources)
+	}
 }
 
-func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
+// loadEngine loads the policies from the policy paths, or from standard
+// input when the policy is read from standard input.
+func (t *TestRunner) loadEngine(ctx context.Context, options policy.Options) (*policy.Engine, error) {
+	if !t.PolicyStdin {
+		return policy.LoadWithOptions(ctx, t.Policy, t.Data, options)
+	}
+
+	source, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		return nil, fmt.Errorf("read standard in: %w", err)
+	}
+
+	return policy.LoadSourceWithOptions(ctx, "stdin.rego", string(source), t.Data, options)
+}
+
+// The reasons that a file can be skipped for.
+const (
+	SkipReasonIgnored      = "ignored"
+	SkipReasonUnsupported  = "unsupported"
+	SkipReasonUnrecognized = "unrecognized"
+)
+
+// SkippedFile is a file that was found when walking a directory,
+// but that was not tested.
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+func parseFileList(fileList []string, ignoreRegex string, parserName string, strict bool) ([]string, []SkippedFile, error) {
 	var files []string
+	var skipped []SkippedFile
 	for _, file := range fileList {
 		if file == "" {
 			continue
@@ -108,35 +655,37 @@ func parseFileList(fileList []string, ignoreRegex string) ([]string, error) {
 
 		fileInfo, err := os.Stat(file)
 		if err != nil {
-			return nil, fmt.Errorf("get file info: %w", err)
+			return nil, nil, fmt.Errorf("get file info: %w", err)
 		}
 
 		if fileInfo.IsDir() {
-			directoryFiles, err := getFilesFromDirectory(file, ignoreRegex)
+			directoryFiles, directorySkipped, err := getFilesFromDirectory(file, ignoreRegex, parserName, strict)
 			if err != nil {
-				return nil, fmt.Errorf("get files from directory: %w", err)
+				return nil, nil, fmt.Errorf("get files from directory: %w", err)
 			}
 
 			files = append(files, directoryFiles...)
+			skipped = append(skipped, directorySkipped...)
 		} else {
 			files = append(files, file)
 		}
 	}
 
 	if len(files) == 0 {
-		return nil, fmt.Errorf("no files found")
+		return nil, nil, ErrNoInput
 	}
 
-	return files, nil
+	return files, skipped, nil
 }
 
-func getFilesFromDirectory(directory string, ignoreRegex string) ([]string, error) {
+func getFilesFromDirectory(directory string, ignoreRegex string, parserName string, strict bool) ([]string, []SkippedFile, error) {
 	regexp, err := regexp.Compile(ignoreRegex)
 	if err != nil {
-		return nil, fmt.Errorf("given regexp couldn't be parsed :%w", err)
+		return nil, nil, fmt.Errorf("given regexp couldn't be parsed :%w", err)
 	}
 
 	var files []string
+	var skipped []SkippedFile
 	walk := func(currentPath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return fmt.Errorf("walk path: %w", err)
@@ -147,20 +696,40 @@ func getFilesFromDirectory(directory string, ignoreRegex string) ([]string, erro
 		}
 
 		if ignoreRegex != "" && regexp.MatchString(currentPath) {
+			skipped = append(skipped, SkippedFile{Path: currentPath, Reason: SkipReasonIgnored})
+			return nil
+		}
+
+		// Files that are not supported, including the files that are detected as a
+		// parser that is not one of the allowed parsers and, with strict input, the
+		// files that would only be parsed as YAML by default, are skipped, since
+		// they would not be parsed. They are only an error when passed directly.
+		if !parser.FileSupportedAs(currentPath, parserName) || (strict && parser.CheckRecognized(currentPath, parserName) != nil) {
+			skipped = append(skipped, SkippedFile{Path: currentPath, Reason: SkipReasonUnsupported})
 			return nil
 		}
 
-		if parser.FileSupported(currentPath) {
-			files = append(files, currentPath)
+		// Extensions such as .conf are shared by many formats, so the files whose
+		// contents are not in a format that the parser for the extension parses,
+		// such as nginx configurations, are skipped rather than failing the run.
+		supported, err := parser.ContentsSupportedAs(currentPath, parserName)
+		if err != nil {
+			return fmt.Errorf("detect contents of %v: %w", currentPath, err)
 		}
 
+		if !supported {
+			skipped = append(skipped, SkippedFile{Path: currentPath, Reason: SkipReasonUnrecognized})
+			return nil
+		}
+
+		files = append(files, currentPath)
 		return nil
 	}
 
 	err = filepath.Walk(directory, walk)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return files, nil
+	return files, skipped, nil
 }
@@ -15,15 +15,27 @@ import (
 // VerifyRunner is the runner for the Verify command, executing
 // Rego policy unit-tests.
 type VerifyRunner struct {
-	Policy  []string
-	Data    []string
-	Output  string
-	NoColor bool `mapstructure:"no-color"`
-	Trace   bool
+	Policy      []string
+	Data        []string
+	Output      string
+	NoColor     bool `mapstructure:"no-color"`
+	Trace       bool
+	TraceFormat string `mapstructure:"trace-format"`
+	Bench       bool
+
+	RequireTests bool `mapstructure:"require-tests"`
+
+	// UntestedRules contains the rules that are not referenced by any test.
+	// It is only populated by Run when tests are required.
+	UntestedRules []policy.UntestedRule `mapstructure:"-"`
 }
 
 // Run executes the Rego tests for the given policies.
 func (r *VerifyRunner) Run(ctx context.Context) ([]output.CheckResult, error) {
+	if r.TraceFormat != "" && r.TraceFormat != policy.TraceFormatPretty && r.TraceFormat != policy.TraceFormatJSON {
+		return nil, fmt.Errorf("unknown trace format: %v", r.TraceFormat)
+	}
+
 	engine, err := policy.LoadWithData(ctx, r.Policy, r.Data)
 	if err != nil {
 		return nil, fmt.Errorf("load: %w", err)
@@ -33,7 +45,12 @@ func (r *VerifyRunner) Run(ctx context.Context) ([]output.CheckResult, error) {
 		engine.EnableTracing()
 	}
 
-	runner := tester.NewRunner().SetCompiler(engine.Compiler()).SetStore(engine.Store()).SetModules(engine.Modules()).EnableTracing(r.Trace).SetRuntime(engine.Runtime())
+	r.UntestedRules = nil
+	if r.RequireTests {
+		r.UntestedRules = engine.UntestedRules()
+	}
+
+	runner := newTestRunner(engine).EnableTracing(r.Trace)
 	ch, err := runner.RunTests(ctx, nil)
 	if err != nil {
 		return nil, fmt.Errorf("running tests: %w", err)
@@ -45,19 +62,31 @@ func (r *VerifyRunner) Run(ctx context.Context) ([]output.CheckResult, error) {
 			return nil, fmt.Errorf("run test: %w", result.Error)
 		}
 
-		buf := new(bytes.Buffer)
-		topdown.PrettyTrace(buf, result.Trace)
 		var traces []string
-		for _, line := range strings.Split(buf.String(), "\n") {
-			if len(line) > 0 {
-				traces = append(traces, line)
+		if r.TraceFormat == policy.TraceFormatJSON {
+			traces, err = policy.JSONTrace(result.Trace)
+			if err != nil {
+				return nil, fmt.Errorf("json trace: %w", err)
+			}
+		} else {
+			buf := new(bytes.Buffer)
+			topdown.PrettyTrace(buf, result.Trace)
+			for _, line := range strings.Split(buf.String(), "\n") {
+				if len(line) > 0 {
+					traces = append(traces, line)
+				}
 			}
 		}
 
-		var outputResult output.Result
+		outputResult := output.Result{
+			Rule: result.Name,
+		}
 		if result.Fail || result.Skip {
 			outputResult.Message = result.Package + "." + result.Name
 		}
+		if result.Fail {
+			outputResult.Message, outputResult.Metadata = failureDetails(outputResult.Message, result)
+		}
 
 		queryResult := output.QueryResult{
 			Query:   result.Name,
@@ -82,3 +111,80 @@ func (r *VerifyRunner) Run(ctx context.Context) ([]output.CheckResult, error) {
 
 	return results, nil
 }
+
+// failureDetails adds the details of why the given test failed to the message, and
+// returns the details as metadata as well. The details include the location of the
+// test and, when it is known, the expression of the test that failed.
+func failureDetails(message string, result *tester.Result) (string, map[string]interface{}) {
+	metadata := map[string]interface{}{
+		"duration": result.Duration.String(),
+	}
+
+	if result.Location != nil {
+		metadata["location"] = fmt.Sprintf("%s:%d", result.Location.File, result.Location.Row)
+	}
+
+	if result.FailedAt == nil {
+		return message, metadata
+	}
+
+	failedAt := result.FailedAt.String()
+	metadata["failed_at"] = failedAt
+
+	if result.FailedAt.Location != nil {
+		failedAtLocation := fmt.Sprintf("%s:%d", result.FailedAt.Location.File, result.FailedAt.Location.Row)
+		metadata["failed_at_location"] = failedAtLocation
+		return fmt.Sprintf("%s - failed at %s: %s", message, failedAtLocation, failedAt), metadata
+	}
+
+	return fmt.Sprintf("%s - failed at %s", message, failedAt), metadata
+}
+
+// RunBenchmarks benchmarks the Rego tests for the given policies.
+func (r *VerifyRunner) RunBenchmarks(ctx context.Context) ([]output.BenchmarkResult, error) {
+	engine, err := policy.LoadWithData(ctx, r.Policy, r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	runner := newTestRunner(engine)
+	ch, err := runner.RunBenchmarks(ctx, nil, tester.BenchmarkOptions{ReportAllocations: true})
+	if err != nil {
+		return nil, fmt.Errorf("running benchmarks: %w", err)
+	}
+
+	var results []output.BenchmarkResult
+	for result := range ch {
+		if result.Error != nil {
+			return nil, fmt.Errorf("run benchmark: %w", result.Error)
+		}
+
+		if result.Fail {
+			return nil, fmt.Errorf("benchmark %s.%s failed", result.Package, result.Name)
+		}
+
+		if result.BenchmarkResult == nil {
+			continue
+		}
+
+		results = append(results, output.BenchmarkResult{
+			FileName:    result.Location.File,
+			Package:     result.Package,
+			Name:        result.Name,
+			Iterations:  result.BenchmarkResult.N,
+			NsPerOp:     result.BenchmarkResult.NsPerOp(),
+			BytesPerOp:  result.BenchmarkResult.AllocedBytesPerOp(),
+			AllocsPerOp: result.BenchmarkResult.AllocsPerOp(),
+		})
+	}
+
+	return results, nil
+}
+
+func newTestRunner(engine *policy.Engine) *tester.Runner {
+	return tester.NewRunner().
+		SetCompiler(engine.Compiler()).
+		SetStore(engine.Store()).
+		SetModules(engine.Modules()).
+		SetRuntime(engine.Runtime())
+}
@@ -0,0 +1,79 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// AzureDevOps represents an Outputter that outputs results
+// as Azure DevOps logging commands.
+type AzureDevOps struct {
+	Writer io.Writer
+}
+
+// NewAzureDevOps creates a new AzureDevOps with the given writer.
+func NewAzureDevOps(w io.Writer) *AzureDevOps {
+	azureDevOps := AzureDevOps{
+		Writer: w,
+	}
+
+	return &azureDevOps
+}
+
+// Output outputs the results.
+func (a *AzureDevOps) Output(checkResults []CheckResult) error {
+	var hasFailure bool
+	var hasWarning bool
+	for _, result := range checkResults {
+		for _, warning := range result.Warnings {
+			hasWarning = true
+			a.logIssue("warning", result.FileName, warning.Message)
+		}
+
+		for _, failure := range result.Failures {
+			hasFailure = true
+			a.logIssue("error", result.FileName, failure.Message)
+		}
+
+		for _, evaluationError := range result.Errors {
+			hasFailure = true
+			a.logIssue("error", result.FileName, evaluationError.Message)
+		}
+	}
+
+	// The task result is set so that the build step reflects the results,
+	// even when conftest is configured to not return a non-zero exit code.
+	switch {
+	case hasFailure:
+		fmt.Fprintln(a.Writer, "##vso[task.complete result=Failed;]Policy failures found")
+	case hasWarning:
+		fmt.Fprintln(a.Writer, "##vso[task.complete result=SucceededWithIssues;]Policy warnings found")
+	}
+
+	return nil
+}
+
+func (a *AzureDevOps) logIssue(issueType string, fileName string, message string) {
+	properties := "type=" + issueType
+	if fileName != "-" && fileName != "" {
+		properties += ";sourcepath=" + escapeAzureDevOpsProperty(fileName)
+	}
+
+	fmt.Fprintf(a.Writer, "##vso[task.logissue %s;]%s\n", properties, escapeAzureDevOpsMessage(message))
+}
+
+// escapeAzureDevOpsMessage escapes the message of a logging command, which
+// would otherwise be cut off at the first line break.
+func escapeAzureDevOpsMessage(message string) string {
+	replacer := strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A")
+	return replacer.Replace(message)
+}
+
+// escapeAzureDevOpsProperty escapes a property value of a logging command.
+// On top of the characters escaped in messages, the characters that separate
+// the properties themselves need to be escaped.
+func escapeAzureDevOpsProperty(value string) string {
+	replacer := strings.NewReplacer("%", "%AZP25", "\r", "%0D", "\n", "%0A", "]", "%5D", ";", "%3B")
+	return replacer.Replace(value)
+}
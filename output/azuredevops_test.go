@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAzureDevOps(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "No warnings or errors",
+			input: []CheckResult{
+				{
+					FileName: "examples/kubernetes/service.yaml",
+				},
+			},
+			expected: []string{""},
+		},
+		{
+			name: "A warning and a failure",
+			input: []CheckResult{
+				{
+					FileName: "examples/kubernetes/service.yaml",
+					Warnings: []Result{{Message: "first warning"}},
+					Failures: []Result{{Message: "first failure"}},
+				},
+			},
+			expected: []string{
+				"##vso[task.logissue type=warning;sourcepath=examples/kubernetes/service.yaml;]first warning",
+				"##vso[task.logissue type=error;sourcepath=examples/kubernetes/service.yaml;]first failure",
+				"##vso[task.complete result=Failed;]Policy failures found",
+				"",
+			},
+		},
+		{
+			name: "An error",
+			input: []CheckResult{
+				{
+					FileName: "examples/kubernetes/service.yaml",
+					Errors:   []Result{{Message: "first error"}},
+				},
+			},
+			expected: []string{
+				"##vso[task.logissue type=error;sourcepath=examples/kubernetes/service.yaml;]first error",
+				"##vso[task.complete result=Failed;]Policy failures found",
+				"",
+			},
+		},
+		{
+			name: "Only warnings",
+			input: []CheckResult{
+				{
+					FileName: "-",
+					Warnings: []Result{{Message: "first warning"}},
+				},
+			},
+			expected: []string{
+				"##vso[task.logissue type=warning;]first warning",
+				"##vso[task.complete result=SucceededWithIssues;]Policy warnings found",
+				"",
+			},
+		},
+		{
+			name: "Characters that require escaping",
+			input: []CheckResult{
+				{
+					FileName: "dir;name]/file.yaml",
+					Failures: []Result{{Message: "100% of\r\nreplicas"}},
+				},
+			},
+			expected: []string{
+				"##vso[task.logissue type=error;sourcepath=dir%3Bname%5D/file.yaml;]100%AZP25 of%0D%0Areplicas",
+				"##vso[task.complete result=Failed;]Policy failures found",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := NewAzureDevOps(buf).Output(tt.input); err != nil {
+				t.Fatal("output azure devops:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// BenchmarkResult describes how long a single Rego unit test
+// took to evaluate when benchmarked.
+type BenchmarkResult struct {
+	FileName    string `json:"filename"`
+	Package     string `json:"package"`
+	Name        string `json:"name"`
+	Iterations  int    `json:"iterations"`
+	NsPerOp     int64  `json:"ns_per_op"`
+	BytesPerOp  int64  `json:"bytes_per_op"`
+	AllocsPerOp int64  `json:"allocs_per_op"`
+}
+
+// OutputBenchmarks writes the benchmark results, slowest first, to the given
+// writer. Results are written as JSON when the format is json, and as a table
+// for all other formats.
+func OutputBenchmarks(w io.Writer, format string, results []BenchmarkResult) error {
+	sorted := make([]BenchmarkResult, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].NsPerOp > sorted[j].NsPerOp
+	})
+
+	if format == OutputJSON {
+		b, err := json.Marshal(sorted)
+		if err != nil {
+			return fmt.Errorf("marshal json: %w", err)
+		}
+
+		var out bytes.Buffer
+		if err := json.Indent(&out, b, "", "\t"); err != nil {
+			return fmt.Errorf("indent: %w", err)
+		}
+
+		fmt.Fprintln(w, out.String())
+		return nil
+	}
+
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"test", "file", "iterations", "ns/op", "B/op", "allocs/op"})
+	for _, result := range sorted {
+		table.Append([]string{
+			result.Package + "." + result.Name,
+			result.FileName,
+			strconv.Itoa(result.Iterations),
+			strconv.FormatInt(result.NsPerOp, 10),
+			strconv.FormatInt(result.BytesPerOp, 10),
+			strconv.FormatInt(result.AllocsPerOp, 10),
+		})
+	}
+
+	if len(sorted) > 0 {
+		table.Render()
+	}
+
+	return nil
+}
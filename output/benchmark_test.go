@@ -0,0 +1,40 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestOutputBenchmarksJSON(t *testing.T) {
+	input := []BenchmarkResult{
+		{FileName: "policy/a_test.rego", Package: "data.main", Name: "test_fast", Iterations: 1000, NsPerOp: 100},
+		{FileName: "policy/b_test.rego", Package: "data.main", Name: "test_slow", Iterations: 10, NsPerOp: 5000},
+		{FileName: "policy/a_test.rego", Package: "data.main", Name: "test_medium", Iterations: 100, NsPerOp: 900},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := OutputBenchmarks(buf, OutputJSON, input); err != nil {
+		t.Fatal("output benchmarks:", err)
+	}
+
+	var actual []BenchmarkResult
+	if err := json.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatal("unmarshal:", err)
+	}
+
+	var names []string
+	for _, result := range actual {
+		names = append(names, result.Name)
+	}
+
+	expected := []string{"test_slow", "test_medium", "test_fast"}
+	if !reflect.DeepEqual(expected, names) {
+		t.Errorf("Unexpected ordering. expected %v actual %v", expected, names)
+	}
+
+	if input[0].Name != "test_fast" {
+		t.Errorf("Input was modified. expected %v actual %v", "test_fast", input[0].Name)
+	}
+}
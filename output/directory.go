@@ -0,0 +1,125 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Directory writes a separate report for every file to a directory,
+// using the outputter of the given format for each report.
+type Directory struct {
+	Path    string
+	Format  string
+	Options Options
+}
+
+// NewDirectory creates a new Directory that writes reports in the
+// given format to the given directory.
+func NewDirectory(path string, format string, options Options) *Directory {
+	return &Directory{
+		Path:    path,
+		Format:  format,
+		Options: options,
+	}
+}
+
+// Output writes a report for every file in the results to the directory.
+// The directory is created if it does not exist.
+func (d *Directory) Output(results []CheckResult) error {
+	if err := os.MkdirAll(d.Path, os.ModePerm); err != nil {
+		return fmt.Errorf("create output directory: %w", err)
+	}
+
+	// A file can have results in multiple namespaces, which
+	// are all written to the report of the file.
+	var fileNames []string
+	fileResults := make(map[string][]CheckResult)
+	for _, result := range results {
+		if _, ok := fileResults[result.FileName]; !ok {
+			fileNames = append(fileNames, result.FileName)
+		}
+
+		fileResults[result.FileName] = append(fileResults[result.FileName], result)
+	}
+
+	// Reports are never colored, as they are not written to a terminal.
+	options := d.Options
+	options.NoColor = true
+
+	usedNames := make(map[string]bool)
+	for _, fileName := range fileNames {
+		reportName := uniqueReportName(reportFileName(fileName, d.Format), usedNames)
+		if err := d.writeReport(filepath.Join(d.Path, reportName), fileResults[fileName], options); err != nil {
+			return fmt.Errorf("write report for %v: %w", fileName, err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Directory) writeReport(path string, results []CheckResult, options Options) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create report: %w", err)
+	}
+	defer file.Close()
+
+	if err := GetWithWriter(d.Format, file, options).Output(results); err != nil {
+		return fmt.Errorf("output: %w", err)
+	}
+
+	return file.Close()
+}
+
+// reportFileName returns the name of the report for the given file. Nested paths are
+// flattened into a single file name, so that files with the same name in different
+// directories (e.g. frontend/values.yaml and backend/values.yaml) get different reports.
+func reportFileName(fileName string, format string) string {
+	name := filepath.ToSlash(filepath.Clean(fileName))
+	if fileName == "-" {
+		name = "stdin"
+	}
+
+	var segments []string
+	for _, segment := range strings.Split(name, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			continue
+		}
+
+		segments = append(segments, segment)
+	}
+
+	return strings.Join(segments, "_") + reportExtension(format)
+}
+
+// uniqueReportName returns the given name, or the name with a numbered suffix
+// when the name is already used by the report of another file.
+func uniqueReportName(name string, usedNames map[string]bool) string {
+	unique := name
+	for i := 2; usedNames[unique]; i++ {
+		extension := filepath.Ext(name)
+		unique = fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, extension), i, extension)
+	}
+
+	usedNames[unique] = true
+	return unique
+}
+
+func reportExtension(format string) string {
+	switch format {
+	case OutputJSON, OutputRaw:
+		return ".json"
+	case OutputJUnit:
+		return ".xml"
+	case OutputSARIF:
+		return ".sarif"
+	case OutputTAP:
+		return ".tap"
+	case OutputPrometheus:
+		return ".prom"
+	default:
+		return ".txt"
+	}
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirectory(t *testing.T) {
+	results := []CheckResult{
+		{FileName: "frontend/values.yaml", Namespace: "main", Failures: []Result{{Message: "first failure"}}},
+		{FileName: "backend/values.yaml", Namespace: "main", Successes: 1},
+		{FileName: "frontend_values.yaml", Namespace: "main", Successes: 1},
+		{FileName: "frontend/values.yaml", Namespace: "other", Warnings: []Result{{Message: "first warning"}}},
+	}
+
+	directory := filepath.Join(t.TempDir(), "reports")
+	if err := NewDirectory(directory, OutputJSON, Options{}).Output(results); err != nil {
+		t.Fatal("output results:", err)
+	}
+
+	expected := map[string][]CheckResult{
+		"frontend_values.yaml.json":   {results[0], results[3]},
+		"backend_values.yaml.json":    {results[1]},
+		"frontend_values.yaml-2.json": {results[2]},
+	}
+
+	files, err := ioutil.ReadDir(directory)
+	if err != nil {
+		t.Fatal("read reports:", err)
+	}
+
+	if len(files) != len(expected) {
+		t.Errorf("Unexpected number of reports. expected %v actual %v", len(expected), len(files))
+	}
+
+	for name, expectedResults := range expected {
+		contents, err := ioutil.ReadFile(filepath.Join(directory, name))
+		if err != nil {
+			t.Fatalf("read report %v: %v", name, err)
+		}
+
+		var actualResults []CheckResult
+		if err := json.Unmarshal(contents, &actualResults); err != nil {
+			t.Fatalf("unmarshal report %v: %v", name, err)
+		}
+
+		if !reflect.DeepEqual(expectedResults, actualResults) {
+			t.Errorf("Unexpected report %v. expected %v actual %v", name, expectedResults, actualResults)
+		}
+	}
+}
+
+func TestReportFileName(t *testing.T) {
+	testCases := []struct {
+		fileName string
+		format   string
+		expected string
+	}{
+		{fileName: "deployment.yaml", format: OutputStandard, expected: "deployment.yaml.txt"},
+		{fileName: "k8s/prod/deployment.yaml", format: OutputJUnit, expected: "k8s_prod_deployment.yaml.xml"},
+		{fileName: "/abs/path/main.tf", format: OutputSARIF, expected: "abs_path_main.tf.sarif"},
+		{fileName: "../shared/values.yaml", format: OutputTAP, expected: "shared_values.yaml.tap"},
+		{fileName: "-", format: OutputJSON, expected: "stdin.json"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.fileName, func(t *testing.T) {
+			actual := reportFileName(testCase.fileName, testCase.format)
+			if actual != testCase.expected {
+				t.Errorf("Unexpected report file name. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// GitHub represents an Outputter that outputs results as GitHub Actions
+// workflow commands, which are shown as annotations on the pull request.
+type GitHub struct {
+	Writer io.Writer
+}
+
+// NewGitHub creates a new GitHub with the given writer.
+func NewGitHub(w io.Writer) *GitHub {
+	github := GitHub{
+		Writer: w,
+	}
+
+	return &github
+}
+
+// Output outputs the results. Only the warnings, failures and errors are
+// written, as an annotation for every success would only add noise.
+func (g *GitHub) Output(checkResults []CheckResult) error {
+	for _, result := range checkResults {
+		for _, warning := range result.Warnings {
+			g.annotate("warning", result.FileName, warning)
+		}
+
+		for _, failure := range result.Failures {
+			g.annotate("error", result.FileName, failure)
+		}
+
+		for _, evaluationError := range result.Errors {
+			g.annotate("error", result.FileName, evaluationError)
+		}
+	}
+
+	return nil
+}
+
+func (g *GitHub) annotate(command string, fileName string, result Result) {
+	var properties []string
+	if fileName != "-" && fileName != "" {
+		properties = append(properties, "file="+escapeGitHubProperty(fileName))
+	}
+
+	if line, ok := resultLine(result); ok {
+		properties = append(properties, fmt.Sprintf("line=%d", line))
+	}
+
+	if len(properties) > 0 {
+		command += " " + strings.Join(properties, ",")
+	}
+
+	fmt.Fprintf(g.Writer, "::%s::%s\n", command, escapeGitHubMessage(result.Message))
+}
+
+// resultLine returns the line in the line field of the metadata of the result,
+// when the metadata has a line that is a positive whole number.
+func resultLine(result Result) (int, bool) {
+	var line float64
+	switch value := result.Metadata["line"].(type) {
+	case int:
+		line = float64(value)
+	case float64:
+		line = value
+	case json.Number:
+		number, err := value.Float64()
+		if err != nil {
+			return 0, false
+		}
+		line = number
+	default:
+		return 0, false
+	}
+
+	if line < 1 || line != float64(int(line)) {
+		return 0, false
+	}
+
+	return int(line), true
+}
+
+// escapeGitHubMessage escapes the message of a workflow command, which
+// would otherwise be cut off at the first line break.
+func escapeGitHubMessage(message string) string {
+	replacer := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	return replacer.Replace(message)
+}
+
+// escapeGitHubProperty escapes a property value of a workflow command. On top
+// of the characters escaped in messages, the characters that separate the
+// properties themselves need to be escaped.
+func escapeGitHubProperty(value string) string {
+	replacer := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	return replacer.Replace(value)
+}
@@ -0,0 +1,118 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGitHub(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "No warnings or errors",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Successes: 2,
+				},
+			},
+			expected: []string{""},
+		},
+		{
+			name: "A warning and a failure",
+			input: []CheckResult{
+				{
+					FileName: "examples/kubernetes/service.yaml",
+					Warnings: []Result{{Message: "first warning"}},
+					Failures: []Result{{Message: "first failure"}},
+				},
+			},
+			expected: []string{
+				"::warning file=examples/kubernetes/service.yaml::first warning",
+				"::error file=examples/kubernetes/service.yaml::first failure",
+				"",
+			},
+		},
+		{
+			name: "An error",
+			input: []CheckResult{
+				{
+					FileName: "examples/kubernetes/service.yaml",
+					Errors:   []Result{{Message: "first error"}},
+				},
+			},
+			expected: []string{
+				"::error file=examples/kubernetes/service.yaml::first error",
+				"",
+			},
+		},
+		{
+			name: "Failures with a line",
+			input: []CheckResult{
+				{
+					FileName: "main.tf",
+					Failures: []Result{
+						{Message: "json number", Metadata: map[string]interface{}{"line": json.Number("12")}},
+						{Message: "float", Metadata: map[string]interface{}{"line": float64(3)}},
+						{Message: "not a whole number", Metadata: map[string]interface{}{"line": 1.5}},
+						{Message: "not a number", Metadata: map[string]interface{}{"line": "7"}},
+					},
+				},
+			},
+			expected: []string{
+				"::error file=main.tf,line=12::json number",
+				"::error file=main.tf,line=3::float",
+				"::error file=main.tf::not a whole number",
+				"::error file=main.tf::not a number",
+				"",
+			},
+		},
+		{
+			name: "Escaped message and file name",
+			input: []CheckResult{
+				{
+					FileName: "dir,name/a:b.yaml",
+					Failures: []Result{{Message: "100% broken\nsecond line"}},
+				},
+			},
+			expected: []string{
+				"::error file=dir%2Cname/a%3Ab.yaml::100%25 broken%0Asecond line",
+				"",
+			},
+		},
+		{
+			name: "Standard input",
+			input: []CheckResult{
+				{
+					FileName: "-",
+					Failures: []Result{{Message: "first failure"}},
+				},
+			},
+			expected: []string{
+				"::error::first failure",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := NewGitHub(buf).Output(tt.input); err != nil {
+				t.Fatal("output github:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
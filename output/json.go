@@ -11,6 +11,10 @@ import (
 // results in JSON format.
 type JSON struct {
 	Writer io.Writer
+
+	// GroupBy controls whether the results are grouped by
+	// file (the default), by rule or by namespace.
+	GroupBy string
 }
 
 // NewJSON creates a new JSON with the given writer.
@@ -32,7 +36,15 @@ func (j *JSON) Output(results []CheckResult) error {
 		results[r].Queries = nil
 	}
 
-	b, err := json.Marshal(results)
+	var grouped interface{} = results
+	switch j.GroupBy {
+	case GroupByRule:
+		grouped = GroupResultsByRule(results)
+	case GroupByNamespace:
+		grouped = GroupResultsByNamespace(results)
+	}
+
+	b, err := json.Marshal(grouped)
 	if err != nil {
 		return fmt.Errorf("marshal json: %w", err)
 	}
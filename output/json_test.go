@@ -140,6 +140,66 @@ func TestJSON(t *testing.T) {
 				``,
 			},
 		},
+		{
+			name: "A failure with a message id and arguments",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Namespace: "namespace",
+					Failures: []Result{
+						{
+							Message: "Deployment hello-kubernetes must not run as root",
+							Metadata: map[string]interface{}{
+								"id":   "K8S-001",
+								"args": []interface{}{"hello-kubernetes"},
+							},
+						},
+					},
+				},
+			},
+			expected: []string{
+				`[`,
+				`	{`,
+				`		"filename": "examples/kubernetes/deployment.yaml",`,
+				`		"namespace": "namespace",`,
+				`		"successes": 0,`,
+				`		"failures": [`,
+				`			{`,
+				`				"msg": "Deployment hello-kubernetes must not run as root",`,
+				`				"metadata": {`,
+				`					"args": [`,
+				`						"hello-kubernetes"`,
+				`					],`,
+				`					"id": "K8S-001"`,
+				`				}`,
+				`			}`,
+				`		]`,
+				`	}`,
+				`]`,
+				``,
+			},
+		},
+		{
+			name: "A run id",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					RunID:     "pipeline-1234",
+				},
+			},
+			expected: []string{
+				`[`,
+				`	{`,
+				`		"filename": "examples/kubernetes/service.yaml",`,
+				`		"namespace": "namespace",`,
+				`		"successes": 0,`,
+				`		"run_id": "pipeline-1234"`,
+				`	}`,
+				`]`,
+				``,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -158,3 +218,106 @@ func TestJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestJSONGroupByRule(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/deployment.yaml",
+			Namespace: "main",
+			Failures:  []Result{{Message: "first failure", Rule: "deny"}},
+		},
+		{
+			FileName:  "examples/kubernetes/service.yaml",
+			Namespace: "main",
+			Failures:  []Result{{Message: "second failure", Rule: "deny"}},
+		},
+	}
+
+	expected := []string{
+		`[`,
+		`	{`,
+		`		"namespace": "main",`,
+		`		"rule": "deny",`,
+		`		"failures": [`,
+		`			{`,
+		`				"filename": "examples/kubernetes/deployment.yaml",`,
+		`				"msg": "first failure"`,
+		`			},`,
+		`			{`,
+		`				"filename": "examples/kubernetes/service.yaml",`,
+		`				"msg": "second failure"`,
+		`			}`,
+		`		]`,
+		`	}`,
+		`]`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	jsonOutput := JSON{Writer: buf, GroupBy: GroupByRule}
+	if err := jsonOutput.Output(input); err != nil {
+		t.Fatal("output json:", err)
+	}
+	actual := buf.String()
+
+	if strings.Join(expected, "\n") != actual {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+}
+
+func TestJSONGroupByNamespace(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "team.b",
+			Failures:  []Result{{Message: "first failure"}},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "team.a",
+			Successes: 1,
+		},
+	}
+
+	expected := []string{
+		`[`,
+		`	{`,
+		`		"namespace": "team.a",`,
+		`		"results": [`,
+		`			{`,
+		`				"filename": "deployment.yaml",`,
+		`				"namespace": "team.a",`,
+		`				"successes": 1`,
+		`			}`,
+		`		]`,
+		`	},`,
+		`	{`,
+		`		"namespace": "team.b",`,
+		`		"results": [`,
+		`			{`,
+		`				"filename": "deployment.yaml",`,
+		`				"namespace": "team.b",`,
+		`				"successes": 0,`,
+		`				"failures": [`,
+		`					{`,
+		`						"msg": "first failure"`,
+		`					}`,
+		`				]`,
+		`			}`,
+		`		]`,
+		`	}`,
+		`]`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	jsonOutput := JSON{Writer: buf, GroupBy: GroupByNamespace}
+	if err := jsonOutput.Output(input); err != nil {
+		t.Fatal("output json:", err)
+	}
+	actual := buf.String()
+
+	if strings.Join(expected, "\n") != actual {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+}
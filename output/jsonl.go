@@ -0,0 +1,43 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// JSONLines represents an Outputter that outputs every result
+// as a JSON object on its own line (JSON Lines).
+type JSONLines struct {
+	Writer io.Writer
+}
+
+// NewJSONLines creates a new JSONLines with the given writer.
+func NewJSONLines(w io.Writer) *JSONLines {
+	jsonLines := JSONLines{
+		Writer: w,
+	}
+
+	return &jsonLines
+}
+
+// Output outputs the results. As with the JSON output, the queries
+// are not included and results read from stdin have no file name.
+func (j *JSONLines) Output(results []CheckResult) error {
+	for _, result := range results {
+		if result.FileName == "-" {
+			result.FileName = ""
+		}
+
+		result.Queries = nil
+
+		line, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("marshal json: %w", err)
+		}
+
+		fmt.Fprintln(j.Writer, string(line))
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestJSONLines(t *testing.T) {
+	document := 1
+	input := []CheckResult{
+		{
+			FileName:  "-",
+			Namespace: "main",
+			Successes: 1,
+			Failures:  []Result{{Message: "first failure", Document: &document}},
+			Queries:   []QueryResult{{Query: "data.main.deny"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Successes: 2,
+		},
+	}
+
+	expected := []string{
+		`{"filename":"","namespace":"main","successes":1,"failures":[{"msg":"first failure","document":1}]}`,
+		`{"filename":"service.yaml","namespace":"main","successes":2}`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewJSONLines(buf).Output(input); err != nil {
+		t.Fatal("output json lines:", err)
+	}
+
+	if actual := buf.String(); actual != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+
+	if input[0].FileName != "-" || input[0].Queries == nil {
+		t.Error("Expected the results to be left unchanged")
+	}
+}
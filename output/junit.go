@@ -14,6 +14,11 @@ import (
 // results in JUnit format.
 type JUnit struct {
 	Writer io.Writer
+
+	// SuitePerFile writes a test suite for every file, rather than a single
+	// conftest test suite. The warnings are then reported as skipped tests,
+	// so that only the failures fail the test suite.
+	SuitePerFile bool
 }
 
 // NewJUnit creates a new JUnit with the given writer.
@@ -27,12 +32,31 @@ func NewJUnit(w io.Writer) *JUnit {
 
 // Output outputs the results.
 func (j *JUnit) Output(results []CheckResult) error {
-	var tests []*parser.Test
+	warningResult := parser.FAIL
+	if j.SuitePerFile {
+		warningResult = parser.SKIP
+	}
+
+	var packages []parser.Package
+	packageIndexes := make(map[string]int)
 	for _, result := range results {
+		name := "conftest"
+		if j.SuitePerFile {
+			name = result.FileName
+		}
+
+		index, ok := packageIndexes[name]
+		if !ok {
+			index = len(packages)
+			packageIndexes[name] = index
+			packages = append(packages, parser.Package{Name: name})
+		}
+
+		var tests []*parser.Test
 		for _, warning := range result.Warnings {
 			warningTest := parser.Test{
-				Name:   getTestName(result.FileName, result.Namespace, warning.Message),
-				Result: parser.FAIL,
+				Name:   getTestName(documentFileName(result.FileName, warning), result.Namespace, warning.Message),
+				Result: warningResult,
 				Output: []string{warning.Message},
 			}
 
@@ -41,7 +65,7 @@ func (j *JUnit) Output(results []CheckResult) error {
 
 		for _, failure := range result.Failures {
 			failingTest := parser.Test{
-				Name:   getTestName(result.FileName, result.Namespace, failure.Message),
+				Name:   getTestName(documentFileName(result.FileName, failure), result.Namespace, failure.Message),
 				Result: parser.FAIL,
 				Output: []string{failure.Message},
 			}
@@ -49,9 +73,21 @@ func (j *JUnit) Output(results []CheckResult) error {
 			tests = append(tests, &failingTest)
 		}
 
+		// Rules that failed to evaluate fail the test suite, as it is not
+		// known whether the file passes them.
+		for _, evaluationError := range result.Errors {
+			errorTest := parser.Test{
+				Name:   getTestName(documentFileName(result.FileName, evaluationError), result.Namespace, evaluationError.Message),
+				Result: parser.FAIL,
+				Output: []string{evaluationError.Message},
+			}
+
+			tests = append(tests, &errorTest)
+		}
+
 		for _, skipped := range result.Skipped {
 			skippedTest := parser.Test{
-				Name:   getTestName(result.FileName, result.Namespace, skipped.Message),
+				Name:   getTestName(documentFileName(result.FileName, skipped), result.Namespace, skipped.Message),
 				Result: parser.SKIP,
 				Output: []string{skipped.Message},
 			}
@@ -68,15 +104,17 @@ func (j *JUnit) Output(results []CheckResult) error {
 
 			tests = append(tests, &successfulTest)
 		}
+
+		packages[index].Tests = append(packages[index].Tests, tests...)
+	}
+
+	// Without any results, there is still a single conftest test suite.
+	if len(packages) == 0 {
+		packages = append(packages, parser.Package{Name: "conftest"})
 	}
 
 	report := parser.Report{
-		Packages: []parser.Package{
-			{
-				Name:  "conftest",
-				Tests: tests,
-			},
-		},
+		Packages: packages,
 	}
 
 	if err := formatter.JUnitReportXML(&report, false, runtime.Version(), j.Writer); err != nil {
@@ -66,6 +66,30 @@ func TestJUnit(t *testing.T) {
 				``,
 			},
 		},
+		{
+			name: "An error",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Errors:    []Result{{Message: "json.unmarshal: invalid character"}},
+				},
+			},
+			expected: []string{
+				`<?xml version="1.0" encoding="UTF-8"?>`,
+				`<testsuites>`,
+				`	<testsuite tests="1" failures="1" time="0.000" name="conftest">`,
+				`		<properties>`,
+				`			<property name="go.version" value="%s"></property>`,
+				`		</properties>`,
+				`		<testcase classname="conftest" name="examples/kubernetes/service.yaml - namespace - json.unmarshal: invalid character" time="0.000">`,
+				`			<failure message="Failed" type="">json.unmarshal: invalid character</failure>`,
+				`		</testcase>`,
+				`	</testsuite>`,
+				`</testsuites>`,
+				``,
+			},
+		},
 		{
 			name: "Failure with a long description",
 			input: []CheckResult{
@@ -110,3 +134,55 @@ This is the rest of the description of the failed test`}},
 		})
 	}
 }
+
+func TestJUnitSuitePerFile(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Successes: 1,
+			Warnings:  []Result{{Message: "image is not pinned"}},
+			Failures:  []Result{{Message: "containers must not run as root"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Successes: 1,
+		},
+	}
+
+	expected := []string{
+		`<?xml version="1.0" encoding="UTF-8"?>`,
+		`<testsuites>`,
+		`	<testsuite tests="3" failures="1" time="0.000" name="deployment.yaml">`,
+		`		<properties>`,
+		`			<property name="go.version" value="%[1]s"></property>`,
+		`		</properties>`,
+		`		<testcase classname="deployment.yaml" name="deployment.yaml - main - image is not pinned" time="0.000">`,
+		`			<skipped message="image is not pinned"></skipped>`,
+		`		</testcase>`,
+		`		<testcase classname="deployment.yaml" name="deployment.yaml - main - containers must not run as root" time="0.000">`,
+		`			<failure message="Failed" type="">containers must not run as root</failure>`,
+		`		</testcase>`,
+		`		<testcase classname="deployment.yaml" name="deployment.yaml - main" time="0.000"></testcase>`,
+		`	</testsuite>`,
+		`	<testsuite tests="1" failures="0" time="0.000" name="service.yaml">`,
+		`		<properties>`,
+		`			<property name="go.version" value="%[1]s"></property>`,
+		`		</properties>`,
+		`		<testcase classname="service.yaml" name="service.yaml - main" time="0.000"></testcase>`,
+		`	</testsuite>`,
+		`</testsuites>`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	outputter := &JUnit{Writer: buf, SuitePerFile: true}
+	if err := outputter.Output(input); err != nil {
+		t.Fatal("output junit:", err)
+	}
+
+	if actual, expected := buf.String(), fmt.Sprintf(strings.Join(expected, "\n"), runtime.Version()); expected != actual {
+		t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+	}
+}
@@ -0,0 +1,264 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Timing records when the evaluation of a file against a namespace started and ended.
+type Timing struct {
+	FileName  string
+	Namespace string
+	Start     time.Time
+	End       time.Time
+}
+
+// The status code of OTLP spans that failed. Spans that did not fail leave
+// the status unset.
+const otlpStatusError = 2
+
+// The span kind of the spans, which are all internal to conftest.
+const otlpSpanKindInternal = 1
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope  `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Events            []otlpEvent     `json:"events,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string `json:"stringValue,omitempty"`
+	IntValue    *string `json:"intValue,omitempty"`
+}
+
+func otlpString(key string, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: &value}}
+}
+
+// OTLP encodes integers as strings in JSON, as they are 64-bit integers.
+func otlpInt(key string, value int) otlpAttribute {
+	encoded := strconv.Itoa(value)
+	return otlpAttribute{Key: key, Value: otlpValue{IntValue: &encoded}}
+}
+
+func otlpTime(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// ExportSpans exports the results as OpenTelemetry spans to the given OTLP/HTTP
+// endpoint, encoded as JSON. A single conftest test span contains a span for every
+// file and namespace that was evaluated, which uses the timing of the evaluation of
+// the file when one is given. Every warning, failure, exception and error is added
+// to the span of its file as an event.
+//
+// When the endpoint does not have a path, the spans are sent to the /v1/traces path
+// of the endpoint. Otherwise, the spans are sent to the endpoint as it is.
+func ExportSpans(ctx context.Context, client *http.Client, endpoint string, results []CheckResult, timings []Timing) error {
+	target, err := url.Parse(endpoint)
+	if err != nil {
+		return fmt.Errorf("parse endpoint: %w", err)
+	}
+
+	if target.Path == "" || target.Path == "/" {
+		target.Path = "/v1/traces"
+	}
+
+	spans, err := otlpSpans(results, timings, time.Now())
+	if err != nil {
+		return fmt.Errorf("create spans: %w", err)
+	}
+
+	request := otlpRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{otlpString("service.name", "conftest")},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/open-policy-agent/conftest"},
+				Spans: spans,
+			}},
+		}},
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return fmt.Errorf("marshal spans: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("send spans: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// otlpSpans returns the root span followed by the span of every result. The
+// results that do not have a timing start and end when the root span ends.
+func otlpSpans(results []CheckResult, timings []Timing, now time.Time) ([]otlpSpan, error) {
+	traceID, err := randomID(16)
+	if err != nil {
+		return nil, fmt.Errorf("trace id: %w", err)
+	}
+
+	rootID, err := randomID(8)
+	if err != nil {
+		return nil, fmt.Errorf("span id: %w", err)
+	}
+
+	timingsByFile := make(map[string]Timing)
+	start, end := now, now
+	for _, timing := range timings {
+		timingsByFile[timing.Namespace+"\x00"+timing.FileName] = timing
+		if timing.Start.Before(start) {
+			start = timing.Start
+		}
+	}
+
+	root := otlpSpan{
+		TraceID:           traceID,
+		SpanID:            rootID,
+		Name:              "conftest test",
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: otlpTime(start),
+		EndTimeUnixNano:   otlpTime(end),
+		Attributes: []otlpAttribute{
+			otlpInt("conftest.tests", TestCount(results)),
+			otlpInt("conftest.failures", FailureCount(results)),
+			otlpString("conftest.status", Status(results)),
+		},
+	}
+	if ExitCode(results) > 0 {
+		root.Status = otlpStatus{Code: otlpStatusError, Message: "policy failures"}
+	}
+
+	spans := []otlpSpan{root}
+	for _, result := range results {
+		spanID, err := randomID(8)
+		if err != nil {
+			return nil, fmt.Errorf("span id: %w", err)
+		}
+
+		timing, ok := timingsByFile[result.Namespace+"\x00"+result.FileName]
+		if !ok {
+			timing = Timing{Start: end, End: end}
+		}
+
+		span := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            spanID,
+			ParentSpanID:      rootID,
+			Name:              result.FileName,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: otlpTime(timing.Start),
+			EndTimeUnixNano:   otlpTime(timing.End),
+			Attributes: []otlpAttribute{
+				otlpString("conftest.file", result.FileName),
+				otlpString("conftest.namespace", result.Namespace),
+				otlpInt("conftest.successes", result.Successes),
+				otlpInt("conftest.warnings", len(result.Warnings)),
+				otlpInt("conftest.failures", len(result.Failures)),
+				otlpInt("conftest.exceptions", len(result.Exceptions)),
+				otlpInt("conftest.errors", len(result.Errors)),
+			},
+		}
+
+		addEvents := func(name string, results []Result) {
+			for _, r := range results {
+				span.Events = append(span.Events, otlpEvent{
+					TimeUnixNano: otlpTime(timing.End),
+					Name:         name,
+					Attributes: []otlpAttribute{
+						otlpString("conftest.rule", r.Rule),
+						otlpString("conftest.message", r.Message),
+					},
+				})
+			}
+		}
+		addEvents("warning", result.Warnings)
+		addEvents("failure", result.Failures)
+		addEvents("exception", result.Exceptions)
+		addEvents("error", result.Errors)
+
+		if len(result.Failures) > 0 || len(result.Errors) > 0 {
+			span.Status = otlpStatus{Code: otlpStatusError, Message: "policy failures"}
+		}
+
+		spans = append(spans, span)
+	}
+
+	return spans, nil
+}
+
+func randomID(length int) (string, error) {
+	id := make([]byte, length)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("read random bytes: %w", err)
+	}
+
+	return hex.EncodeToString(id), nil
+}
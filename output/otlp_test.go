@@ -0,0 +1,112 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportSpans(t *testing.T) {
+	var path string
+	var request otlpRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	results := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Successes: 1,
+			Failures:  []Result{{Message: "containers must not run as root", Rule: "deny_root"}},
+			Warnings:  []Result{{Message: "missing resource limits", Rule: "warn_limits"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Successes: 3,
+		},
+	}
+
+	start := time.Unix(100, 0)
+	timings := []Timing{
+		{FileName: "deployment.yaml", Namespace: "main", Start: start, End: start.Add(time.Second)},
+	}
+
+	if err := ExportSpans(context.Background(), server.Client(), server.URL, results, timings); err != nil {
+		t.Fatalf("export spans: %v", err)
+	}
+
+	if path != "/v1/traces" {
+		t.Errorf("Unexpected path. expected %v actual %v", "/v1/traces", path)
+	}
+
+	spans := request.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 3 {
+		t.Fatalf("Unexpected number of spans. expected 3 actual %v", len(spans))
+	}
+
+	root, deployment, service := spans[0], spans[1], spans[2]
+	if root.Status.Code != otlpStatusError {
+		t.Errorf("Unexpected root status. expected %v actual %v", otlpStatusError, root.Status.Code)
+	}
+
+	if root.StartTimeUnixNano != "100000000000" {
+		t.Errorf("Unexpected root start. expected the start of the first file actual %v", root.StartTimeUnixNano)
+	}
+
+	if deployment.ParentSpanID != root.SpanID || deployment.TraceID != root.TraceID {
+		t.Errorf("Unexpected parent. expected the root span actual %v", deployment.ParentSpanID)
+	}
+
+	if deployment.StartTimeUnixNano != "100000000000" || deployment.EndTimeUnixNano != "101000000000" {
+		t.Errorf("Unexpected timing. expected 100s to 101s actual %v to %v", deployment.StartTimeUnixNano, deployment.EndTimeUnixNano)
+	}
+
+	var events []string
+	for _, event := range deployment.Events {
+		events = append(events, event.Name)
+	}
+
+	if len(events) != 2 || events[0] != "warning" || events[1] != "failure" {
+		t.Errorf("Unexpected events. expected [warning failure] actual %v", events)
+	}
+
+	if service.Status.Code != 0 || len(service.Events) != 0 {
+		t.Errorf("Unexpected service span. expected no status and no events actual %+v", service)
+	}
+}
+
+func TestExportSpansWithPath(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+	}))
+	defer server.Close()
+
+	if err := ExportSpans(context.Background(), server.Client(), server.URL+"/otlp/traces", nil, nil); err != nil {
+		t.Fatalf("export spans: %v", err)
+	}
+
+	if path != "/otlp/traces" {
+		t.Errorf("Unexpected path. expected %v actual %v", "/otlp/traces", path)
+	}
+}
+
+func TestExportSpansUnexpectedStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if err := ExportSpans(context.Background(), server.Client(), server.URL, nil, nil); err == nil {
+		t.Error("expected an error when the collector returns an unexpected status")
+	}
+}
@@ -1,6 +1,9 @@
 package output
 
-import "os"
+import (
+	"io"
+	"os"
+)
 
 // Outputter controls how results of an evaluation will
 // be recorded and reported to the end user.
@@ -15,33 +18,73 @@ type Options struct {
 	NoColor            bool
 	SuppressExceptions bool
 	ShowSkipped        bool
+	GroupBy            string
+	JUnitSuitePerFile  bool
 }
 
+// The defined groupings represent how results can be
+// grouped by the outputs that support grouping.
+const (
+	GroupByFile      = "file"
+	GroupByRule      = "rule"
+	GroupByNamespace = "namespace"
+)
+
 // The defined output formats represent all of the supported formats
 // that can be used to format and render results.
 const (
-	OutputStandard = "stdout"
-	OutputJSON     = "json"
-	OutputTAP      = "tap"
-	OutputTable    = "table"
-	OutputJUnit    = "junit"
+	OutputStandard    = "stdout"
+	OutputJSON        = "json"
+	OutputTAP         = "tap"
+	OutputTable       = "table"
+	OutputJUnit       = "junit"
+	OutputSARIF       = "sarif"
+	OutputPrometheus  = "prometheus"
+	OutputAzureDevOps = "azuredevops"
+	OutputRaw         = "raw"
+	OutputSummary     = "summary"
+	OutputRemediation = "remediation"
+	OutputJSONLines   = "jsonl"
+	OutputGitHub      = "github"
 )
 
 // Get returns a type that can render output in the given format.
 func Get(format string, options Options) Outputter {
+	return GetWithWriter(format, os.Stdout, options)
+}
+
+// GetWithWriter returns a type that can render output in the given
+// format to the given writer.
+func GetWithWriter(format string, w io.Writer, options Options) Outputter {
 	switch format {
 	case OutputStandard:
-		return &Standard{Writer: os.Stdout, NoColor: options.NoColor, SuppressExceptions: options.SuppressExceptions, Tracing: options.Tracing, ShowSkipped: options.ShowSkipped}
+		return &Standard{Writer: w, NoColor: options.NoColor, SuppressExceptions: options.SuppressExceptions, Tracing: options.Tracing, ShowSkipped: options.ShowSkipped, GroupBy: options.GroupBy}
 	case OutputJSON:
-		return NewJSON(os.Stdout)
+		return &JSON{Writer: w, GroupBy: options.GroupBy}
 	case OutputTAP:
-		return NewTAP(os.Stdout)
+		return NewTAP(w)
 	case OutputTable:
-		return NewTable(os.Stdout)
+		return NewTable(w)
 	case OutputJUnit:
-		return NewJUnit(os.Stdout)
+		return &JUnit{Writer: w, SuitePerFile: options.JUnitSuitePerFile}
+	case OutputSARIF:
+		return NewSARIF(w)
+	case OutputPrometheus:
+		return NewPrometheus(w)
+	case OutputAzureDevOps:
+		return NewAzureDevOps(w)
+	case OutputRaw:
+		return NewRaw(w)
+	case OutputSummary:
+		return &Summary{Writer: w, NoColor: options.NoColor}
+	case OutputRemediation:
+		return NewRemediation(w)
+	case OutputJSONLines:
+		return NewJSONLines(w)
+	case OutputGitHub:
+		return NewGitHub(w)
 	default:
-		return NewStandard(os.Stdout)
+		return NewStandard(w)
 	}
 }
 
@@ -53,5 +96,13 @@ func Outputs() []string {
 		OutputTAP,
 		OutputTable,
 		OutputJUnit,
+		OutputSARIF,
+		OutputPrometheus,
+		OutputAzureDevOps,
+		OutputRaw,
+		OutputSummary,
+		OutputRemediation,
+		OutputJSONLines,
+		OutputGitHub,
 	}
 }
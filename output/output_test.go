@@ -31,6 +31,38 @@ func TestGetOutputter(t *testing.T) {
 			input:    OutputJUnit,
 			expected: NewJUnit(os.Stdout),
 		},
+		{
+			input:    OutputSARIF,
+			expected: NewSARIF(os.Stdout),
+		},
+		{
+			input:    OutputPrometheus,
+			expected: NewPrometheus(os.Stdout),
+		},
+		{
+			input:    OutputAzureDevOps,
+			expected: NewAzureDevOps(os.Stdout),
+		},
+		{
+			input:    OutputRaw,
+			expected: NewRaw(os.Stdout),
+		},
+		{
+			input:    OutputSummary,
+			expected: NewSummary(os.Stdout),
+		},
+		{
+			input:    OutputRemediation,
+			expected: NewRemediation(os.Stdout),
+		},
+		{
+			input:    OutputJSONLines,
+			expected: NewJSONLines(os.Stdout),
+		},
+		{
+			input:    OutputGitHub,
+			expected: NewGitHub(os.Stdout),
+		},
 		{
 			input:    "unknown_format",
 			expected: NewStandard(os.Stdout),
@@ -0,0 +1,104 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Prometheus represents an Outputter that outputs results as metrics
+// in the Prometheus text exposition format.
+type Prometheus struct {
+	Writer io.Writer
+}
+
+// NewPrometheus creates a new Prometheus with the given writer.
+func NewPrometheus(w io.Writer) *Prometheus {
+	prometheus := Prometheus{
+		Writer: w,
+	}
+
+	return &prometheus
+}
+
+type prometheusMetric struct {
+	name string
+	help string
+}
+
+var (
+	prometheusSuccesses  = prometheusMetric{name: "conftest_successes_total", help: "Number of policy checks that passed."}
+	prometheusWarnings   = prometheusMetric{name: "conftest_warnings_total", help: "Number of policy warnings."}
+	prometheusFailures   = prometheusMetric{name: "conftest_failures_total", help: "Number of policy failures."}
+	prometheusExceptions = prometheusMetric{name: "conftest_exceptions_total", help: "Number of policy failures that were excepted."}
+	prometheusErrors     = prometheusMetric{name: "conftest_errors_total", help: "Number of policy rules that failed to evaluate."}
+)
+
+// Output outputs the results.
+func (p *Prometheus) Output(checkResults []CheckResult) error {
+	samples := make(map[prometheusMetric]map[string]int)
+	for _, metric := range []prometheusMetric{prometheusSuccesses, prometheusWarnings, prometheusFailures, prometheusExceptions, prometheusErrors} {
+		samples[metric] = make(map[string]int)
+	}
+
+	for _, result := range checkResults {
+		if result.Successes > 0 {
+			samples[prometheusSuccesses][prometheusLabels(result.FileName, result.Namespace, "")] += result.Successes
+		}
+
+		for _, warning := range result.Warnings {
+			samples[prometheusWarnings][prometheusLabels(result.FileName, result.Namespace, warning.Rule)]++
+		}
+
+		for _, failure := range result.Failures {
+			samples[prometheusFailures][prometheusLabels(result.FileName, result.Namespace, failure.Rule)]++
+		}
+
+		for _, exception := range result.Exceptions {
+			samples[prometheusExceptions][prometheusLabels(result.FileName, result.Namespace, exception.Rule)]++
+		}
+
+		for _, evaluationError := range result.Errors {
+			samples[prometheusErrors][prometheusLabels(result.FileName, result.Namespace, evaluationError.Rule)]++
+		}
+	}
+
+	for _, metric := range []prometheusMetric{prometheusSuccesses, prometheusWarnings, prometheusFailures, prometheusExceptions, prometheusErrors} {
+		fmt.Fprintf(p.Writer, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(p.Writer, "# TYPE %s counter\n", metric.name)
+
+		var labels []string
+		for label := range samples[metric] {
+			labels = append(labels, label)
+		}
+		sort.Strings(labels)
+
+		for _, label := range labels {
+			fmt.Fprintf(p.Writer, "%s{%s} %d\n", metric.name, label, samples[metric][label])
+		}
+	}
+
+	return nil
+}
+
+func prometheusLabels(fileName string, namespace string, rule string) string {
+	labels := []string{
+		fmt.Sprintf(`file="%s"`, escapeLabelValue(fileName)),
+		fmt.Sprintf(`namespace="%s"`, escapeLabelValue(namespace)),
+	}
+
+	if rule != "" {
+		labels = append(labels, fmt.Sprintf(`rule="%s"`, escapeLabelValue(rule)))
+	}
+
+	return strings.Join(labels, ",")
+}
+
+// escapeLabelValue escapes a label value as required by the Prometheus text
+// exposition format, where backslashes, double quotes and line feeds need
+// to be escaped.
+func escapeLabelValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return replacer.Replace(value)
+}
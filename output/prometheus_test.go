@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrometheus(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/deployment.yaml",
+			Namespace: "main",
+			Successes: 2,
+			Failures: []Result{
+				{Message: "first failure", Rule: "deny"},
+				{Message: "second failure", Rule: "deny"},
+				{Message: "third failure", Rule: "violation_root"},
+			},
+			Warnings: []Result{{Message: "first warning", Rule: "warn"}},
+		},
+		{
+			FileName:  `examples/"quoted"\dir` + "\n" + "service.yaml",
+			Namespace: "main",
+			Exceptions: []Result{
+				{Message: "first exception", Rule: "deny_root"},
+			},
+			Errors: []Result{
+				{Message: "first error", Rule: "deny_invalid"},
+			},
+		},
+	}
+
+	expected := []string{
+		`# HELP conftest_successes_total Number of policy checks that passed.`,
+		`# TYPE conftest_successes_total counter`,
+		`conftest_successes_total{file="examples/kubernetes/deployment.yaml",namespace="main"} 2`,
+		`# HELP conftest_warnings_total Number of policy warnings.`,
+		`# TYPE conftest_warnings_total counter`,
+		`conftest_warnings_total{file="examples/kubernetes/deployment.yaml",namespace="main",rule="warn"} 1`,
+		`# HELP conftest_failures_total Number of policy failures.`,
+		`# TYPE conftest_failures_total counter`,
+		`conftest_failures_total{file="examples/kubernetes/deployment.yaml",namespace="main",rule="deny"} 2`,
+		`conftest_failures_total{file="examples/kubernetes/deployment.yaml",namespace="main",rule="violation_root"} 1`,
+		`# HELP conftest_exceptions_total Number of policy failures that were excepted.`,
+		`# TYPE conftest_exceptions_total counter`,
+		`conftest_exceptions_total{file="examples/\"quoted\"\\dir\nservice.yaml",namespace="main",rule="deny_root"} 1`,
+		`# HELP conftest_errors_total Number of policy rules that failed to evaluate.`,
+		`# TYPE conftest_errors_total counter`,
+		`conftest_errors_total{file="examples/\"quoted\"\\dir\nservice.yaml",namespace="main",rule="deny_invalid"} 1`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewPrometheus(buf).Output(input); err != nil {
+		t.Fatal("output prometheus:", err)
+	}
+
+	actual := buf.String()
+	if strings.Join(expected, "\n") != actual {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+}
@@ -0,0 +1,71 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Raw represents an Outputter that outputs the result sets of
+// the queries as they were returned by OPA. It is intended for
+// debugging what policies return.
+type Raw struct {
+	Writer io.Writer
+}
+
+// NewRaw creates a new Raw with the given writer.
+func NewRaw(w io.Writer) *Raw {
+	raw := Raw{
+		Writer: w,
+	}
+
+	return &raw
+}
+
+type rawCheckResult struct {
+	FileName  string           `json:"filename"`
+	Namespace string           `json:"namespace"`
+	Queries   []rawQueryResult `json:"queries"`
+}
+
+type rawQueryResult struct {
+	Query  string      `json:"query"`
+	Result interface{} `json:"result"`
+}
+
+// Output outputs the results.
+func (r *Raw) Output(checkResults []CheckResult) error {
+	rawResults := make([]rawCheckResult, 0, len(checkResults))
+	for _, checkResult := range checkResults {
+		rawResult := rawCheckResult{
+			FileName:  checkResult.FileName,
+			Namespace: checkResult.Namespace,
+			Queries:   []rawQueryResult{},
+		}
+
+		for _, query := range checkResult.Queries {
+			result := query.Raw
+			if result == nil {
+				result = []interface{}{}
+			}
+
+			rawResult.Queries = append(rawResult.Queries, rawQueryResult{Query: query.Query, Result: result})
+		}
+
+		rawResults = append(rawResults, rawResult)
+	}
+
+	b, err := json.Marshal(rawResults)
+	if err != nil {
+		return fmt.Errorf("marshal json: %w", err)
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "\t"); err != nil {
+		return fmt.Errorf("indent: %w", err)
+	}
+
+	fmt.Fprintln(r.Writer, out.String())
+	return nil
+}
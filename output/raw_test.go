@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRaw(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/service.yaml",
+			Namespace: "main",
+			Queries: []QueryResult{
+				{
+					Query: "data.main.deny",
+					Raw: []interface{}{
+						map[string]interface{}{
+							"expressions": []interface{}{
+								map[string]interface{}{"value": []interface{}{"first failure"}, "text": "data.main.deny"},
+							},
+						},
+					},
+				},
+				{
+					Query: "data.main.warn",
+				},
+			},
+		},
+	}
+
+	expected := []string{
+		`[`,
+		`	{`,
+		`		"filename": "examples/kubernetes/service.yaml",`,
+		`		"namespace": "main",`,
+		`		"queries": [`,
+		`			{`,
+		`				"query": "data.main.deny",`,
+		`				"result": [`,
+		`					{`,
+		`						"expressions": [`,
+		`							{`,
+		`								"text": "data.main.deny",`,
+		`								"value": [`,
+		`									"first failure"`,
+		`								]`,
+		`							}`,
+		`						]`,
+		`					}`,
+		`				]`,
+		`			},`,
+		`			{`,
+		`				"query": "data.main.warn",`,
+		`				"result": []`,
+		`			}`,
+		`		]`,
+		`	}`,
+		`]`,
+		``,
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewRaw(buf).Output(input); err != nil {
+		t.Fatal("output raw:", err)
+	}
+
+	actual := buf.String()
+	if strings.Join(expected, "\n") != actual {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+}
@@ -0,0 +1,159 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// RemediationKey is the metadata key of the suggested remediation of a result.
+// The remediation is either a JSON patch (RFC 6902), given as a single
+// operation or as a list of operations, or any other value, such as a
+// replacement snippet, that describes how to fix the result.
+const RemediationKey = "remediation"
+
+// Remediation represents an Outputter that outputs the remediations
+// of the warnings and failures as a set of JSON patches.
+type Remediation struct {
+	Writer io.Writer
+}
+
+// NewRemediation creates a new Remediation with the given writer.
+func NewRemediation(w io.Writer) *Remediation {
+	remediation := Remediation{
+		Writer: w,
+	}
+
+	return &remediation
+}
+
+// FileRemediation contains the remediations of a single file, or of a
+// single document of a file that contains multiple documents.
+type FileRemediation struct {
+	FileName string `json:"filename"`
+	Document *int   `json:"document,omitempty"`
+
+	// Patch contains the JSON patch operations of all of the results.
+	Patch []interface{} `json:"patch"`
+
+	// Suggestions contains the remediations that are not a JSON patch,
+	// and therefore cannot be applied automatically.
+	Suggestions []RemediationSuggestion `json:"suggestions,omitempty"`
+}
+
+// RemediationSuggestion is a remediation that is not a JSON patch.
+type RemediationSuggestion struct {
+	Rule        string      `json:"rule,omitempty"`
+	Message     string      `json:"msg"`
+	Remediation interface{} `json:"remediation"`
+}
+
+// Output outputs the results.
+func (r *Remediation) Output(results []CheckResult) error {
+	var keys []string
+	remediations := make(map[string]*FileRemediation)
+	add := func(fileName string, result Result) {
+		remediation, ok := result.Metadata[RemediationKey]
+		if !ok {
+			return
+		}
+
+		key := fileName
+		if result.Document != nil {
+			key = fmt.Sprintf("%s\x00%d", fileName, *result.Document)
+		}
+
+		if _, ok := remediations[key]; !ok {
+			keys = append(keys, key)
+			remediations[key] = &FileRemediation{FileName: fileName, Document: result.Document, Patch: []interface{}{}}
+		}
+
+		fileRemediation := remediations[key]
+		if operations, ok := patchOperations(remediation); ok {
+			fileRemediation.Patch = append(fileRemediation.Patch, operations...)
+			return
+		}
+
+		fileRemediation.Suggestions = append(fileRemediation.Suggestions, RemediationSuggestion{
+			Rule:        result.Rule,
+			Message:     result.Message,
+			Remediation: remediation,
+		})
+	}
+
+	for _, result := range results {
+		for _, warning := range result.Warnings {
+			add(result.FileName, warning)
+		}
+
+		for _, failure := range result.Failures {
+			add(result.FileName, failure)
+		}
+	}
+
+	fileRemediations := []FileRemediation{}
+	for _, key := range keys {
+		fileRemediations = append(fileRemediations, *remediations[key])
+	}
+
+	out, err := json.MarshalIndent(fileRemediations, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal remediations: %w", err)
+	}
+
+	fmt.Fprintln(r.Writer, string(out))
+	return nil
+}
+
+// patchOperations returns the JSON patch operations of the given remediation,
+// and whether the remediation is a JSON patch.
+func patchOperations(remediation interface{}) ([]interface{}, bool) {
+	if isPatchOperation(remediation) {
+		return []interface{}{remediation}, true
+	}
+
+	operations, ok := remediation.([]interface{})
+	if !ok || len(operations) == 0 {
+		return nil, false
+	}
+
+	for _, operation := range operations {
+		if !isPatchOperation(operation) {
+			return nil, false
+		}
+	}
+
+	return operations, true
+}
+
+func isPatchOperation(value interface{}) bool {
+	operation, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, hasOp := operation["op"].(string)
+	_, hasPath := operation["path"].(string)
+	return hasOp && hasPath
+}
+
+// formatRemediation returns the remediation of the given result as text,
+// and whether the result has a remediation. Remediations that are not a
+// string, such as JSON patches, are formatted as JSON.
+func formatRemediation(result Result) (string, bool) {
+	remediation, ok := result.Metadata[RemediationKey]
+	if !ok {
+		return "", false
+	}
+
+	if text, ok := remediation.(string); ok {
+		return text, true
+	}
+
+	out, err := json.Marshal(remediation)
+	if err != nil {
+		return fmt.Sprint(remediation), true
+	}
+
+	return string(out), true
+}
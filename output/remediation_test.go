@@ -0,0 +1,107 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestRemediation(t *testing.T) {
+	document := 1
+	results := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{
+					Message: "the latest tag is not allowed",
+					Rule:    "deny_latest_tag",
+					Metadata: map[string]interface{}{
+						"remediation": map[string]interface{}{"op": "replace", "path": "/spec/image", "value": "nginx:1.25"},
+					},
+				},
+				{
+					Message: "containers must not run as root",
+					Rule:    "deny_root",
+					Metadata: map[string]interface{}{
+						"remediation": []interface{}{
+							map[string]interface{}{"op": "add", "path": "/spec/securityContext", "value": map[string]interface{}{}},
+							map[string]interface{}{"op": "add", "path": "/spec/securityContext/runAsNonRoot", "value": true},
+						},
+					},
+				},
+				{Message: "no remediation"},
+			},
+			Warnings: []Result{
+				{
+					Message:  "missing resource limits",
+					Rule:     "warn_limits",
+					Metadata: map[string]interface{}{"remediation": "add resources.limits to every container"},
+				},
+			},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{
+					Message:  "NodePort services are not allowed",
+					Document: &document,
+					Metadata: map[string]interface{}{"remediation": map[string]interface{}{"op": "replace", "path": "/spec/type", "value": "ClusterIP"}},
+				},
+			},
+		},
+		{
+			FileName:  "configmap.yaml",
+			Namespace: "main",
+			Successes: 1,
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewRemediation(buf).Output(results); err != nil {
+		t.Fatal("output remediation:", err)
+	}
+
+	var actual []interface{}
+	if err := json.Unmarshal(buf.Bytes(), &actual); err != nil {
+		t.Fatalf("unmarshal output: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"filename": "deployment.yaml",
+			"patch": []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/spec/image", "value": "nginx:1.25"},
+				map[string]interface{}{"op": "add", "path": "/spec/securityContext", "value": map[string]interface{}{}},
+				map[string]interface{}{"op": "add", "path": "/spec/securityContext/runAsNonRoot", "value": true},
+			},
+			"suggestions": []interface{}{
+				map[string]interface{}{"rule": "warn_limits", "msg": "missing resource limits", "remediation": "add resources.limits to every container"},
+			},
+		},
+		map[string]interface{}{
+			"filename": "service.yaml",
+			"document": float64(1),
+			"patch": []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/spec/type", "value": "ClusterIP"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestRemediationWithoutRemediations(t *testing.T) {
+	buf := new(bytes.Buffer)
+	if err := NewRemediation(buf).Output([]CheckResult{{FileName: "foo.yaml", Successes: 1}}); err != nil {
+		t.Fatal("output remediation:", err)
+	}
+
+	if buf.String() != "[]\n" {
+		t.Errorf("Unexpected output. expected an empty list actual %v", buf.String())
+	}
+}
@@ -1,11 +1,41 @@
 package output
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // Result describes the result of a single rule evaluation.
 type Result struct {
 	Message  string                 `json:"msg"`
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Document is the index of the document that produced the result when the
+	// file contains multiple documents, such as a multi-document YAML file.
+	Document *int `json:"document,omitempty"`
+
+	// PolicySource contains the policy files that define the rule
+	// that produced the result, when requested.
+	PolicySource []string `json:"policy_source,omitempty"`
+
+	// Rule is the name of the rule that produced the result (e.g. deny).
+	Rule string `json:"-"`
+
+	// Description is the description of the rule that produced the result,
+	// taken from the comments above the rule in the policy.
+	Description string `json:"-"`
+}
+
+// documentFileName returns the given file name followed by the index of the
+// document that produced the result, when it is known (e.g. deployment.yaml[1]).
+// Standard input is always written as -.
+func documentFileName(fileName string, result Result) string {
+	if result.Document == nil || fileName == "-" {
+		return fileName
+	}
+
+	return fmt.Sprintf("%s[%d]", fileName, *result.Document)
 }
 
 // NewResult creates a new result. An error is returned if the
@@ -52,6 +82,10 @@ type QueryResult struct {
 	// Traces represents a single trace of how the query was
 	// evaluated. Each trace value is a trace line.
 	Traces []string `json:"traces"`
+
+	// Raw is the result set of the query as it was returned by
+	// OPA, before it was interpreted into results.
+	Raw interface{} `json:"-"`
 }
 
 // Passed returns true if all of the results in the query
@@ -78,14 +112,329 @@ type CheckResult struct {
 	Failures   []Result      `json:"failures,omitempty"`
 	Exceptions []Result      `json:"exceptions,omitempty"`
 	Queries    []QueryResult `json:"queries,omitempty"`
+
+	// Errors are the rules that failed to evaluate, such as a builtin
+	// returning an error, when evaluation errors are reported as results.
+	Errors []Result `json:"errors,omitempty"`
+
+	// RunID identifies the invocation that produced the result, so that the
+	// results of a single run can be grouped by the systems they are sent to.
+	RunID string `json:"run_id,omitempty"`
+}
+
+// RuleResult describes the results of a single rule
+// across all of the files that were evaluated.
+type RuleResult struct {
+	Namespace  string       `json:"namespace"`
+	Rule       string       `json:"rule"`
+	Warnings   []FileResult `json:"warnings,omitempty"`
+	Failures   []FileResult `json:"failures,omitempty"`
+	Exceptions []FileResult `json:"exceptions,omitempty"`
+	Errors     []FileResult `json:"errors,omitempty"`
+	RunID      string       `json:"run_id,omitempty"`
+}
+
+// FileResult is a result along with the file that produced it.
+type FileResult struct {
+	FileName string `json:"filename"`
+	Result
+}
+
+// NamespaceResult describes the results of all of the files
+// that were evaluated against a single namespace.
+type NamespaceResult struct {
+	Namespace string        `json:"namespace"`
+	Results   []CheckResult `json:"results"`
+}
+
+// GroupResultsByRule regroups the results by the rule that produced them. The rules
+// are sorted by their namespace and name, while the results of every rule
+// keep the order in which they were found.
+func GroupResultsByRule(results []CheckResult) []RuleResult {
+	var keys []string
+	ruleResults := make(map[string]*RuleResult)
+	get := func(result CheckResult, rule string) *RuleResult {
+		key := result.Namespace + "\x00" + rule
+		if _, ok := ruleResults[key]; !ok {
+			keys = append(keys, key)
+			ruleResults[key] = &RuleResult{Namespace: result.Namespace, Rule: rule, RunID: result.RunID}
+		}
+
+		return ruleResults[key]
+	}
+
+	for _, result := range results {
+		for _, warning := range result.Warnings {
+			ruleResult := get(result, warning.Rule)
+			ruleResult.Warnings = append(ruleResult.Warnings, FileResult{FileName: result.FileName, Result: warning})
+		}
+
+		for _, failure := range result.Failures {
+			ruleResult := get(result, failure.Rule)
+			ruleResult.Failures = append(ruleResult.Failures, FileResult{FileName: result.FileName, Result: failure})
+		}
+
+		for _, exception := range result.Exceptions {
+			ruleResult := get(result, exception.Rule)
+			ruleResult.Exceptions = append(ruleResult.Exceptions, FileResult{FileName: result.FileName, Result: exception})
+		}
+
+		for _, evaluationError := range result.Errors {
+			ruleResult := get(result, evaluationError.Rule)
+			ruleResult.Errors = append(ruleResult.Errors, FileResult{FileName: result.FileName, Result: evaluationError})
+		}
+	}
+
+	sort.Strings(keys)
+
+	grouped := make([]RuleResult, 0, len(keys))
+	for _, key := range keys {
+		grouped = append(grouped, *ruleResults[key])
+	}
+
+	return grouped
+}
+
+// GroupResultsByNamespace regroups the results by the namespace that produced them.
+// The namespaces are sorted by name, while the results of every namespace keep
+// the order in which they were found.
+func GroupResultsByNamespace(results []CheckResult) []NamespaceResult {
+	var namespaces []string
+	namespaceResults := make(map[string][]CheckResult)
+	for _, result := range results {
+		if _, ok := namespaceResults[result.Namespace]; !ok {
+			namespaces = append(namespaces, result.Namespace)
+		}
+
+		namespaceResults[result.Namespace] = append(namespaceResults[result.Namespace], result)
+	}
+
+	sort.Strings(namespaces)
+
+	grouped := make([]NamespaceResult, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		grouped = append(grouped, NamespaceResult{Namespace: namespace, Results: namespaceResults[namespace]})
+	}
+
+	return grouped
+}
+
+// AddNamespaceMetadata adds the namespace that produced every warning, failure
+// and exception to the namespace key of its metadata, so that the namespace
+// is kept when the results are no longer grouped by namespace. Results that
+// already have a namespace key in their metadata are left as they are.
+func AddNamespaceMetadata(results []CheckResult) {
+	add := func(results []Result, namespace string) {
+		for r := range results {
+			if results[r].Metadata == nil {
+				results[r].Metadata = make(map[string]interface{})
+			}
+
+			if _, ok := results[r].Metadata["namespace"]; !ok {
+				results[r].Metadata["namespace"] = namespace
+			}
+		}
+	}
+
+	for _, result := range results {
+		add(result.Warnings, result.Namespace)
+		add(result.Failures, result.Namespace)
+		add(result.Exceptions, result.Namespace)
+		add(result.Errors, result.Namespace)
+	}
+}
+
+// SetRunID sets the identifier of the run that produced the results.
+func SetRunID(results []CheckResult, runID string) {
+	for r := range results {
+		results[r].RunID = runID
+	}
+}
+
+// SplitByFile regroups the results of a combined evaluation into a result per file.
+// A result is attributed to a file when its metadata contains a "file" key with the
+// path of the file. Results without a file, as well as the successes, remain part of
+// the original result.
+func SplitByFile(results []CheckResult) []CheckResult {
+	var splitResults []CheckResult
+	for _, result := range results {
+		remaining := CheckResult{
+			FileName:  result.FileName,
+			Namespace: result.Namespace,
+			Successes: result.Successes,
+			Queries:   result.Queries,
+			Errors:    result.Errors,
+		}
+
+		var files []string
+		fileResults := make(map[string]*CheckResult)
+		split := func(results []Result, field func(*CheckResult) *[]Result) {
+			for _, r := range results {
+				file, ok := r.Metadata["file"].(string)
+				if !ok || file == "" {
+					*field(&remaining) = append(*field(&remaining), r)
+					continue
+				}
+
+				if _, ok := fileResults[file]; !ok {
+					files = append(files, file)
+					fileResults[file] = &CheckResult{FileName: file, Namespace: result.Namespace}
+				}
+
+				*field(fileResults[file]) = append(*field(fileResults[file]), r)
+			}
+		}
+
+		split(result.Skipped, func(c *CheckResult) *[]Result { return &c.Skipped })
+		split(result.Warnings, func(c *CheckResult) *[]Result { return &c.Warnings })
+		split(result.Failures, func(c *CheckResult) *[]Result { return &c.Failures })
+		split(result.Exceptions, func(c *CheckResult) *[]Result { return &c.Exceptions })
+
+		hasRemaining := remaining.Successes > 0 || len(remaining.Errors) > 0 || len(remaining.Skipped) > 0 || len(remaining.Warnings) > 0 || len(remaining.Failures) > 0 || len(remaining.Exceptions) > 0
+		if hasRemaining || len(files) == 0 {
+			splitResults = append(splitResults, remaining)
+		}
+
+		for _, file := range files {
+			splitResults = append(splitResults, *fileResults[file])
+		}
+	}
+
+	return splitResults
+}
+
+// ExitCodeOptions represents the options that determine
+// which exit code should be returned.
+type ExitCodeOptions struct {
+
+	// NoFail always results in an exit code of zero. It takes precedence
+	// over all of the other options.
+	NoFail bool
+
+	// FailOnWarn considers warnings as failures. See ExitCodeFailOnWarn.
+	FailOnWarn bool
+
+	// FailOnWarnNamespaces are the namespaces whose warnings are considered
+	// as failures, including the namespaces nested in them. Unlike FailOnWarn,
+	// the warnings count as failures, e.g. towards MaxFailures.
+	FailOnWarnNamespaces []string
+
+	// MaxFailures is the number of failures that is allowed before the failures
+	// result in a non-zero exit code. See FailureCount for what is considered
+	// a failure. When zero, any failure results in a non-zero exit code.
+	MaxFailures int
+}
+
+// ExitCodeWithOptions returns the exit code that should be returned
+// given all of the returned results and the options.
+func ExitCodeWithOptions(results []CheckResult, options ExitCodeOptions) int {
+	if options.NoFail {
+		return 0
+	}
+
+	if len(options.FailOnWarnNamespaces) > 0 {
+		results = escalateWarnings(results, options.FailOnWarnNamespaces)
+	}
+
+	// Within the threshold, the failures are accepted, but warnings
+	// are still considered when failing on warnings.
+	if options.MaxFailures > 0 && FailureCount(results) <= options.MaxFailures {
+		if options.FailOnWarn && hasWarnings(results) {
+			return 1
+		}
+
+		return 0
+	}
+
+	if options.FailOnWarn {
+		return ExitCodeFailOnWarn(results)
+	}
+
+	return ExitCode(results)
+}
+
+// hasWarnings returns true if any of the results contains a warning.
+func hasWarnings(results []CheckResult) bool {
+	for _, result := range results {
+		if len(result.Warnings) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// escalateWarnings returns a copy of the results in which the warnings of the
+// given namespaces are failures. The namespace of a warning is the namespace in
+// its metadata, as added by AddNamespaceMetadata, or else the namespace of its
+// result.
+func escalateWarnings(results []CheckResult, namespaces []string) []CheckResult {
+	escalated := make([]CheckResult, len(results))
+	for i, result := range results {
+		escalated[i] = result
+		escalated[i].Warnings = nil
+		escalated[i].Failures = append([]Result{}, result.Failures...)
+
+		for _, warning := range result.Warnings {
+			namespace, ok := warning.Metadata["namespace"].(string)
+			if !ok {
+				namespace = result.Namespace
+			}
+
+			if inNamespaces(namespace, namespaces) {
+				escalated[i].Failures = append(escalated[i].Failures, warning)
+				continue
+			}
+
+			escalated[i].Warnings = append(escalated[i].Warnings, warning)
+		}
+	}
+
+	return escalated
+}
+
+// inNamespaces returns true if the namespace is one of the namespaces,
+// or is nested in one of them, e.g. security.images in security.
+func inNamespaces(namespace string, namespaces []string) bool {
+	for _, n := range namespaces {
+		if namespace == n || strings.HasPrefix(namespace, n+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TestCount returns the total number of tests in the given results, which is
+// the number of successes, warnings, failures, exceptions and errors. Skipped
+// tests are not counted, as they were not evaluated.
+func TestCount(results []CheckResult) int {
+	var count int
+	for _, result := range results {
+		count += result.Successes + len(result.Warnings) + len(result.Failures) + len(result.Exceptions) + len(result.Errors)
+	}
+
+	return count
+}
+
+// FailureCount returns the total number of failures in the given results.
+// Errors are counted as failures.
+func FailureCount(results []CheckResult) int {
+	var count int
+	for _, result := range results {
+		count += len(result.Failures) + len(result.Errors)
+	}
+
+	return count
 }
 
 // ExitCode returns the exit code that should be returned
-// given all of the returned results.
+// given all of the returned results. Errors are considered
+// as failures.
 func ExitCode(results []CheckResult) int {
 	var hasFailure bool
 	for _, result := range results {
-		if len(result.Failures) > 0 {
+		if len(result.Failures) > 0 || len(result.Errors) > 0 {
 			hasFailure = true
 		}
 	}
@@ -104,7 +453,7 @@ func ExitCodeFailOnWarn(results []CheckResult) int {
 	var hasFailure bool
 	var hasWarning bool
 	for _, result := range results {
-		if len(result.Failures) > 0 {
+		if len(result.Failures) > 0 || len(result.Errors) > 0 {
 			hasFailure = true
 		}
 
@@ -1,6 +1,7 @@
 package output
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -17,6 +18,10 @@ func TestExitCode(t *testing.T) {
 		Skipped: []Result{{}},
 	}
 
+	evaluationError := CheckResult{
+		Errors: []Result{{}},
+	}
+
 	testCases := []struct {
 		results  []CheckResult
 		expected int
@@ -26,6 +31,7 @@ func TestExitCode(t *testing.T) {
 		{results: []CheckResult{skipped}, expected: 0},
 		{results: []CheckResult{failure}, expected: 1},
 		{results: []CheckResult{warning, failure}, expected: 1},
+		{results: []CheckResult{evaluationError}, expected: 1},
 	}
 
 	for _, testCase := range testCases {
@@ -64,3 +70,210 @@ func TestExitCodeFailOnWarn(t *testing.T) {
 		}
 	}
 }
+
+func TestTestCount(t *testing.T) {
+	testCases := []struct {
+		name     string
+		results  []CheckResult
+		expected int
+	}{
+		{name: "no results", results: []CheckResult{}, expected: 0},
+		{name: "file without tests", results: []CheckResult{{FileName: "deployment.yaml"}}, expected: 0},
+		{name: "skipped", results: []CheckResult{{Skipped: []Result{{}}}}, expected: 0},
+		{
+			name: "all types",
+			results: []CheckResult{
+				{Successes: 2, Warnings: []Result{{}}, Failures: []Result{{}}},
+				{Exceptions: []Result{{}}, Errors: []Result{{}}},
+			},
+			expected: 6,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := TestCount(testCase.results)
+			if actual != testCase.expected {
+				t.Errorf("Unexpected test count. expected %v, actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestSplitByFile(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "Combined",
+			Namespace: "main",
+			Successes: 2,
+			Warnings: []Result{
+				{Message: "first warning", Metadata: map[string]interface{}{"file": "service.yaml"}},
+			},
+			Failures: []Result{
+				{Message: "first failure", Metadata: map[string]interface{}{"file": "deployment.yaml"}},
+				{Message: "second failure"},
+				{Message: "third failure", Metadata: map[string]interface{}{"file": "service.yaml"}},
+			},
+		},
+	}
+
+	expected := []CheckResult{
+		{
+			FileName:  "Combined",
+			Namespace: "main",
+			Successes: 2,
+			Failures:  []Result{{Message: "second failure"}},
+		},
+		{
+			FileName:  "service.yaml",
+			Namespace: "main",
+			Warnings: []Result{
+				{Message: "first warning", Metadata: map[string]interface{}{"file": "service.yaml"}},
+			},
+			Failures: []Result{
+				{Message: "third failure", Metadata: map[string]interface{}{"file": "service.yaml"}},
+			},
+		},
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "first failure", Metadata: map[string]interface{}{"file": "deployment.yaml"}},
+			},
+		},
+	}
+
+	actual := SplitByFile(input)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected results. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestAddNamespaceMetadata(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "team.a",
+			Warnings:  []Result{{Message: "first warning"}},
+			Failures:  []Result{{Message: "first failure", Metadata: map[string]interface{}{"namespace": "custom"}}},
+		},
+		{
+			FileName:   "deployment.yaml",
+			Namespace:  "team.b",
+			Failures:   []Result{{Message: "second failure", Metadata: map[string]interface{}{"severity": "high"}}},
+			Exceptions: []Result{{Message: "first exception"}},
+			Errors:     []Result{{Message: "first error"}},
+		},
+	}
+
+	expected := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "team.a",
+			Warnings:  []Result{{Message: "first warning", Metadata: map[string]interface{}{"namespace": "team.a"}}},
+			Failures:  []Result{{Message: "first failure", Metadata: map[string]interface{}{"namespace": "custom"}}},
+		},
+		{
+			FileName:   "deployment.yaml",
+			Namespace:  "team.b",
+			Failures:   []Result{{Message: "second failure", Metadata: map[string]interface{}{"severity": "high", "namespace": "team.b"}}},
+			Exceptions: []Result{{Message: "first exception", Metadata: map[string]interface{}{"namespace": "team.b"}}},
+			Errors:     []Result{{Message: "first error", Metadata: map[string]interface{}{"namespace": "team.b"}}},
+		},
+	}
+
+	AddNamespaceMetadata(input)
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected results. expected %v actual %v", expected, input)
+	}
+}
+
+func TestExitCodeWithOptions(t *testing.T) {
+	warning := CheckResult{
+		Warnings: []Result{{}},
+	}
+
+	failure := CheckResult{
+		Failures: []Result{{}},
+	}
+
+	testCases := []struct {
+		name     string
+		results  []CheckResult
+		options  ExitCodeOptions
+		expected int
+	}{
+		{name: "no results", results: []CheckResult{}, options: ExitCodeOptions{}, expected: 0},
+		{name: "warning", results: []CheckResult{warning}, options: ExitCodeOptions{}, expected: 0},
+		{name: "failure", results: []CheckResult{failure}, options: ExitCodeOptions{}, expected: 1},
+		{name: "warning with fail-on-warn", results: []CheckResult{warning}, options: ExitCodeOptions{FailOnWarn: true}, expected: 1},
+		{name: "failure with fail-on-warn", results: []CheckResult{warning, failure}, options: ExitCodeOptions{FailOnWarn: true}, expected: 2},
+		{name: "failure with no-fail", results: []CheckResult{failure}, options: ExitCodeOptions{NoFail: true}, expected: 0},
+		{name: "warning with no-fail and fail-on-warn", results: []CheckResult{warning}, options: ExitCodeOptions{NoFail: true, FailOnWarn: true}, expected: 0},
+		{name: "failure with no-fail and fail-on-warn", results: []CheckResult{failure}, options: ExitCodeOptions{NoFail: true, FailOnWarn: true}, expected: 0},
+		{name: "failures within max-failures", results: []CheckResult{failure, failure}, options: ExitCodeOptions{MaxFailures: 2}, expected: 0},
+		{name: "failures above max-failures", results: []CheckResult{failure, failure, failure}, options: ExitCodeOptions{MaxFailures: 2}, expected: 1},
+		{name: "warning within max-failures with fail-on-warn", results: []CheckResult{warning, failure}, options: ExitCodeOptions{MaxFailures: 1, FailOnWarn: true}, expected: 1},
+		{name: "failures within max-failures with fail-on-warn and no warnings", results: []CheckResult{failure, failure, failure}, options: ExitCodeOptions{MaxFailures: 5, FailOnWarn: true}, expected: 0},
+		{name: "failures above max-failures with fail-on-warn", results: []CheckResult{warning, failure, failure}, options: ExitCodeOptions{MaxFailures: 1, FailOnWarn: true}, expected: 2},
+		{name: "failures above max-failures with no-fail", results: []CheckResult{failure, failure}, options: ExitCodeOptions{MaxFailures: 1, NoFail: true}, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := ExitCodeWithOptions(testCase.results, testCase.options)
+
+			if actual != testCase.expected {
+				t.Errorf("Unexpected error code. expected %v, actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestExitCodeFailOnWarnNamespaces(t *testing.T) {
+	securityWarning := CheckResult{Namespace: "security", Warnings: []Result{{Message: "image is not pinned"}}}
+	nestedWarning := CheckResult{Namespace: "security.images", Warnings: []Result{{Message: "image is not signed"}}}
+	styleWarning := CheckResult{Namespace: "style", Warnings: []Result{{Message: "labels are not sorted"}}}
+	styleFailure := CheckResult{Namespace: "style", Failures: []Result{{Message: "name is too long"}}}
+	combinedWarnings := CheckResult{
+		Namespace: "main",
+		Warnings: []Result{
+			{Message: "image is not pinned", Metadata: map[string]interface{}{"namespace": "security"}},
+			{Message: "labels are not sorted", Metadata: map[string]interface{}{"namespace": "style"}},
+		},
+	}
+
+	namespaces := []string{"security"}
+	testCases := []struct {
+		name     string
+		results  []CheckResult
+		options  ExitCodeOptions
+		expected int
+	}{
+		{name: "warning of an escalated namespace", results: []CheckResult{securityWarning, styleWarning}, options: ExitCodeOptions{FailOnWarnNamespaces: namespaces}, expected: 1},
+		{name: "warning of a nested namespace", results: []CheckResult{nestedWarning}, options: ExitCodeOptions{FailOnWarnNamespaces: namespaces}, expected: 1},
+		{name: "warning of another namespace", results: []CheckResult{styleWarning}, options: ExitCodeOptions{FailOnWarnNamespaces: namespaces}, expected: 0},
+		{name: "namespace prefix is not a parent", results: []CheckResult{{Namespace: "securityx", Warnings: []Result{{}}}}, options: ExitCodeOptions{FailOnWarnNamespaces: namespaces}, expected: 0},
+		{name: "namespace in metadata", results: []CheckResult{combinedWarnings}, options: ExitCodeOptions{FailOnWarnNamespaces: []string{"style"}}, expected: 1},
+		{name: "namespace in metadata of another namespace", results: []CheckResult{combinedWarnings}, options: ExitCodeOptions{FailOnWarnNamespaces: []string{"main"}}, expected: 0},
+		{name: "escalated warning with fail-on-warn", results: []CheckResult{securityWarning, styleWarning}, options: ExitCodeOptions{FailOnWarn: true, FailOnWarnNamespaces: namespaces}, expected: 2},
+		{name: "other warning with fail-on-warn", results: []CheckResult{styleWarning}, options: ExitCodeOptions{FailOnWarn: true, FailOnWarnNamespaces: namespaces}, expected: 1},
+		{name: "escalated warning counts towards max-failures", results: []CheckResult{securityWarning, styleFailure}, options: ExitCodeOptions{MaxFailures: 1, FailOnWarnNamespaces: namespaces}, expected: 1},
+		{name: "escalated warning within max-failures", results: []CheckResult{securityWarning, styleWarning}, options: ExitCodeOptions{MaxFailures: 1, FailOnWarnNamespaces: namespaces}, expected: 0},
+		{name: "escalated warning with no-fail", results: []CheckResult{securityWarning}, options: ExitCodeOptions{NoFail: true, FailOnWarnNamespaces: namespaces}, expected: 0},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := ExitCodeWithOptions(testCase.results, testCase.options)
+
+			if actual != testCase.expected {
+				t.Errorf("Unexpected error code. expected %v, actual %v", testCase.expected, actual)
+			}
+		})
+	}
+
+	if len(securityWarning.Warnings) != 1 || len(securityWarning.Failures) != 0 {
+		t.Errorf("the results should not be modified: %v", securityWarning)
+	}
+}
@@ -0,0 +1,220 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+const (
+	sarifSchema         = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+	sarifVersion        = "2.1.0"
+	sarifToolName       = "conftest"
+	sarifInformationURI = "https://github.com/open-policy-agent/conftest"
+)
+
+// guidRegex matches the GUIDs that SARIF accepts as the guid of a run.
+var guidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// SARIF represents an Outputter that outputs
+// results in the SARIF format.
+type SARIF struct {
+	Writer io.Writer
+}
+
+type sarifReport struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool               `json:"tool"`
+	AutomationDetails *sarifAutomationDetails `json:"automationDetails,omitempty"`
+	Results           []sarifResult           `json:"results"`
+	Properties        map[string]interface{}  `json:"properties,omitempty"`
+}
+
+// sarifAutomationDetails identifies the run. Only the guid is set, as the
+// id is used as the category of the analysis by tools such as GitHub code
+// scanning, and a new category for every run would never close any alert.
+type sarifAutomationDetails struct {
+	GUID string `json:"guid"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+// sarifSuppression marks a result as suppressed, which is how exceptions
+// are written, as the rule did match but was excepted by the policy.
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+// NewSARIF creates a new SARIF with the given writer.
+func NewSARIF(w io.Writer) *SARIF {
+	sarif := SARIF{
+		Writer: w,
+	}
+
+	return &sarif
+}
+
+// Output outputs the results.
+func (s *SARIF) Output(checkResults []CheckResult) error {
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           sarifToolName,
+				InformationURI: sarifInformationURI,
+				Rules:          []sarifRule{},
+			},
+		},
+		Results: []sarifResult{},
+	}
+
+	// Every result must reference a rule that is defined in the rules section
+	// of the driver, so the rules are collected as they are encountered.
+	ruleIndexes := make(map[string]int)
+	addResult := func(checkResult CheckResult, result Result, level string, suppressed bool) {
+		ruleID := getRuleID(checkResult.Namespace, result.Rule)
+
+		ruleIndex, ok := ruleIndexes[ruleID]
+		if !ok {
+			ruleIndex = len(run.Tool.Driver.Rules)
+			ruleIndexes[ruleID] = ruleIndex
+
+			rule := sarifRule{
+				ID:               ruleID,
+				ShortDescription: sarifMessage{Text: ruleID},
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+
+		// When the rule is documented in the policy, its description is used
+		// as the description of the rule rather than the rule identifier.
+		if result.Description != "" {
+			run.Tool.Driver.Rules[ruleIndex].ShortDescription.Text = result.Description
+		}
+
+		sarifResult := sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: ruleIndex,
+			Level:     level,
+			Message:   sarifMessage{Text: result.Message},
+		}
+
+		if checkResult.FileName != "-" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: checkResult.FileName},
+				},
+			}
+			sarifResult.Locations = []sarifLocation{location}
+		}
+
+		if suppressed {
+			sarifResult.Suppressions = []sarifSuppression{{Kind: "inSource"}}
+		}
+
+		run.Results = append(run.Results, sarifResult)
+	}
+
+	for _, checkResult := range checkResults {
+		if checkResult.RunID != "" && run.Properties == nil {
+			run.Properties = map[string]interface{}{"run_id": checkResult.RunID}
+			if guidRegex.MatchString(checkResult.RunID) {
+				run.AutomationDetails = &sarifAutomationDetails{GUID: checkResult.RunID}
+			}
+		}
+
+		for _, failure := range checkResult.Failures {
+			addResult(checkResult, failure, "error", false)
+		}
+
+		for _, warning := range checkResult.Warnings {
+			addResult(checkResult, warning, "warning", false)
+		}
+
+		// Exceptions keep the level of the rule that they except.
+		for _, exception := range checkResult.Exceptions {
+			level := "error"
+			if strings.HasPrefix(exception.Rule, "warn") {
+				level = "warning"
+			}
+
+			addResult(checkResult, exception, level, true)
+		}
+
+		// Rules that failed to evaluate are errors, as it is not known
+		// whether the file passes them.
+		for _, evaluationError := range checkResult.Errors {
+			addResult(checkResult, evaluationError, "error", false)
+		}
+	}
+
+	report := sarifReport{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+
+	b, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return fmt.Errorf("marshal sarif: %w", err)
+	}
+
+	fmt.Fprintln(s.Writer, string(b))
+	return nil
+}
+
+func getRuleID(namespace string, rule string) string {
+	if rule == "" {
+		return namespace
+	}
+
+	if namespace == "" {
+		return rule
+	}
+
+	return namespace + "." + rule
+}
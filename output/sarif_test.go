@@ -0,0 +1,129 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestSARIF(t *testing.T) {
+	input := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "first failure", Rule: "deny"},
+				{Message: "second failure", Rule: "deny_root", Description: "Containers must not run as root"},
+			},
+			Warnings: []Result{{Message: "first warning", Rule: "warn"}},
+		},
+		{
+			FileName:  "examples/kubernetes/service.yaml",
+			Namespace: "main",
+			Failures:  []Result{{Message: "third failure", Rule: "deny"}},
+		},
+		{
+			FileName:   "examples/kubernetes/pod.yaml",
+			Namespace:  "main",
+			Exceptions: []Result{{Message: `data.main.exception[_][_] == "warn"`, Rule: "warn"}},
+			Errors:     []Result{{Message: "json.unmarshal: invalid character", Rule: "deny_invalid"}},
+		},
+	}
+
+	buf := new(bytes.Buffer)
+	if err := NewSARIF(buf).Output(input); err != nil {
+		t.Fatal("output sarif:", err)
+	}
+
+	var report sarifReport
+	if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+		t.Fatal("unmarshal sarif:", err)
+	}
+
+	if report.Version != sarifVersion {
+		t.Errorf("Unexpected version. expected %v actual %v", sarifVersion, report.Version)
+	}
+
+	rules := report.Runs[0].Tool.Driver.Rules
+	expectedRules := []sarifRule{
+		{ID: "main.deny", ShortDescription: sarifMessage{Text: "main.deny"}},
+		{ID: "main.deny_root", ShortDescription: sarifMessage{Text: "Containers must not run as root"}},
+		{ID: "main.warn", ShortDescription: sarifMessage{Text: "main.warn"}},
+		{ID: "main.deny_invalid", ShortDescription: sarifMessage{Text: "main.deny_invalid"}},
+	}
+	if len(rules) != len(expectedRules) {
+		t.Fatalf("Unexpected rules. expected %v actual %v", expectedRules, rules)
+	}
+	for i := range expectedRules {
+		if rules[i] != expectedRules[i] {
+			t.Errorf("Unexpected rule. expected %v actual %v", expectedRules[i], rules[i])
+		}
+	}
+
+	results := report.Runs[0].Results
+	if len(results) != 6 {
+		t.Fatalf("Unexpected number of results. expected 6 actual %v", len(results))
+	}
+
+	for _, result := range results {
+		if rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("Result rule id %v does not match rule %v", result.RuleID, rules[result.RuleIndex].ID)
+		}
+	}
+
+	if results[2].Level != "warning" {
+		t.Errorf("Unexpected level. expected warning actual %v", results[2].Level)
+	}
+
+	if results[3].Locations[0].PhysicalLocation.ArtifactLocation.URI != "examples/kubernetes/service.yaml" {
+		t.Errorf("Unexpected location. expected examples/kubernetes/service.yaml actual %v", results[3].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	}
+
+	if results[4].Level != "warning" || len(results[4].Suppressions) != 1 || results[4].Suppressions[0].Kind != "inSource" {
+		t.Errorf("Unexpected exception. expected a suppressed warning actual %v", results[4])
+	}
+
+	if results[5].Level != "error" || results[5].Message.Text != "json.unmarshal: invalid character" || len(results[5].Suppressions) != 0 {
+		t.Errorf("Unexpected error. expected an error result actual %v", results[5])
+	}
+}
+
+func TestSARIFRunID(t *testing.T) {
+	testCases := []struct {
+		runID        string
+		expectedGUID string
+	}{
+		{runID: "4f9a1c62-8e3b-4d7a-9c25-1b6e0f3d8a47", expectedGUID: "4f9a1c62-8e3b-4d7a-9c25-1b6e0f3d8a47"},
+		{runID: "pipeline-1234", expectedGUID: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.runID, func(t *testing.T) {
+			input := []CheckResult{{FileName: "deployment.yaml", Namespace: "main", RunID: testCase.runID}}
+
+			buf := new(bytes.Buffer)
+			if err := NewSARIF(buf).Output(input); err != nil {
+				t.Fatal("output sarif:", err)
+			}
+
+			var report sarifReport
+			if err := json.Unmarshal(buf.Bytes(), &report); err != nil {
+				t.Fatal("unmarshal sarif:", err)
+			}
+
+			run := report.Runs[0]
+			if run.Properties["run_id"] != testCase.runID {
+				t.Errorf("Unexpected run id. expected %v actual %v", testCase.runID, run.Properties["run_id"])
+			}
+
+			var guid string
+			if run.AutomationDetails != nil {
+				guid = run.AutomationDetails.GUID
+			}
+
+			if guid != testCase.expectedGUID {
+				t.Errorf("Unexpected guid. expected %q actual %q", testCase.expectedGUID, guid)
+			}
+		})
+	}
+}
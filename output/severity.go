@@ -0,0 +1,79 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The defined severities represent the levels, from lowest to highest,
+// that can be set in the severity field of the metadata of a result.
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Severities returns the available severities, from lowest to highest.
+func Severities() []string {
+	return []string{
+		SeverityLow,
+		SeverityMedium,
+		SeverityHigh,
+		SeverityCritical,
+	}
+}
+
+// FilterBySeverity removes the warnings, failures and exceptions whose severity is
+// lower than the given minimum severity. The severity of a result is read from the
+// severity field of its metadata, and results without a known severity are
+// considered to have the default severity. Successes and errors are never removed.
+func FilterBySeverity(results []CheckResult, minimum string, defaultSeverity string) ([]CheckResult, error) {
+	minimumLevel, ok := severityLevel(minimum)
+	if !ok {
+		return nil, fmt.Errorf("unknown severity: %v", minimum)
+	}
+
+	defaultLevel, ok := severityLevel(defaultSeverity)
+	if !ok {
+		return nil, fmt.Errorf("unknown default severity: %v", defaultSeverity)
+	}
+
+	filter := func(results []Result) []Result {
+		var filtered []Result
+		for _, result := range results {
+			severity, _ := result.Metadata["severity"].(string)
+			level, ok := severityLevel(severity)
+			if !ok {
+				level = defaultLevel
+			}
+
+			if level >= minimumLevel {
+				filtered = append(filtered, result)
+			}
+		}
+
+		return filtered
+	}
+
+	filteredResults := make([]CheckResult, 0, len(results))
+	for _, result := range results {
+		result.Warnings = filter(result.Warnings)
+		result.Failures = filter(result.Failures)
+		result.Exceptions = filter(result.Exceptions)
+
+		filteredResults = append(filteredResults, result)
+	}
+
+	return filteredResults, nil
+}
+
+func severityLevel(severity string) (int, bool) {
+	for level, s := range Severities() {
+		if strings.EqualFold(s, severity) {
+			return level, true
+		}
+	}
+
+	return 0, false
+}
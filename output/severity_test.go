@@ -0,0 +1,87 @@
+package output
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterBySeverity(t *testing.T) {
+	low := Result{Message: "low", Metadata: map[string]interface{}{"severity": "low"}}
+	high := Result{Message: "high", Metadata: map[string]interface{}{"severity": "HIGH"}}
+	critical := Result{Message: "critical", Metadata: map[string]interface{}{"severity": "critical"}}
+	unknown := Result{Message: "unknown"}
+
+	results := []CheckResult{
+		{
+			FileName:  "examples/kubernetes/deployment.yaml",
+			Successes: 2,
+			Warnings:  []Result{low, unknown},
+			Failures:  []Result{high, critical, unknown},
+			Errors:    []Result{{Message: "error"}},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		minimum         string
+		defaultSeverity string
+		expected        []CheckResult
+	}{
+		{
+			name:            "keeps results at or above the minimum",
+			minimum:         SeverityHigh,
+			defaultSeverity: SeverityLow,
+			expected: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Successes: 2,
+					Failures:  []Result{high, critical},
+					Errors:    []Result{{Message: "error"}},
+				},
+			},
+		},
+		{
+			name:            "uses the default severity for results without a severity",
+			minimum:         SeverityHigh,
+			defaultSeverity: SeverityCritical,
+			expected: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Successes: 2,
+					Warnings:  []Result{unknown},
+					Failures:  []Result{high, critical, unknown},
+					Errors:    []Result{{Message: "error"}},
+				},
+			},
+		},
+		{
+			name:            "keeps all results at the lowest severity",
+			minimum:         SeverityLow,
+			defaultSeverity: SeverityLow,
+			expected:        results,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := FilterBySeverity(results, tt.minimum, tt.defaultSeverity)
+			if err != nil {
+				t.Fatal("filter by severity:", err)
+			}
+
+			if !reflect.DeepEqual(actual, tt.expected) {
+				t.Errorf("Unexpected results. expected %v actual %v", tt.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFilterBySeverityUnknown(t *testing.T) {
+	if _, err := FilterBySeverity(nil, "urgent", SeverityLow); err == nil {
+		t.Error("expected an error for an unknown minimum severity")
+	}
+
+	if _, err := FilterBySeverity(nil, SeverityLow, "urgent"); err == nil {
+		t.Error("expected an error for an unknown default severity")
+	}
+}
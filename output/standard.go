@@ -26,6 +26,10 @@ type Standard struct {
 	// ShowSkipped whether to show skipped tests
 	// in the output.
 	ShowSkipped bool
+
+	// GroupBy controls whether the results are grouped by
+	// file (the default), by rule or by namespace.
+	GroupBy string
 }
 
 // NewStandard creates a new Standard with the given writer.
@@ -54,49 +58,38 @@ func (s *Standard) Output(results []CheckResult) error {
 	var totalWarnings int
 	var totalSuccesses int
 	var totalSkipped int
+	var totalErrors int
 	for _, result := range results {
-		var indicator string
-		var namespace string
-		if result.FileName == "-" {
-			indicator = "-"
-		} else {
-			indicator = fmt.Sprintf("- %s", result.FileName)
-		}
-
-		if result.Namespace == "-" {
-			namespace = "-"
-		} else {
-			namespace = fmt.Sprintf("- %s -", result.Namespace)
-		}
-
-		totalPolicies := result.Successes + len(result.Warnings) + len(result.Failures) + len(result.Exceptions) + len(result.Skipped)
+		totalPolicies := result.Successes + len(result.Warnings) + len(result.Failures) + len(result.Exceptions) + len(result.Skipped) + len(result.Errors)
 		if totalPolicies == 0 {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("?", aurora.WhiteFg), indicator, namespace, "no policies found")
+			fmt.Fprintln(s.Writer, colorizer.Colorize("?", aurora.WhiteFg), fileNameIndicator(result.FileName), namespaceIndicator(result.Namespace), "no policies found")
 			continue
 		}
 
-		for _, warning := range result.Warnings {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("WARN", aurora.YellowFg), indicator, namespace, warning.Message)
-		}
-
-		for _, failure := range result.Failures {
-			fmt.Fprintln(s.Writer, colorizer.Colorize("FAIL", aurora.RedFg), indicator, namespace, failure.Message)
-		}
-
-		if !s.SuppressExceptions {
-			for _, exception := range result.Exceptions {
-				fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), indicator, namespace, exception.Message)
-			}
-		}
-
 		totalFailures += len(result.Failures)
 		totalExceptions += len(result.Exceptions)
 		totalWarnings += len(result.Warnings)
 		totalSkipped += len(result.Skipped)
+		totalErrors += len(result.Errors)
 		totalSuccesses += result.Successes
+
+		// When grouping by rule or namespace, the results are written
+		// after all of the results have been counted.
+		if s.GroupBy == GroupByRule || s.GroupBy == GroupByNamespace {
+			continue
+		}
+
+		s.outputResult(result, namespaceIndicator(result.Namespace), colorizer)
 	}
 
-	totalTests := totalFailures + totalExceptions + totalWarnings + totalSuccesses + totalSkipped
+	switch s.GroupBy {
+	case GroupByRule:
+		s.outputByRule(results, colorizer)
+	case GroupByNamespace:
+		s.outputByNamespace(results, colorizer)
+	}
+
+	totalTests := totalFailures + totalExceptions + totalWarnings + totalSuccesses + totalSkipped + totalErrors
 
 	var pluralSuffixTests string
 	if totalTests != 1 {
@@ -130,8 +123,19 @@ func (s *Standard) Output(results []CheckResult) error {
 		outputText += fmt.Sprintf(", %v skipped", totalSkipped)
 	}
 
+	// Errors are only reported when evaluation errors are reported
+	// as results, so they are only part of the summary when present.
+	if totalErrors > 0 {
+		var pluralSuffixErrors string
+		if totalErrors != 1 {
+			pluralSuffixErrors = "s"
+		}
+
+		outputText += fmt.Sprintf(", %v error%s", totalErrors, pluralSuffixErrors)
+	}
+
 	var outputColor aurora.Color
-	if totalFailures > 0 {
+	if totalFailures > 0 || totalErrors > 0 {
 		outputColor = aurora.RedFg
 	} else if totalWarnings > 0 {
 		outputColor = aurora.YellowFg
@@ -146,6 +150,124 @@ func (s *Standard) Output(results []CheckResult) error {
 	return nil
 }
 
+// outputResult writes the warnings, failures, errors and exceptions of the given
+// result, labelled with its file name, including the index of the document for
+// multi-document files, and the given namespace label.
+func (s *Standard) outputResult(result CheckResult, namespace string, colorizer aurora.Aurora) {
+	file := func(r Result) string {
+		return fileNameIndicator(documentFileName(result.FileName, r))
+	}
+
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(s.Writer, colorizer.Colorize("WARN", aurora.YellowFg), file(warning), namespace, warning.Message)
+		s.outputRemediation(warning)
+	}
+
+	for _, failure := range result.Failures {
+		fmt.Fprintln(s.Writer, colorizer.Colorize("FAIL", aurora.RedFg), file(failure), namespace, failure.Message)
+		s.outputRemediation(failure)
+	}
+
+	for _, evaluationError := range result.Errors {
+		fmt.Fprintln(s.Writer, colorizer.Colorize("ERROR", aurora.MagentaFg), file(evaluationError), namespace, evaluationError.Rule+":", evaluationError.Message)
+	}
+
+	if !s.SuppressExceptions {
+		for _, exception := range result.Exceptions {
+			fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), file(exception), namespace, exception.Message)
+		}
+	}
+}
+
+// outputByNamespace writes the results under the namespace that produced
+// them. The namespace is written once as a heading, so it is left out of
+// the lines of the results.
+func (s *Standard) outputByNamespace(results []CheckResult, colorizer aurora.Aurora) {
+	for _, namespaceResult := range GroupResultsByNamespace(results) {
+		var hasResults bool
+		for _, result := range namespaceResult.Results {
+			if len(result.Warnings) > 0 || len(result.Failures) > 0 || len(result.Errors) > 0 || (!s.SuppressExceptions && len(result.Exceptions) > 0) {
+				hasResults = true
+			}
+		}
+
+		if !hasResults {
+			continue
+		}
+
+		fmt.Fprintln(s.Writer, namespaceResult.Namespace)
+		for _, result := range namespaceResult.Results {
+			s.outputResult(result, "-", colorizer)
+		}
+	}
+}
+
+func (s *Standard) outputByRule(results []CheckResult, colorizer aurora.Aurora) {
+	for _, ruleResult := range GroupResultsByRule(results) {
+		if len(ruleResult.Warnings) == 0 && len(ruleResult.Failures) == 0 && len(ruleResult.Errors) == 0 && (s.SuppressExceptions || len(ruleResult.Exceptions) == 0) {
+			continue
+		}
+
+		name := ruleResult.Namespace
+		if ruleResult.Rule != "" {
+			name += "." + ruleResult.Rule
+		}
+		fmt.Fprintln(s.Writer, name)
+
+		for _, warning := range ruleResult.Warnings {
+			fmt.Fprintln(s.Writer, colorizer.Colorize("WARN", aurora.YellowFg), fileIndicator(documentFileName(warning.FileName, warning.Result)), warning.Message)
+			s.outputRemediation(warning.Result)
+		}
+
+		for _, failure := range ruleResult.Failures {
+			fmt.Fprintln(s.Writer, colorizer.Colorize("FAIL", aurora.RedFg), fileIndicator(documentFileName(failure.FileName, failure.Result)), failure.Message)
+			s.outputRemediation(failure.Result)
+		}
+
+		for _, evaluationError := range ruleResult.Errors {
+			fmt.Fprintln(s.Writer, colorizer.Colorize("ERROR", aurora.MagentaFg), fileIndicator(documentFileName(evaluationError.FileName, evaluationError.Result)), evaluationError.Message)
+		}
+
+		if !s.SuppressExceptions {
+			for _, exception := range ruleResult.Exceptions {
+				fmt.Fprintln(s.Writer, colorizer.Colorize("EXCP", aurora.CyanFg), fileIndicator(documentFileName(exception.FileName, exception.Result)), exception.Message)
+			}
+		}
+	}
+}
+
+// outputRemediation writes the remediation of the given result, if it has
+// one, indented under the message of the result.
+func (s *Standard) outputRemediation(result Result) {
+	if remediation, ok := formatRemediation(result); ok {
+		fmt.Fprintln(s.Writer, "  remediation:", remediation)
+	}
+}
+
+func fileNameIndicator(fileName string) string {
+	if fileName == "-" {
+		return "-"
+	}
+
+	return fmt.Sprintf("- %s", fileName)
+}
+
+func namespaceIndicator(namespace string) string {
+	if namespace == "-" {
+		return "-"
+	}
+
+	return fmt.Sprintf("- %s -", namespace)
+}
+
+func fileIndicator(fileName string) string {
+	if fileName == "-" {
+		return "-"
+	}
+
+	return fmt.Sprintf("- %s -", fileName)
+}
+
 func (s *Standard) outputTrace(results []CheckResult, colorizer aurora.Aurora) {
 	for _, result := range results {
 		for _, query := range result.Queries {
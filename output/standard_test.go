@@ -8,11 +8,14 @@ import (
 )
 
 func TestStandard(t *testing.T) {
+	firstDocument, secondDocument := 0, 1
+
 	tests := []struct {
 		name        string
 		input       []CheckResult
 		expected    []string
 		showSkipped bool
+		groupBy     string
 	}{
 		{
 			name: "records failures, warnings and skipped",
@@ -32,6 +35,25 @@ func TestStandard(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "records the document of results from multi-document files",
+			input: []CheckResult{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "namespace",
+					Warnings:  []Result{{Message: "first warning", Document: &firstDocument}},
+					Failures:  []Result{{Message: "first failure", Document: &secondDocument}, {Message: "second failure"}},
+				},
+			},
+			expected: []string{
+				"WARN - foo.yaml[0] - namespace - first warning",
+				"FAIL - foo.yaml[1] - namespace - first failure",
+				"FAIL - foo.yaml - namespace - second failure",
+				"",
+				"3 tests, 0 passed, 1 warning, 2 failures, 0 exceptions",
+				"",
+			},
+		},
 		{
 			name: "skips filenames for stdin",
 			input: []CheckResult{
@@ -50,6 +72,45 @@ func TestStandard(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "records remediations under the message",
+			input: []CheckResult{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "namespace",
+					Warnings:  []Result{{Message: "first warning", Metadata: map[string]interface{}{"remediation": "set the replicas to 3"}}},
+					Failures: []Result{{Message: "first failure", Metadata: map[string]interface{}{
+						"remediation": map[string]interface{}{"op": "replace", "path": "/image", "value": "nginx:1.25"},
+					}}},
+				},
+			},
+			expected: []string{
+				"WARN - foo.yaml - namespace - first warning",
+				"  remediation: set the replicas to 3",
+				"FAIL - foo.yaml - namespace - first failure",
+				`  remediation: {"op":"replace","path":"/image","value":"nginx:1.25"}`,
+				"",
+				"2 tests, 0 passed, 1 warning, 1 failure, 0 exceptions",
+				"",
+			},
+		},
+		{
+			name: "records evaluation errors",
+			input: []CheckResult{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "namespace",
+					Successes: 1,
+					Errors:    []Result{{Message: "json.unmarshal: invalid character", Rule: "deny"}},
+				},
+			},
+			expected: []string{
+				"ERROR - foo.yaml - namespace - deny: json.unmarshal: invalid character",
+				"",
+				"2 tests, 1 passed, 0 warnings, 0 failures, 0 exceptions, 1 error",
+				"",
+			},
+		},
 		{
 			name: "records failures, warnings and skipped",
 			input: []CheckResult{
@@ -89,6 +150,77 @@ func TestStandard(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "groups results by rule",
+			input: []CheckResult{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "namespace",
+					Warnings:  []Result{{Message: "first warning", Rule: "warn"}},
+					Failures:  []Result{{Message: "first failure", Rule: "deny"}},
+				},
+				{
+					FileName:  "bar.yaml",
+					Namespace: "namespace",
+					Successes: 1,
+					Failures:  []Result{{Message: "second failure", Rule: "deny"}},
+				},
+				{
+					FileName:  "baz.yaml",
+					Namespace: "namespace",
+					Errors:    []Result{{Message: "json.unmarshal: invalid character", Rule: "deny_invalid"}},
+				},
+			},
+			groupBy: GroupByRule,
+			expected: []string{
+				"namespace.deny",
+				"FAIL - foo.yaml - first failure",
+				"FAIL - bar.yaml - second failure",
+				"namespace.deny_invalid",
+				"ERROR - baz.yaml - json.unmarshal: invalid character",
+				"namespace.warn",
+				"WARN - foo.yaml - first warning",
+				"",
+				"5 tests, 1 passed, 1 warning, 2 failures, 0 exceptions, 1 error",
+				"",
+			},
+		},
+		{
+			name: "groups results by namespace",
+			input: []CheckResult{
+				{
+					FileName:  "foo.yaml",
+					Namespace: "team.b",
+					Failures:  []Result{{Message: "first failure"}},
+				},
+				{
+					FileName:  "foo.yaml",
+					Namespace: "team.a",
+					Warnings:  []Result{{Message: "first warning"}},
+				},
+				{
+					FileName:  "bar.yaml",
+					Namespace: "team.b",
+					Successes: 1,
+				},
+				{
+					FileName:  "bar.yaml",
+					Namespace: "team.a",
+					Failures:  []Result{{Message: "second failure"}},
+				},
+			},
+			groupBy: GroupByNamespace,
+			expected: []string{
+				"team.a",
+				"WARN - foo.yaml - first warning",
+				"FAIL - bar.yaml - second failure",
+				"team.b",
+				"FAIL - foo.yaml - first failure",
+				"",
+				"4 tests, 1 passed, 1 warning, 2 failures, 0 exceptions",
+				"",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -96,7 +228,7 @@ func TestStandard(t *testing.T) {
 			expected := strings.Join(tt.expected, "\n")
 
 			buf := new(bytes.Buffer)
-			standard := Standard{Writer: buf, NoColor: true, ShowSkipped: tt.showSkipped}
+			standard := Standard{Writer: buf, NoColor: true, ShowSkipped: tt.showSkipped, GroupBy: tt.groupBy}
 			if err := standard.Output(tt.input); err != nil {
 				t.Fatal("output standard:", err)
 			}
@@ -0,0 +1,48 @@
+package output
+
+// The statuses that describe the outcome of a test run.
+const (
+	StatusOK         = "ok"
+	StatusWarnings   = "warnings"
+	StatusFailures   = "failures"
+	StatusParseError = "parse_error"
+	StatusNoInput    = "no_input"
+	StatusError      = "error"
+)
+
+// Statuses returns the statuses that a test run can result in.
+func Statuses() []string {
+	return []string{
+		StatusOK,
+		StatusWarnings,
+		StatusFailures,
+		StatusParseError,
+		StatusNoInput,
+		StatusError,
+	}
+}
+
+// Status returns the status of the given results. Failures and errors take
+// precedence over warnings, so the status is only StatusWarnings when none
+// of the results failed.
+//
+// The status only describes the results, options that change the exit
+// code, such as NoFail or MaxFailures, do not change the status.
+func Status(results []CheckResult) string {
+	var hasWarning bool
+	for _, result := range results {
+		if len(result.Failures) > 0 || len(result.Errors) > 0 {
+			return StatusFailures
+		}
+
+		if len(result.Warnings) > 0 {
+			hasWarning = true
+		}
+	}
+
+	if hasWarning {
+		return StatusWarnings
+	}
+
+	return StatusOK
+}
@@ -0,0 +1,40 @@
+package output
+
+import (
+	"testing"
+)
+
+func TestStatus(t *testing.T) {
+	warning := CheckResult{
+		Warnings: []Result{{}},
+	}
+
+	failure := CheckResult{
+		Failures: []Result{{}},
+	}
+
+	testCases := []struct {
+		name     string
+		results  []CheckResult
+		expected string
+	}{
+		{name: "no results", results: []CheckResult{}, expected: StatusOK},
+		{name: "successes", results: []CheckResult{{Successes: 2}}, expected: StatusOK},
+		{name: "exception", results: []CheckResult{{Exceptions: []Result{{}}}}, expected: StatusOK},
+		{name: "warning", results: []CheckResult{warning}, expected: StatusWarnings},
+		{name: "failure", results: []CheckResult{failure}, expected: StatusFailures},
+		{name: "error", results: []CheckResult{{Errors: []Result{{}}}}, expected: StatusFailures},
+		{name: "warning and failure", results: []CheckResult{warning, failure}, expected: StatusFailures},
+		{name: "failure and warning", results: []CheckResult{failure, warning}, expected: StatusFailures},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := Status(testCase.results)
+
+			if actual != testCase.expected {
+				t.Errorf("Unexpected status. expected %v, actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// Summary represents an Outputter that outputs a single line
+// per file with the number of results of every type, followed
+// by the totals, without the messages of the results.
+type Summary struct {
+	Writer io.Writer
+
+	// NoColor will disable all coloring when
+	// set to true.
+	NoColor bool
+}
+
+// NewSummary creates a new Summary with the given writer.
+func NewSummary(w io.Writer) *Summary {
+	summary := Summary{
+		Writer: w,
+	}
+
+	return &summary
+}
+
+type summaryCounts struct {
+	successes  int
+	warnings   int
+	failures   int
+	exceptions int
+	errors     int
+}
+
+// Output outputs the results.
+func (s *Summary) Output(results []CheckResult) error {
+	colorizer := aurora.NewAurora(!s.NoColor)
+
+	// A file is part of multiple results when it is tested against
+	// multiple namespaces, so the results are counted per file.
+	var files []string
+	counts := make(map[string]*summaryCounts)
+	var total summaryCounts
+	for _, result := range results {
+		if _, ok := counts[result.FileName]; !ok {
+			files = append(files, result.FileName)
+			counts[result.FileName] = &summaryCounts{}
+		}
+
+		fileCounts := counts[result.FileName]
+		for _, c := range []*summaryCounts{fileCounts, &total} {
+			c.successes += result.Successes
+			c.warnings += len(result.Warnings)
+			c.failures += len(result.Failures)
+			c.exceptions += len(result.Exceptions)
+			c.errors += len(result.Errors)
+		}
+	}
+
+	for _, file := range files {
+		fileCounts := counts[file]
+
+		var parts []string
+		if fileCounts.failures > 0 {
+			parts = append(parts, plural(fileCounts.failures, "failure"))
+		}
+		if fileCounts.errors > 0 {
+			parts = append(parts, plural(fileCounts.errors, "error"))
+		}
+		if fileCounts.warnings > 0 {
+			parts = append(parts, plural(fileCounts.warnings, "warning"))
+		}
+		if fileCounts.exceptions > 0 {
+			parts = append(parts, plural(fileCounts.exceptions, "exception"))
+		}
+		if fileCounts.successes > 0 || len(parts) == 0 {
+			parts = append(parts, fmt.Sprintf("%d passed", fileCounts.successes))
+		}
+
+		line := fmt.Sprintf("%s: %s", file, strings.Join(parts, ", "))
+		fmt.Fprintln(s.Writer, colorizer.Colorize(line, fileCounts.color()))
+	}
+
+	totalTests := total.successes + total.warnings + total.failures + total.exceptions + total.errors
+	totalText := fmt.Sprintf("%s, %s, %d passed, %s, %s, %s",
+		plural(len(files), "file"),
+		plural(totalTests, "test"),
+		total.successes,
+		plural(total.warnings, "warning"),
+		plural(total.failures, "failure"),
+		plural(total.exceptions, "exception"),
+	)
+
+	// Errors are only reported when evaluation errors are reported
+	// as results, so they are only part of the totals when present.
+	if total.errors > 0 {
+		totalText += ", " + plural(total.errors, "error")
+	}
+
+	fmt.Fprintln(s.Writer)
+	fmt.Fprintln(s.Writer, colorizer.Colorize(totalText, total.color()))
+	return nil
+}
+
+// color returns the color of the worst type of result.
+func (c summaryCounts) color() aurora.Color {
+	switch {
+	case c.failures > 0 || c.errors > 0:
+		return aurora.RedFg
+	case c.warnings > 0:
+		return aurora.YellowFg
+	case c.exceptions > 0:
+		return aurora.CyanFg
+	default:
+		return aurora.GreenFg
+	}
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, noun)
+	}
+
+	return fmt.Sprintf("%d %ss", count, noun)
+}
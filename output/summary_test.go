@@ -0,0 +1,92 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []CheckResult
+		expected []string
+	}{
+		{
+			name: "a line per file",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Namespace: "main",
+					Successes: 1,
+					Warnings:  []Result{{Message: "first warning"}},
+					Failures:  []Result{{Message: "first failure"}, {Message: "second failure"}},
+				},
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "main",
+					Successes: 4,
+				},
+			},
+			expected: []string{
+				"examples/kubernetes/deployment.yaml: 2 failures, 1 warning, 1 passed",
+				"examples/kubernetes/service.yaml: 4 passed",
+				"",
+				"2 files, 8 tests, 5 passed, 1 warning, 2 failures, 0 exceptions",
+				"",
+			},
+		},
+		{
+			name: "counts a file tested against multiple namespaces once",
+			input: []CheckResult{
+				{
+					FileName:   "examples/kubernetes/deployment.yaml",
+					Namespace:  "main",
+					Exceptions: []Result{{Message: "first exception"}},
+				},
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Namespace: "kubernetes",
+					Errors:    []Result{{Message: "first error"}},
+				},
+			},
+			expected: []string{
+				"examples/kubernetes/deployment.yaml: 1 error, 1 exception",
+				"",
+				"1 file, 2 tests, 0 passed, 0 warnings, 0 failures, 1 exception, 1 error",
+				"",
+			},
+		},
+		{
+			name: "a file without results",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/deployment.yaml",
+					Namespace: "main",
+				},
+			},
+			expected: []string{
+				"examples/kubernetes/deployment.yaml: 0 passed",
+				"",
+				"1 file, 0 tests, 0 passed, 0 warnings, 0 failures, 0 exceptions",
+				"",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expected := strings.Join(tt.expected, "\n")
+
+			buf := new(bytes.Buffer)
+			if err := (&Summary{Writer: buf, NoColor: true}).Output(tt.input); err != nil {
+				t.Fatal("output summary:", err)
+			}
+			actual := buf.String()
+
+			if expected != actual {
+				t.Errorf("Unexpected output. expected %v actual %v", expected, actual)
+			}
+		})
+	}
+}
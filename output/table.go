@@ -33,19 +33,23 @@ func (t *Table) Output(checkResults []CheckResult) error {
 		}
 
 		for _, result := range checkResult.Exceptions {
-			tableData = append(tableData, []string{"exception", checkResult.FileName, checkResult.Namespace, result.Message})
+			tableData = append(tableData, []string{"exception", documentFileName(checkResult.FileName, result), checkResult.Namespace, result.Message})
 		}
 
 		for _, result := range checkResult.Warnings {
-			tableData = append(tableData, []string{"warning", checkResult.FileName, checkResult.Namespace, result.Message})
+			tableData = append(tableData, []string{"warning", documentFileName(checkResult.FileName, result), checkResult.Namespace, result.Message})
 		}
 
 		for _, result := range checkResult.Skipped {
-			tableData = append(tableData, []string{"skipped", checkResult.FileName, checkResult.Namespace, result.Message})
+			tableData = append(tableData, []string{"skipped", documentFileName(checkResult.FileName, result), checkResult.Namespace, result.Message})
 		}
 
 		for _, result := range checkResult.Failures {
-			tableData = append(tableData, []string{"failure", checkResult.FileName, checkResult.Namespace, result.Message})
+			tableData = append(tableData, []string{"failure", documentFileName(checkResult.FileName, result), checkResult.Namespace, result.Message})
+		}
+
+		for _, result := range checkResult.Errors {
+			tableData = append(tableData, []string{"error", documentFileName(checkResult.FileName, result), checkResult.Namespace, result.Message})
 		}
 	}
 
@@ -43,6 +43,24 @@ func TestTable(t *testing.T) {
 				``,
 			},
 		},
+		{
+			name: "An error",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Errors:    []Result{{Message: "first error"}},
+				},
+			},
+			expected: []string{
+				`+--------+----------------------------------+-----------+-------------+`,
+				`| RESULT |               FILE               | NAMESPACE |   MESSAGE   |`,
+				`+--------+----------------------------------+-----------+-------------+`,
+				`| error  | examples/kubernetes/service.yaml | namespace | first error |`,
+				`+--------+----------------------------------+-----------+-------------+`,
+				``,
+			},
+		},
 	}
 
 	for _, tt := range tests {
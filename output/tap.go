@@ -23,21 +23,20 @@ func NewTAP(w io.Writer) *TAP {
 // Output outputs the results.
 func (t *TAP) Output(checkResults []CheckResult) error {
 	for _, result := range checkResults {
-		var indicator string
-		var namespace string
-		if result.FileName == "-" {
-			indicator = "-"
-		} else {
-			indicator = fmt.Sprintf("- %s", result.FileName)
+		indicator := func(r Result) string {
+			if result.FileName == "-" {
+				return "-"
+			}
+
+			return fmt.Sprintf("- %s", documentFileName(result.FileName, r))
 		}
 
+		namespace := fmt.Sprintf("- %s -", result.Namespace)
 		if result.Namespace == "-" {
 			namespace = "-"
-		} else {
-			namespace = fmt.Sprintf("- %s -", result.Namespace)
 		}
 
-		totalTests := result.Successes + len(result.Failures) + len(result.Warnings) + len(result.Exceptions) + len(result.Skipped)
+		totalTests := result.Successes + len(result.Failures) + len(result.Warnings) + len(result.Exceptions) + len(result.Skipped) + len(result.Errors)
 		if totalTests == 0 {
 			return nil
 		}
@@ -46,14 +45,22 @@ func (t *TAP) Output(checkResults []CheckResult) error {
 		fmt.Fprintf(t.Writer, "1..%d\n", totalTests)
 
 		for _, failure := range result.Failures {
-			fmt.Fprintf(t.Writer, "not ok %v %v %v %v\n", counter, indicator, namespace, failure.Message)
+			fmt.Fprintf(t.Writer, "not ok %v %v %v %v\n", counter, indicator(failure), namespace, failure.Message)
 			counter++
 		}
 
+		if len(result.Errors) > 0 {
+			fmt.Fprintln(t.Writer, "# errors")
+			for _, evaluationError := range result.Errors {
+				fmt.Fprintf(t.Writer, "not ok %v %v %v %v\n", counter, indicator(evaluationError), namespace, evaluationError.Message)
+				counter++
+			}
+		}
+
 		if len(result.Warnings) > 0 {
 			fmt.Fprintln(t.Writer, "# warnings")
 			for _, warning := range result.Warnings {
-				fmt.Fprintf(t.Writer, "not ok %v %v %v %v\n", counter, indicator, namespace, warning.Message)
+				fmt.Fprintf(t.Writer, "not ok %v %v %v %v\n", counter, indicator(warning), namespace, warning.Message)
 				counter++
 			}
 		}
@@ -61,7 +68,7 @@ func (t *TAP) Output(checkResults []CheckResult) error {
 		if len(result.Exceptions) > 0 {
 			fmt.Fprintln(t.Writer, "# exceptions")
 			for _, exception := range result.Exceptions {
-				fmt.Fprintf(t.Writer, "ok %v %v %v %v\n", counter, indicator, namespace, exception.Message)
+				fmt.Fprintf(t.Writer, "ok %v %v %v %v\n", counter, indicator(exception), namespace, exception.Message)
 				counter++
 			}
 		}
@@ -69,7 +76,7 @@ func (t *TAP) Output(checkResults []CheckResult) error {
 		if len(result.Skipped) > 0 {
 			fmt.Fprintln(t.Writer, "# skip")
 			for _, skipped := range result.Skipped {
-				fmt.Fprintf(t.Writer, "ok %v %v %v %v\n", counter, indicator, namespace, skipped.Message)
+				fmt.Fprintf(t.Writer, "ok %v %v %v %v\n", counter, indicator(skipped), namespace, skipped.Message)
 				counter++
 			}
 		}
@@ -77,7 +84,7 @@ func (t *TAP) Output(checkResults []CheckResult) error {
 		if result.Successes > 0 {
 			fmt.Fprintln(t.Writer, "# successes")
 			for i := 0; i < result.Successes; i++ {
-				fmt.Fprintf(t.Writer, "ok %v %v %v %v\n", counter, indicator, namespace, "SUCCESS")
+				fmt.Fprintf(t.Writer, "ok %v %v %v %v\n", counter, indicator(Result{}), namespace, "SUCCESS")
 				counter++
 			}
 		}
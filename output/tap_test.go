@@ -7,6 +7,8 @@ import (
 )
 
 func TestTAP(t *testing.T) {
+	secondDocument := 1
+
 	tests := []struct {
 		name     string
 		input    []CheckResult
@@ -58,6 +60,39 @@ func TestTAP(t *testing.T) {
 				"",
 			},
 		},
+		{
+			name: "records errors",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Failures:  []Result{{Message: "first failure"}},
+					Errors:    []Result{{Message: "first error"}},
+				},
+			},
+			expected: []string{
+				"1..2",
+				"not ok 1 - examples/kubernetes/service.yaml - namespace - first failure",
+				"# errors",
+				"not ok 2 - examples/kubernetes/service.yaml - namespace - first error",
+				"",
+			},
+		},
+		{
+			name: "records the document of results from multi-document files",
+			input: []CheckResult{
+				{
+					FileName:  "examples/kubernetes/service.yaml",
+					Namespace: "namespace",
+					Failures:  []Result{{Message: "first failure", Document: &secondDocument}},
+				},
+			},
+			expected: []string{
+				"1..1",
+				"not ok 1 - examples/kubernetes/service.yaml[1] - namespace - first failure",
+				"",
+			},
+		},
 		{
 			name: "handles stdin input",
 			input: []CheckResult{
@@ -0,0 +1,118 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// DefaultWebhookContentType is the content type of the requests sent to a webhook
+// when no content type is configured.
+const DefaultWebhookContentType = "application/json"
+
+// Webhook represents an Outputter that sends the results in JSON format
+// to an HTTP endpoint, after writing them with the Outputter it wraps.
+type Webhook struct {
+	// Outputter is the Outputter that writes the results before they are
+	// sent to the webhook. When it is nil, the results are only sent to
+	// the webhook.
+	Outputter Outputter
+
+	URL         string
+	ContentType string
+
+	// Token is sent as a bearer token in the Authorization header. When it
+	// is empty and a username is set, basic authentication is used instead.
+	Token    string
+	Username string
+	Password string
+
+	Client *http.Client
+
+	// IgnoreErrors reports the errors of the request to ErrorWriter
+	// instead of returning them.
+	IgnoreErrors bool
+	ErrorWriter  io.Writer
+}
+
+// NewWebhook creates a new Webhook that sends the results to the given URL
+// after writing them with the given Outputter.
+func NewWebhook(outputter Outputter, url string) *Webhook {
+	webhook := Webhook{
+		Outputter:   outputter,
+		URL:         url,
+		ContentType: DefaultWebhookContentType,
+		Client:      http.DefaultClient,
+	}
+
+	return &webhook
+}
+
+// Output writes the results with the wrapped Outputter, then sends
+// the results to the webhook.
+func (w *Webhook) Output(results []CheckResult) error {
+	if w.Outputter != nil {
+		if err := w.Outputter.Output(results); err != nil {
+			return err
+		}
+	}
+
+	if err := w.send(results); err != nil {
+		if !w.IgnoreErrors {
+			return err
+		}
+
+		if w.ErrorWriter != nil {
+			fmt.Fprintln(w.ErrorWriter, "WARN - webhook -", err)
+		}
+	}
+
+	return nil
+}
+
+func (w *Webhook) send(results []CheckResult) error {
+	// The JSON outputter modifies the results it is given, so it is given
+	// a copy to leave the results of the wrapped Outputter as they are.
+	copied := make([]CheckResult, len(results))
+	copy(copied, results)
+
+	var body bytes.Buffer
+	if err := NewJSON(&body).Output(copied); err != nil {
+		return fmt.Errorf("marshal results: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, &body)
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+
+	contentType := w.ContentType
+	if contentType == "" {
+		contentType = DefaultWebhookContentType
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	if w.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+w.Token)
+	} else if w.Username != "" {
+		req.SetBasicAuth(w.Username, w.Password)
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send results to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("send results to webhook: unexpected status %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhook(t *testing.T) {
+	var contentType string
+	var authorization string
+	var received []CheckResult
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		authorization = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	results := []CheckResult{
+		{
+			FileName:  "-",
+			Namespace: "main",
+			Failures:  []Result{{Message: "first failure"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	webhook := NewWebhook(NewStandard(&buf), server.URL)
+	webhook.Client = server.Client()
+	webhook.Token = "secret"
+	if err := webhook.Output(results); err != nil {
+		t.Fatalf("output results: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "first failure") {
+		t.Errorf("Expected the wrapped output to contain the failure, actual %v", buf.String())
+	}
+
+	if contentType != DefaultWebhookContentType {
+		t.Errorf("Unexpected content type. expected %v actual %v", DefaultWebhookContentType, contentType)
+	}
+
+	if authorization != "Bearer secret" {
+		t.Errorf("Unexpected authorization. expected %v actual %v", "Bearer secret", authorization)
+	}
+
+	if len(received) != 1 || len(received[0].Failures) != 1 {
+		t.Fatalf("Unexpected results. actual %v", received)
+	}
+
+	if results[0].FileName != "-" {
+		t.Errorf("Expected the results to be left unchanged, actual file name %v", results[0].FileName)
+	}
+}
+
+func TestWebhookBasicAuth(t *testing.T) {
+	var username, password string
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+		contentType = r.Header.Get("Content-Type")
+		ioutil.ReadAll(r.Body) //nolint
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(nil, server.URL)
+	webhook.Client = server.Client()
+	webhook.Username = "conftest"
+	webhook.Password = "password"
+	webhook.ContentType = "application/vnd.conftest+json"
+	if err := webhook.Output(nil); err != nil {
+		t.Fatalf("output results: %v", err)
+	}
+
+	if username != "conftest" || password != "password" {
+		t.Errorf("Unexpected credentials. expected conftest:password actual %v:%v", username, password)
+	}
+
+	if contentType != "application/vnd.conftest+json" {
+		t.Errorf("Unexpected content type. expected %v actual %v", "application/vnd.conftest+json", contentType)
+	}
+}
+
+func TestWebhookErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	webhook := NewWebhook(nil, server.URL)
+	webhook.Client = server.Client()
+	if err := webhook.Output(nil); err == nil {
+		t.Error("expected an error when the webhook returns an unexpected status")
+	}
+
+	var buf bytes.Buffer
+	webhook.IgnoreErrors = true
+	webhook.ErrorWriter = &buf
+	if err := webhook.Output(nil); err != nil {
+		t.Errorf("Unexpected error when ignoring errors: %v", err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "WARN - webhook -") {
+		t.Errorf("Expected the error to be reported, actual %v", buf.String())
+	}
+}
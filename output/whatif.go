@@ -0,0 +1,168 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/logrusorgru/aurora"
+)
+
+// The kinds of findings that are compared by DiffResults.
+const (
+	FindingFailure = "failure"
+	FindingWarning = "warning"
+)
+
+// Finding is a failure or warning along with the file and
+// namespace that produced it.
+type Finding struct {
+	FileName  string `json:"filename"`
+	Namespace string `json:"namespace"`
+	Kind      string `json:"kind"`
+	Result
+}
+
+// WhatIf describes the findings that a change to the input would introduce,
+// and the findings that the change would resolve.
+type WhatIf struct {
+	Introduced []Finding `json:"introduced"`
+	Resolved   []Finding `json:"resolved"`
+}
+
+// DiffResults compares the failures and warnings of the results before and after
+// a change. A finding is the same finding before and after the change when it
+// has the same file, namespace, rule and message. Findings that only exist after
+// the change are introduced, and findings that only exist before are resolved.
+func DiffResults(before []CheckResult, after []CheckResult) WhatIf {
+	beforeFindings := findings(before)
+	afterFindings := findings(after)
+
+	beforeCounts := make(map[string]int)
+	for _, finding := range beforeFindings {
+		beforeCounts[findingKey(finding)]++
+	}
+
+	afterCounts := make(map[string]int)
+	for _, finding := range afterFindings {
+		afterCounts[findingKey(finding)]++
+	}
+
+	whatIf := WhatIf{Introduced: []Finding{}, Resolved: []Finding{}}
+	for _, finding := range afterFindings {
+		key := findingKey(finding)
+		if beforeCounts[key] > 0 {
+			beforeCounts[key]--
+			continue
+		}
+
+		whatIf.Introduced = append(whatIf.Introduced, finding)
+	}
+
+	for _, finding := range beforeFindings {
+		key := findingKey(finding)
+		if afterCounts[key] > 0 {
+			afterCounts[key]--
+			continue
+		}
+
+		whatIf.Resolved = append(whatIf.Resolved, finding)
+	}
+
+	return whatIf
+}
+
+// IntroducedResults returns the introduced findings as results, so that
+// the exit code can be determined from the introduced findings only.
+func (w WhatIf) IntroducedResults() []CheckResult {
+	var results []CheckResult
+	for _, finding := range w.Introduced {
+		result := CheckResult{FileName: finding.FileName, Namespace: finding.Namespace}
+		if finding.Kind == FindingFailure {
+			result.Failures = []Result{finding.Result}
+		} else {
+			result.Warnings = []Result{finding.Result}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// OutputWhatIf writes the findings that are introduced and resolved in the given
+// format. The json format writes the findings as a JSON object, while every other
+// format writes them in a human readable format, where introduced findings are
+// prefixed with + and resolved findings with -.
+func OutputWhatIf(w io.Writer, whatIf WhatIf, format string, noColor bool) error {
+	if format == OutputJSON {
+		b, err := json.Marshal(whatIf)
+		if err != nil {
+			return fmt.Errorf("marshal json: %w", err)
+		}
+
+		var out bytes.Buffer
+		if err := json.Indent(&out, b, "", "\t"); err != nil {
+			return fmt.Errorf("indent: %w", err)
+		}
+
+		fmt.Fprintln(w, out.String())
+		return nil
+	}
+
+	colorizer := aurora.NewAurora(!noColor)
+	for _, finding := range whatIf.Introduced {
+		fmt.Fprintln(w, colorizer.Colorize("+ "+findingLabel(finding), aurora.RedFg), findingIndicator(finding), finding.Message)
+	}
+
+	for _, finding := range whatIf.Resolved {
+		fmt.Fprintln(w, colorizer.Colorize("- "+findingLabel(finding), aurora.GreenFg), findingIndicator(finding), finding.Message)
+	}
+
+	var pluralSuffixIntroduced string
+	if len(whatIf.Introduced) != 1 {
+		pluralSuffixIntroduced = "s"
+	}
+
+	var pluralSuffixResolved string
+	if len(whatIf.Resolved) != 1 {
+		pluralSuffixResolved = "s"
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%v finding%s introduced, %v finding%s resolved\n", len(whatIf.Introduced), pluralSuffixIntroduced, len(whatIf.Resolved), pluralSuffixResolved)
+	return nil
+}
+
+// findings returns the failures and warnings of the results.
+func findings(results []CheckResult) []Finding {
+	var all []Finding
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			all = append(all, Finding{FileName: result.FileName, Namespace: result.Namespace, Kind: FindingFailure, Result: failure})
+		}
+
+		for _, warning := range result.Warnings {
+			all = append(all, Finding{FileName: result.FileName, Namespace: result.Namespace, Kind: FindingWarning, Result: warning})
+		}
+	}
+
+	return all
+}
+
+func findingKey(finding Finding) string {
+	return finding.Kind + "\x00" + finding.FileName + "\x00" + finding.Namespace + "\x00" + finding.Rule + "\x00" + finding.Message
+}
+
+func findingLabel(finding Finding) string {
+	if finding.Kind == FindingFailure {
+		return "FAIL"
+	}
+
+	return "WARN"
+}
+
+func findingIndicator(finding Finding) string {
+	return fmt.Sprintf("%s %s", fileNameIndicator(finding.FileName), namespaceIndicator(finding.Namespace))
+}
@@ -0,0 +1,84 @@
+package output
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiffResults(t *testing.T) {
+	before := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "containers must not run as root", Rule: "deny"},
+				{Message: "missing app label", Rule: "deny"},
+			},
+			Warnings: []Result{{Message: "missing resource limits", Rule: "warn"}},
+		},
+	}
+
+	after := []CheckResult{
+		{
+			FileName:  "deployment.yaml",
+			Namespace: "main",
+			Failures: []Result{
+				{Message: "missing app label", Rule: "deny"},
+				{Message: "image must not use the latest tag", Rule: "deny"},
+			},
+			Warnings: []Result{
+				{Message: "missing resource limits", Rule: "warn"},
+				{Message: "missing resource limits", Rule: "warn"},
+			},
+		},
+	}
+
+	expected := WhatIf{
+		Introduced: []Finding{
+			{FileName: "deployment.yaml", Namespace: "main", Kind: FindingFailure, Result: Result{Message: "image must not use the latest tag", Rule: "deny"}},
+			{FileName: "deployment.yaml", Namespace: "main", Kind: FindingWarning, Result: Result{Message: "missing resource limits", Rule: "warn"}},
+		},
+		Resolved: []Finding{
+			{FileName: "deployment.yaml", Namespace: "main", Kind: FindingFailure, Result: Result{Message: "containers must not run as root", Rule: "deny"}},
+		},
+	}
+
+	actual := DiffResults(before, after)
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected what if. expected %v actual %v", expected, actual)
+	}
+
+	if code := ExitCode(actual.IntroducedResults()); code != 1 {
+		t.Errorf("Unexpected exit code. expected %v actual %v", 1, code)
+	}
+}
+
+func TestOutputWhatIf(t *testing.T) {
+	whatIf := WhatIf{
+		Introduced: []Finding{
+			{FileName: "deployment.yaml", Namespace: "main", Kind: FindingFailure, Result: Result{Message: "image must not use the latest tag"}},
+		},
+		Resolved: []Finding{
+			{FileName: "deployment.yaml", Namespace: "main", Kind: FindingWarning, Result: Result{Message: "missing resource limits"}},
+		},
+	}
+
+	expected := []string{
+		"+ FAIL - deployment.yaml - main - image must not use the latest tag",
+		"- WARN - deployment.yaml - main - missing resource limits",
+		"",
+		"1 finding introduced, 1 finding resolved",
+		"",
+	}
+
+	buf := new(bytes.Buffer)
+	if err := OutputWhatIf(buf, whatIf, OutputStandard, true); err != nil {
+		t.Fatal("output what if:", err)
+	}
+
+	if actual := buf.String(); actual != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected output. expected %v actual %v", strings.Join(expected, "\n"), actual)
+	}
+}
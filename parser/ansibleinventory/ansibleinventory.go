@@ -0,0 +1,200 @@
+package ansibleinventory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parser is a parser for INI-style Ansible inventory files.
+type Parser struct{}
+
+// group is a group of an inventory, with the variables of every host
+// in the group, the names of its child groups and its variables.
+type group struct {
+	Hosts    map[string]map[string]string `json:"hosts"`
+	Children []string                     `json:"children"`
+	Vars     map[string]string            `json:"vars"`
+}
+
+// Unmarshal unmarshals INI-style Ansible inventory files.
+//
+// A [name] section lists the hosts of a group, where every host can be followed
+// by inline variables (host1 ansible_host=10.0.0.1). A [name:children] section
+// lists the child groups of a group, and a [name:vars] section sets the variables
+// of a group. Hosts that are listed before the first section belong to the
+// ungrouped group, as in Ansible. The groups are returned under the groups key:
+//
+//	{"groups": {"web": {"hosts": {"host1": {"ansible_host": "10.0.0.1"}}, "children": [], "vars": {}}}}
+//
+// All variables are kept as strings, and lines starting with # or ; are comments.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	groups := make(map[string]*group)
+	getGroup := func(name string) *group {
+		if _, ok := groups[name]; !ok {
+			groups[name] = &group{
+				Hosts:    make(map[string]map[string]string),
+				Children: []string{},
+				Vars:     make(map[string]string),
+			}
+		}
+
+		return groups[name]
+	}
+
+	name := "ungrouped"
+	kind := "hosts"
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("line %d: invalid section header %q", lineNumber, line)
+			}
+
+			name, kind = strings.TrimSpace(line[1:len(line)-1]), "hosts"
+			if i := strings.LastIndex(name, ":"); i >= 0 {
+				if suffix := name[i+1:]; suffix != "children" && suffix != "vars" {
+					return fmt.Errorf("line %d: unknown section type %q", lineNumber, suffix)
+				}
+
+				name, kind = name[:i], name[i+1:]
+			}
+
+			if name == "" {
+				return fmt.Errorf("line %d: missing group name", lineNumber)
+			}
+
+			getGroup(name)
+			continue
+		}
+
+		fields, err := splitFields(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		if len(fields) == 0 {
+			continue
+		}
+
+		current := getGroup(name)
+		switch kind {
+		case "children":
+			if len(fields) != 1 {
+				return fmt.Errorf("line %d: expected a single group name", lineNumber)
+			}
+
+			getGroup(fields[0])
+			current.Children = append(current.Children, fields[0])
+
+		case "vars":
+			parts := strings.SplitN(line, "=", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("line %d: missing '=' separator", lineNumber)
+			}
+
+			current.Vars[strings.TrimSpace(parts[0])] = unquote(strings.TrimSpace(parts[1]))
+
+		default:
+			host := fields[0]
+			if strings.Contains(host, "=") {
+				return fmt.Errorf("line %d: missing host name", lineNumber)
+			}
+
+			vars := current.Hosts[host]
+			if vars == nil {
+				vars = make(map[string]string)
+				current.Hosts[host] = vars
+			}
+
+			for _, field := range fields[1:] {
+				parts := strings.SplitN(field, "=", 2)
+				if len(parts) != 2 {
+					return fmt.Errorf("line %d: invalid host variable %q", lineNumber, field)
+				}
+
+				vars[parts[0]] = parts[1]
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan ansible inventory: %w", err)
+	}
+
+	j, err := json.Marshal(map[string]interface{}{"groups": groups})
+	if err != nil {
+		return fmt.Errorf("marshal ansible inventory to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal ansible inventory json: %w", err)
+	}
+
+	return nil
+}
+
+// splitFields splits a line into fields separated by whitespace. Quoted
+// parts of a field may contain whitespace, and the quotes are removed.
+// A field starting with # outside of quotes starts a comment, which
+// ends the line.
+func splitFields(line string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	var inField bool
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			field.WriteRune(r)
+
+		case r == '"' || r == '\'':
+			quote = r
+			inField = true
+
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+
+		case r == '#' && !inField:
+			return fields, nil
+
+		default:
+			field.WriteRune(r)
+			inField = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+
+	if inField {
+		fields = append(fields, field.String())
+	}
+
+	return fields, nil
+}
+
+// unquote removes the quotes around a value, if the value is quoted.
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
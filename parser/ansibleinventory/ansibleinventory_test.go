@@ -0,0 +1,91 @@
+package ansibleinventory
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAnsibleInventoryParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `bastion.example.com
+
+# Web servers
+[web]
+web1.example.com ansible_host=10.0.0.1 ansible_user=deploy
+web2.example.com ansible_host=10.0.0.2 motd="hello world"  # inline comment
+
+[db]
+db1.example.com
+
+[production:children]
+web
+db
+
+[production:vars]
+; Applied to every host in production
+ntp_server = ntp.example.com
+environment = "production"`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"groups": map[string]interface{}{
+			"ungrouped": map[string]interface{}{
+				"hosts":    map[string]interface{}{"bastion.example.com": map[string]interface{}{}},
+				"children": []interface{}{},
+				"vars":     map[string]interface{}{},
+			},
+			"web": map[string]interface{}{
+				"hosts": map[string]interface{}{
+					"web1.example.com": map[string]interface{}{"ansible_host": "10.0.0.1", "ansible_user": "deploy"},
+					"web2.example.com": map[string]interface{}{"ansible_host": "10.0.0.2", "motd": "hello world"},
+				},
+				"children": []interface{}{},
+				"vars":     map[string]interface{}{},
+			},
+			"db": map[string]interface{}{
+				"hosts":    map[string]interface{}{"db1.example.com": map[string]interface{}{}},
+				"children": []interface{}{},
+				"vars":     map[string]interface{}{},
+			},
+			"production": map[string]interface{}{
+				"hosts":    map[string]interface{}{},
+				"children": []interface{}{"web", "db"},
+				"vars":     map[string]interface{}{"ntp_server": "ntp.example.com", "environment": "production"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected result. expected %v actual %v", expected, input)
+	}
+}
+
+func TestAnsibleInventoryParserInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "unterminated section", input: "[web"},
+		{name: "unknown section type", input: "[web:hosts]"},
+		{name: "missing group name", input: "[:vars]"},
+		{name: "missing separator in vars", input: "[web:vars]\nntp_server"},
+		{name: "invalid host variable", input: "[web]\nweb1 ansible_host"},
+		{name: "unterminated quote", input: "[web]\nweb1 motd=\"hello"},
+		{name: "multiple children on a line", input: "[production:children]\nweb db"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			if err := parser.Unmarshal([]byte(testCase.input), &input); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Parser is an Avro schema parser.
+type Parser struct{}
+
+// Unmarshal unmarshals Avro schemas (.avsc).
+//
+// Avro schemas are JSON documents, but the nesting of records and the way
+// unions are written make them awkward to write policies for. The schema is
+// normalized so that:
+//
+//   - Unions are always an object with a nullable field, which is true when
+//     the union contains "null", and a types field with the other types of
+//     the union. For example, ["null", "string"] becomes
+//     {"nullable": true, "types": ["string"]}.
+//   - Record schemas have a flattenedFields field that lists the fields of
+//     the record and of all of the records nested in it. Every field has a
+//     path field with the names of the records it is nested in, joined
+//     with a "." (e.g. address.street).
+//
+// Types that refer to a named type by its name are not resolved.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var schema interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Errorf("unmarshal avro schema: %w", err)
+	}
+
+	normalized, err := normalizeType(schema)
+	if err != nil {
+		return fmt.Errorf("normalize schema: %w", err)
+	}
+
+	if record, ok := normalized.(map[string]interface{}); ok && isRecord(record) {
+		record["flattenedFields"] = flattenFields(record, "")
+	}
+
+	j, err := json.Marshal(normalized)
+	if err != nil {
+		return fmt.Errorf("marshal avro schema to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal avro schema json: %w", err)
+	}
+
+	return nil
+}
+
+func normalizeType(schema interface{}) (interface{}, error) {
+	switch value := schema.(type) {
+	case []interface{}:
+		return normalizeUnion(value)
+
+	case map[string]interface{}:
+		if isRecord(value) {
+			fields, ok := value["fields"].([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("record %v does not have a list of fields", value["name"])
+			}
+
+			for i, f := range fields {
+				field, ok := f.(map[string]interface{})
+				if !ok {
+					return nil, fmt.Errorf("field %d of record %v is not an object", i, value["name"])
+				}
+
+				fieldType, err := normalizeType(field["type"])
+				if err != nil {
+					return nil, fmt.Errorf("field %v: %w", field["name"], err)
+				}
+
+				field["type"] = fieldType
+			}
+		}
+
+		for _, key := range []string{"items", "values"} {
+			if child, ok := value[key]; ok {
+				normalizedChild, err := normalizeType(child)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", key, err)
+				}
+
+				value[key] = normalizedChild
+			}
+		}
+
+		return value, nil
+
+	default:
+		return value, nil
+	}
+}
+
+func normalizeUnion(union []interface{}) (map[string]interface{}, error) {
+	nullable := false
+	types := []interface{}{}
+	for _, member := range union {
+		if member == "null" {
+			nullable = true
+			continue
+		}
+
+		normalized, err := normalizeType(member)
+		if err != nil {
+			return nil, fmt.Errorf("union: %w", err)
+		}
+
+		types = append(types, normalized)
+	}
+
+	return map[string]interface{}{"nullable": nullable, "types": types}, nil
+}
+
+// flattenFields returns the fields of the given record, followed by the fields
+// of the records nested in each of them. The type must already be normalized.
+func flattenFields(record map[string]interface{}, prefix string) []interface{} {
+	fields, _ := record["fields"].([]interface{})
+
+	flattened := []interface{}{}
+	for _, f := range fields {
+		field := f.(map[string]interface{})
+		path := fmt.Sprintf("%s%v", prefix, field["name"])
+
+		flattenedField := make(map[string]interface{})
+		for key, value := range field {
+			flattenedField[key] = value
+		}
+		flattenedField["path"] = path
+		flattened = append(flattened, flattenedField)
+
+		for _, nested := range nestedRecords(field["type"]) {
+			flattened = append(flattened, flattenFields(nested, path+".")...)
+		}
+	}
+
+	return flattened
+}
+
+// nestedRecords returns the records that are defined by the given type,
+// either directly or as a member of a union, array or map.
+func nestedRecords(schema interface{}) []map[string]interface{} {
+	value, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if isRecord(value) {
+		return []map[string]interface{}{value}
+	}
+
+	var records []map[string]interface{}
+	if types, ok := value["types"].([]interface{}); ok {
+		for _, member := range types {
+			records = append(records, nestedRecords(member)...)
+		}
+	}
+
+	for _, key := range []string{"items", "values"} {
+		records = append(records, nestedRecords(value[key])...)
+	}
+
+	return records
+}
+
+func isRecord(schema map[string]interface{}) bool {
+	return schema["type"] == "record" || schema["type"] == "error"
+}
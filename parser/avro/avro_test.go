@@ -0,0 +1,115 @@
+package avro
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAvroParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+	"type": "record",
+	"name": "User",
+	"namespace": "com.example",
+	"fields": [
+		{"name": "id", "type": "string"},
+		{"name": "email", "type": ["null", "string"], "default": null},
+		{
+			"name": "address",
+			"type": {
+				"type": "record",
+				"name": "Address",
+				"fields": [
+					{"name": "street", "type": "string", "default": ""},
+					{"name": "tags", "type": {"type": "array", "items": ["null", "string", "int"]}}
+				]
+			}
+		}
+	]
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	schema := input.(map[string]interface{})
+	fields := schema["fields"].([]interface{})
+
+	email := fields[1].(map[string]interface{})
+	expectedUnion := map[string]interface{}{
+		"nullable": true,
+		"types":    []interface{}{"string"},
+	}
+	if !reflect.DeepEqual(email["type"], expectedUnion) {
+		t.Errorf("Unexpected union. expected %v actual %v", expectedUnion, email["type"])
+	}
+
+	if _, ok := email["default"]; !ok {
+		t.Error("expected the default of the email field to be kept")
+	}
+
+	flattened := schema["flattenedFields"].([]interface{})
+
+	var paths []interface{}
+	for _, field := range flattened {
+		paths = append(paths, field.(map[string]interface{})["path"])
+	}
+
+	expectedPaths := []interface{}{"id", "email", "address", "address.street", "address.tags"}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("Unexpected paths. expected %v actual %v", expectedPaths, paths)
+	}
+
+	tags := flattened[4].(map[string]interface{})
+	items := tags["type"].(map[string]interface{})["items"]
+	expectedItems := map[string]interface{}{
+		"nullable": true,
+		"types":    []interface{}{"string", "int"},
+	}
+	if !reflect.DeepEqual(items, expectedItems) {
+		t.Errorf("Unexpected array items. expected %v actual %v", expectedItems, items)
+	}
+}
+
+func TestAvroParserUnionOfRecords(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+	"type": "record",
+	"name": "Event",
+	"fields": [
+		{
+			"name": "payload",
+			"type": [
+				"null",
+				{"type": "record", "name": "Created", "fields": [{"name": "by", "type": "string"}]}
+			]
+		}
+	]
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	flattened := input.(map[string]interface{})["flattenedFields"].([]interface{})
+	if len(flattened) != 2 {
+		t.Fatalf("expected 2 flattened fields, got %d", len(flattened))
+	}
+
+	path := flattened[1].(map[string]interface{})["path"]
+	if path != "payload.by" {
+		t.Errorf("Unexpected path. expected %v actual %v", "payload.by", path)
+	}
+}
+
+func TestAvroParserInvalidRecord(t *testing.T) {
+	parser := &Parser{}
+	sample := `{"type": "record", "name": "User", "fields": "id"}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+		t.Error("expected an error for a record without a list of fields")
+	}
+}
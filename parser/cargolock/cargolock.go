@@ -0,0 +1,66 @@
+package cargolock
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Parser is a parser for Cargo.lock files, the lockfiles of Rust packages.
+type Parser struct{}
+
+// Package is a single package that is locked by a Cargo.lock file.
+type Package struct {
+	Name     string `json:"name" toml:"name"`
+	Version  string `json:"version" toml:"version"`
+	Source   string `json:"source" toml:"source"`
+	Checksum string `json:"checksum" toml:"checksum"`
+
+	// Dependencies are the packages that the package depends on, which are
+	// the name of the package, followed by its version and source when more
+	// than one version of the package is locked.
+	Dependencies []string `json:"dependencies" toml:"dependencies"`
+}
+
+type lockFile struct {
+	Package  []Package         `toml:"package"`
+	Metadata map[string]string `toml:"metadata"`
+}
+
+// Unmarshal unmarshals Cargo.lock files.
+//
+// The packages of the lockfile are returned as a list, where every package has its
+// name, version, source and checksum. Packages of the workspace itself do not have a
+// source or a checksum. Lockfiles of version 1 keep the checksums in a metadata table
+// instead, in which case the checksum of each package is read from that table.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var file lockFile
+	if err := toml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("unmarshal toml: %w", err)
+	}
+
+	packages := []Package{}
+	for _, pkg := range file.Package {
+		if pkg.Checksum == "" && pkg.Source != "" {
+			pkg.Checksum = file.Metadata[fmt.Sprintf("checksum %s %s (%s)", pkg.Name, pkg.Version, pkg.Source)]
+		}
+
+		if pkg.Dependencies == nil {
+			pkg.Dependencies = []string{}
+		}
+
+		packages = append(packages, pkg)
+	}
+
+	j, err := json.Marshal(packages)
+	if err != nil {
+		return fmt.Errorf("marshal packages to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal packages json: %w", err)
+	}
+
+	return nil
+}
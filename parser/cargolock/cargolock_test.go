@@ -0,0 +1,74 @@
+package cargolock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCargoLockParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# This file is automatically @generated by Cargo.
+# It is not intended for manual editing.
+version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+dependencies = [
+ "serde",
+]
+
+[[package]]
+name = "serde"
+version = "1.0.136"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+checksum = "ce31e24b01e1e524df96f1c2fdd054405f8d7376249a5110886fb4b658484789"
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"name":         "app",
+			"version":      "0.1.0",
+			"source":       "",
+			"checksum":     "",
+			"dependencies": []interface{}{"serde"},
+		},
+		map[string]interface{}{
+			"name":         "serde",
+			"version":      "1.0.136",
+			"source":       "registry+https://github.com/rust-lang/crates.io-index",
+			"checksum":     "ce31e24b01e1e524df96f1c2fdd054405f8d7376249a5110886fb4b658484789",
+			"dependencies": []interface{}{},
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected packages. expected %v actual %v", expected, input)
+	}
+}
+
+func TestCargoLockParserMetadataChecksums(t *testing.T) {
+	parser := &Parser{}
+	sample := `[[package]]
+name = "libc"
+version = "0.2.119"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[metadata]
+"checksum libc 0.2.119 (registry+https://github.com/rust-lang/crates.io-index)" = "1bf2e165bb3457c8e098ea76f3e3bc9db55f87aa90d52d0e6be741470916aaa4"
+`
+
+	var input []Package
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 1 || input[0].Checksum != "1bf2e165bb3457c8e098ea76f3e3bc9db55f87aa90d52d0e6be741470916aaa4" {
+		t.Errorf("Unexpected packages: %v", input)
+	}
+}
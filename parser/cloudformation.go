@@ -0,0 +1,116 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+const cloudFormationStackType = "AWS::CloudFormation::Stack"
+
+// ResolveNestedStacks inlines the templates of the nested stacks in the CloudFormation
+// templates of the given configurations, so that policies can evaluate the resources of
+// the nested stacks as well. The template of a nested stack is added to the resource of
+// the stack as NestedTemplate, and the nested stacks of the inlined templates are
+// resolved as well.
+//
+// The templates are looked up in the given directory. A TemplateURL that is a URL, such
+// as an S3 URL, is looked up by the file name in the URL, while other values are looked
+// up as a path relative to the directory. Nested stacks whose template cannot be found
+// or parsed are skipped, and a warning is returned for each of them.
+func ResolveNestedStacks(configurations map[string]interface{}, directory string) []string {
+	var paths []string
+	for path := range configurations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var warnings []string
+	for _, path := range paths {
+		warnings = append(warnings, resolveNestedStacks(configurations[path], path, directory, []string{path})...)
+	}
+
+	return warnings
+}
+
+func resolveNestedStacks(config interface{}, configPath string, directory string, visited []string) []string {
+	if documents, ok := config.([]interface{}); ok {
+		var warnings []string
+		for _, document := range documents {
+			warnings = append(warnings, resolveNestedStacks(document, configPath, directory, visited)...)
+		}
+
+		return warnings
+	}
+
+	template, ok := config.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	resources, ok := template["Resources"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var warnings []string
+	for _, name := range names {
+		resource, ok := resources[name].(map[string]interface{})
+		if !ok || resource["Type"] != cloudFormationStackType {
+			continue
+		}
+
+		properties, _ := resource["Properties"].(map[string]interface{})
+		templateURL, ok := properties["TemplateURL"].(string)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("%s: nested stack %s does not have a TemplateURL that is a string, skipping", configPath, name))
+			continue
+		}
+
+		templatePath := nestedTemplatePath(templateURL, directory)
+		if contains(visited, templatePath) {
+			warnings = append(warnings, fmt.Sprintf("%s: nested stack %s references %s recursively, skipping", configPath, name, templateURL))
+			continue
+		}
+
+		configurations, err := ParseConfigurations([]string{templatePath})
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s: nested stack %s template %s could not be loaded, skipping: %v", configPath, name, templateURL, err))
+			continue
+		}
+
+		nestedTemplate := configurations[templatePath]
+		warnings = append(warnings, resolveNestedStacks(nestedTemplate, templatePath, directory, append(visited, templatePath))...)
+		resource["NestedTemplate"] = nestedTemplate
+	}
+
+	return warnings
+}
+
+// nestedTemplatePath returns the path of the template of a nested stack
+// in the given directory.
+func nestedTemplatePath(templateURL string, directory string) string {
+	if u, err := url.Parse(templateURL); err == nil && u.Scheme != "" {
+		return filepath.Join(directory, path.Base(u.Path))
+	}
+
+	return filepath.Join(directory, filepath.FromSlash(templateURL))
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
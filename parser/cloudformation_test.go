@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestResolveNestedStacks(t *testing.T) {
+	directory := t.TempDir()
+
+	network := `AWSTemplateFormatVersion: "2010-09-09"
+Resources:
+  VPC:
+    Type: AWS::EC2::VPC
+    Properties:
+      CidrBlock: 10.0.0.0/16`
+	if err := ioutil.WriteFile(filepath.Join(directory, "network.yaml"), []byte(network), os.ModePerm); err != nil {
+		t.Fatalf("write template: %v", err)
+	}
+
+	configurations := map[string]interface{}{
+		"root.yaml": map[string]interface{}{
+			"Resources": map[string]interface{}{
+				"Network": map[string]interface{}{
+					"Type": "AWS::CloudFormation::Stack",
+					"Properties": map[string]interface{}{
+						"TemplateURL": "https://s3.amazonaws.com/templates/network.yaml",
+					},
+				},
+				"Database": map[string]interface{}{
+					"Type": "AWS::CloudFormation::Stack",
+					"Properties": map[string]interface{}{
+						"TemplateURL": "database.yaml",
+					},
+				},
+				"Bucket": map[string]interface{}{
+					"Type": "AWS::S3::Bucket",
+				},
+			},
+		},
+	}
+
+	warnings := ResolveNestedStacks(configurations, directory)
+
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "nested stack Database template database.yaml could not be loaded") {
+		t.Errorf("Unexpected warnings. expected a warning for the missing database template actual %v", warnings)
+	}
+
+	resources := configurations["root.yaml"].(map[string]interface{})["Resources"].(map[string]interface{})
+
+	expected := map[string]interface{}{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Resources": map[string]interface{}{
+			"VPC": map[string]interface{}{
+				"Type": "AWS::EC2::VPC",
+				"Properties": map[string]interface{}{
+					"CidrBlock": "10.0.0.0/16",
+				},
+			},
+		},
+	}
+
+	actual := resources["Network"].(map[string]interface{})["NestedTemplate"]
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected nested template. expected %v actual %v", expected, actual)
+	}
+
+	if _, ok := resources["Database"].(map[string]interface{})["NestedTemplate"]; ok {
+		t.Error("Unexpected nested template for the missing database template")
+	}
+}
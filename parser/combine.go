@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CombineByDirectory is the expression that groups the configurations
+// by the directory of the file that they were found in.
+const CombineByDirectory = "dir"
+
+// CombinedDefaultGroup is the group of the configurations that do not
+// have a value for the expression they are grouped by.
+const CombinedDefaultGroup = "Combined"
+
+// CombineConfigurationsBy combines the given configurations into groups, where the group of
+// every document is the result of the given expression. The result will be a map where the
+// key is the name of the group (e.g. Combined/production) and the value is the combined
+// configuration of the group, in the same structure as CombineConfigurations.
+//
+// The expression is either dir, to group by the directory of each file, or a JSONPath that
+// is evaluated against each document (e.g. $.metadata.namespace). JSONPaths support child
+// keys using dot or bracket notation ($.metadata['namespace']) and array indices ($.items[0]).
+// Documents where the path does not resolve to a string, number or boolean are placed in
+// the Combined group.
+func CombineConfigurationsBy(configs map[string]interface{}, expression string) (map[string]interface{}, error) {
+	// Files in the current directory have the key ., and the keys of other
+	// directories use forward slashes on every platform.
+	groupKey := func(path string, _ interface{}) (string, bool) {
+		return filepath.ToSlash(filepath.Dir(path)), true
+	}
+
+	if expression != CombineByDirectory {
+		segments, err := parseJSONPath(expression)
+		if err != nil {
+			return nil, fmt.Errorf("parse jsonpath: %w", err)
+		}
+
+		groupKey = func(_ string, document interface{}) (string, bool) {
+			return lookupJSONPath(document, segments)
+		}
+	}
+
+	// Each document of a multi-document file is grouped on its own, so the
+	// documents of a single file can end up in different groups.
+	groups := make(map[string]map[string]interface{})
+	addToGroup := func(group string, path string, document interface{}) {
+		if _, ok := groups[group]; !ok {
+			groups[group] = make(map[string]interface{})
+		}
+
+		documents, _ := groups[group][path].([]interface{})
+		groups[group][path] = append(documents, document)
+	}
+
+	for path, config := range configs {
+		documents, ok := config.([]interface{})
+		if !ok {
+			documents = []interface{}{config}
+		}
+
+		for _, document := range documents {
+			group := CombinedDefaultGroup
+			if key, ok := groupKey(path, document); ok {
+				group = CombinedDefaultGroup + "/" + key
+			}
+
+			addToGroup(group, path, document)
+		}
+	}
+
+	combinedGroups := make(map[string]interface{})
+	for group, groupConfigs := range groups {
+		combinedGroups[group] = CombineConfigurations(groupConfigs)["Combined"]
+	}
+
+	return combinedGroups, nil
+}
+
+// CombineConfigurationsByParser combines the given configurations into a single configuration,
+// where the configurations are grouped by the name of the parser that parsed them (e.g. dockerfile
+// or yaml). See NewFromPathAs for the supported values of the parser. The result will be a map
+// that contains a single key with a value of Combined, which is a map of parser names to the
+// combined configurations of the parser, in the same structure as CombineConfigurations.
+func CombineConfigurationsByParser(configs map[string]interface{}, parser string) (map[string]interface{}, error) {
+	parserConfigs := make(map[string]map[string]interface{})
+	for path, config := range configs {
+		name, err := NameFromPathAs(path, parser)
+		if err != nil {
+			return nil, fmt.Errorf("parser name of %v: %w", path, err)
+		}
+
+		if _, ok := parserConfigs[name]; !ok {
+			parserConfigs[name] = make(map[string]interface{})
+		}
+
+		parserConfigs[name][path] = config
+	}
+
+	combined := make(map[string]interface{})
+	for name, configs := range parserConfigs {
+		combined[name] = CombineConfigurations(configs)["Combined"]
+	}
+
+	return map[string]interface{}{"Combined": combined}, nil
+}
+
+// jsonPathSegment is a single step of a JSONPath, which is either
+// the key of an object or the index of an array.
+type jsonPathSegment struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+func parseJSONPath(expression string) ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("expression %q must be %q or start with $", expression, CombineByDirectory)
+	}
+
+	var segments []jsonPathSegment
+	remaining := expression[1:]
+	for remaining != "" {
+		switch {
+		case strings.HasPrefix(remaining, "."):
+			end := strings.IndexAny(remaining[1:], ".[")
+			if end < 0 {
+				end = len(remaining) - 1
+			}
+
+			key := remaining[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in expression %q", expression)
+			}
+
+			segments = append(segments, jsonPathSegment{key: key})
+			remaining = remaining[end+1:]
+
+		case strings.HasPrefix(remaining, "['") || strings.HasPrefix(remaining, `["`):
+			quote := remaining[1:2]
+			end := strings.Index(remaining[2:], quote+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated key in expression %q", expression)
+			}
+
+			segments = append(segments, jsonPathSegment{key: remaining[2 : end+2]})
+			remaining = remaining[end+4:]
+
+		case strings.HasPrefix(remaining, "["):
+			end := strings.Index(remaining, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated index in expression %q", expression)
+			}
+
+			index, err := strconv.Atoi(remaining[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in expression %q", remaining[1:end], expression)
+			}
+
+			segments = append(segments, jsonPathSegment{index: index, isIndex: true})
+			remaining = remaining[end+1:]
+
+		default:
+			return nil, fmt.Errorf("unexpected %q in expression %q", remaining, expression)
+		}
+	}
+
+	return segments, nil
+}
+
+func lookupJSONPath(document interface{}, segments []jsonPathSegment) (string, bool) {
+	current := document
+	for _, segment := range segments {
+		if segment.isIndex {
+			values, ok := current.([]interface{})
+			if !ok || segment.index >= len(values) {
+				return "", false
+			}
+
+			current = values[segment.index]
+			continue
+		}
+
+		values, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		if current, ok = values[segment.key]; !ok {
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case string:
+		return value, true
+	case float64, int, int64, bool:
+		return fmt.Sprint(value), true
+	default:
+		return "", false
+	}
+}
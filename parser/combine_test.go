@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestCombineConfigurationsBy(t *testing.T) {
+	configurations := map[string]interface{}{
+		"prod/deployment.yaml": map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+		},
+		"prod/resources.yaml": []interface{}{
+			map[string]interface{}{
+				"kind":     "Service",
+				"metadata": map[string]interface{}{"name": "web", "namespace": "prod"},
+			},
+			map[string]interface{}{
+				"kind":     "ConfigMap",
+				"metadata": map[string]interface{}{"name": "settings", "namespace": "dev"},
+			},
+		},
+		"cluster/namespace.yaml": map[string]interface{}{
+			"kind":     "Namespace",
+			"metadata": map[string]interface{}{"name": "prod"},
+		},
+		"kustomization.yaml": map[string]interface{}{
+			"kind": "Kustomization",
+		},
+	}
+
+	testCases := []struct {
+		expression string
+		expected   map[string][]string
+	}{
+		{
+			expression: "$.metadata.namespace",
+			expected: map[string][]string{
+				"Combined":      {"cluster/namespace.yaml", "kustomization.yaml"},
+				"Combined/dev":  {"prod/resources.yaml"},
+				"Combined/prod": {"prod/deployment.yaml", "prod/resources.yaml"},
+			},
+		},
+		{
+			expression: "$['metadata'].name",
+			expected: map[string][]string{
+				"Combined":          {"kustomization.yaml"},
+				"Combined/prod":     {"cluster/namespace.yaml"},
+				"Combined/settings": {"prod/resources.yaml"},
+				"Combined/web":      {"prod/deployment.yaml", "prod/resources.yaml"},
+			},
+		},
+		{
+			expression: "dir",
+			expected: map[string][]string{
+				"Combined/.":       {"kustomization.yaml"},
+				"Combined/cluster": {"cluster/namespace.yaml"},
+				"Combined/prod":    {"prod/deployment.yaml", "prod/resources.yaml", "prod/resources.yaml"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.expression, func(t *testing.T) {
+			groups, err := CombineConfigurationsBy(configurations, testCase.expression)
+			if err != nil {
+				t.Fatalf("combine configurations: %v", err)
+			}
+
+			actual := make(map[string][]string)
+			for group, combined := range groups {
+				contents, err := json.Marshal(combined)
+				if err != nil {
+					t.Fatalf("marshal combined configurations: %v", err)
+				}
+
+				var documents []struct {
+					Path string `json:"path"`
+				}
+				if err := json.Unmarshal(contents, &documents); err != nil {
+					t.Fatalf("unmarshal combined configurations: %v", err)
+				}
+
+				for _, document := range documents {
+					actual[group] = append(actual[group], document.Path)
+				}
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("Unexpected groups. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCombineConfigurationsByInvalidExpression(t *testing.T) {
+	for _, expression := range []string{"metadata.namespace", "$.metadata[", "$.items[-1]", "$..name", "$['name"} {
+		t.Run(expression, func(t *testing.T) {
+			if _, err := CombineConfigurationsBy(map[string]interface{}{}, expression); err == nil {
+				t.Errorf("expected an error for %v", expression)
+			}
+		})
+	}
+}
+
+func TestCombineConfigurationsByParser(t *testing.T) {
+	configurations := map[string]interface{}{
+		"Dockerfile": []interface{}{
+			map[string]interface{}{"Cmd": "from", "Value": []interface{}{"nginx:1.21"}},
+		},
+		"values.yaml": map[string]interface{}{"image": "nginx:1.21"},
+		"values.json": map[string]interface{}{"replicas": float64(2)},
+	}
+
+	combined, err := CombineConfigurationsByParser(configurations, "")
+	if err != nil {
+		t.Fatalf("combine configurations: %v", err)
+	}
+
+	contents, err := json.Marshal(combined["Combined"])
+	if err != nil {
+		t.Fatalf("marshal combined configurations: %v", err)
+	}
+
+	var actual map[string]interface{}
+	if err := json.Unmarshal(contents, &actual); err != nil {
+		t.Fatalf("unmarshal combined configurations: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"dockerfile": []interface{}{
+			map[string]interface{}{
+				"path":     "Dockerfile",
+				"contents": map[string]interface{}{"Cmd": "from", "Value": []interface{}{"nginx:1.21"}},
+			},
+		},
+		"yaml": []interface{}{
+			map[string]interface{}{
+				"path":     "values.yaml",
+				"contents": map[string]interface{}{"image": "nginx:1.21"},
+			},
+		},
+		"json": []interface{}{
+			map[string]interface{}{
+				"path":     "values.json",
+				"contents": map[string]interface{}{"replicas": float64(2)},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected combined configurations. expected %v actual %v", expected, actual)
+	}
+}
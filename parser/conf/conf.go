@@ -0,0 +1,95 @@
+package conf
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/ini"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+)
+
+// Parser is a parser for .conf and .cfg files, which parses the files
+// as either INI or YAML depending on their contents.
+type Parser struct{}
+
+// Unmarshal unmarshals .conf and .cfg files. See IsINI for how
+// the format of the file is determined. Files that are detected as
+// neither format, see Detect, are an error.
+func (c *Parser) Unmarshal(p []byte, v interface{}) error {
+	if !Detect(p) {
+		return errors.New("contents are neither INI nor YAML")
+	}
+
+	if IsINI(p) {
+		if err := new(ini.Parser).Unmarshal(p, v); err != nil {
+			return fmt.Errorf("unmarshal ini: %w", err)
+		}
+
+		return nil
+	}
+
+	if err := new(yaml.Parser).Unmarshal(p, v); err != nil {
+		return fmt.Errorf("unmarshal yaml: %w", err)
+	}
+
+	return nil
+}
+
+// IsINI returns true when the contents look like an INI file rather than a YAML file.
+// Blank lines and lines starting with # are skipped, and the first remaining line
+// determines the format. The contents are INI when that line:
+//
+// - is a section header, such as [server]
+// - is a comment starting with ;
+// - is a key and value separated by =, where the = comes before any :
+//
+// In all other cases, such as key: value, the contents are YAML.
+func IsINI(p []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(p))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			return true
+		}
+
+		if strings.HasPrefix(line, ";") {
+			return true
+		}
+
+		equals := strings.Index(line, "=")
+		colon := strings.Index(line, ":")
+		return equals > 0 && (colon < 0 || equals < colon)
+	}
+
+	return false
+}
+
+// Detect returns true when the contents are either INI or YAML, rather than one of
+// the many other formats that use the .conf and .cfg extensions, such as the
+// configurations of nginx, Apache and HAProxy. Those formats are usually valid YAML
+// that is a single string, so contents are only detected as YAML when they are an
+// object, a list, or empty.
+func Detect(p []byte) bool {
+	if IsINI(p) {
+		return true
+	}
+
+	var v interface{}
+	if err := new(yaml.Parser).Unmarshal(p, &v); err != nil {
+		return false
+	}
+
+	switch v.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,129 @@
+package conf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConfParser(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{
+			name: "ini",
+			input: `; database settings
+[database]
+host = localhost
+port = 5432`,
+			expected: map[string]interface{}{
+				"database": map[string]interface{}{
+					"host": "localhost",
+					"port": 5432.0,
+				},
+			},
+		},
+		{
+			name: "yaml",
+			input: `# database settings
+database:
+  host: localhost
+  url: postgres://localhost:5432/?sslmode=disable`,
+			expected: map[string]interface{}{
+				"database": map[string]interface{}{
+					"host": "localhost",
+					"url":  "postgres://localhost:5432/?sslmode=disable",
+				},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			var actual interface{}
+			if err := new(Parser).Unmarshal([]byte(testCase.input), &actual); err != nil {
+				t.Fatalf("parser should not have thrown an error: %v", err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("Unexpected result. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestIsINI(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected bool
+	}{
+		{input: "[server]\nport = 8080", expected: true},
+		{input: "; comment\nport = 8080", expected: true},
+		{input: "# comment\n\nport = 8080", expected: true},
+		{input: "port: 8080", expected: false},
+		{input: "url: http://localhost/?a=b", expected: false},
+		{input: "---\nserver:\n  port: 8080", expected: false},
+		{input: "", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		actual := IsINI([]byte(testCase.input))
+		if actual != testCase.expected {
+			t.Errorf("Unexpected result for %q. expected %v actual %v", testCase.input, testCase.expected, actual)
+		}
+	}
+}
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{name: "ini", input: "[server]\nport = 8080", expected: true},
+		{name: "yaml", input: "server:\n  port: 8080", expected: true},
+		{name: "empty", input: "# no settings yet\n", expected: true},
+		{
+			name: "nginx",
+			input: `user nginx;
+worker_processes auto;
+
+http {
+    server {
+        listen 80;
+        location / {
+            proxy_pass http://backend;
+        }
+    }
+}`,
+			expected: false,
+		},
+		{
+			name: "haproxy",
+			input: `global
+    maxconn 256
+
+frontend http
+    bind *:80
+    default_backend servers`,
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if actual := Detect([]byte(testCase.input)); actual != testCase.expected {
+				t.Errorf("Unexpected result. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfParserUndetected(t *testing.T) {
+	var actual interface{}
+	err := new(Parser).Unmarshal([]byte("user nginx;\nworker_processes auto;"), &actual)
+	if err == nil {
+		t.Fatal("expected an error for contents that are neither INI nor YAML")
+	}
+}
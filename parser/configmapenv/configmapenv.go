@@ -0,0 +1,75 @@
+package configmapenv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser is a parser for the env files used when generating
+// Kubernetes ConfigMaps (e.g. kubectl create configmap --from-env-file).
+type Parser struct{}
+
+var envVarNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// Unmarshal unmarshals ConfigMap env files.
+//
+// The env file rules are stricter than a generic dotenv file. Keys must be
+// valid environment variable names and values are taken literally, so quoting
+// and variable interpolation are not allowed. All of the lines that break
+// these rules are reported together in the returned error.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	result := make(map[string]string)
+
+	var violations []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			violations = append(violations, fmt.Sprintf("line %d: missing '=' separator", lineNumber))
+			continue
+		}
+
+		key, value := parts[0], parts[1]
+		if !envVarNameRegex.MatchString(key) {
+			violations = append(violations, fmt.Sprintf("line %d: invalid key %q: must be a valid environment variable name", lineNumber, key))
+		}
+
+		if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+			violations = append(violations, fmt.Sprintf("line %d: value for key %q must not be quoted", lineNumber, key))
+		}
+
+		if strings.Contains(value, "${") || strings.Contains(value, "$(") {
+			violations = append(violations, fmt.Sprintf("line %d: value for key %q must not contain interpolation", lineNumber, key))
+		}
+
+		result[key] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan env file: %w", err)
+	}
+
+	if len(violations) > 0 {
+		return fmt.Errorf("invalid configmap env file:\n%s", strings.Join(violations, "\n"))
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal configmap env to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal configmap env json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package configmapenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConfigMapEnvParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# database settings
+DB_HOST=localhost
+DB_PORT=5432
+
+EMPTY=`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	inputMap := input.(map[string]interface{})
+	if inputMap["DB_HOST"] != "localhost" {
+		t.Errorf("unexpected value for DB_HOST: %v", inputMap["DB_HOST"])
+	}
+
+	if inputMap["DB_PORT"] != "5432" {
+		t.Errorf("unexpected value for DB_PORT: %v", inputMap["DB_PORT"])
+	}
+
+	if inputMap["EMPTY"] != "" {
+		t.Errorf("unexpected value for EMPTY: %v", inputMap["EMPTY"])
+	}
+}
+
+func TestConfigMapEnvParserViolations(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sample   string
+		expected string
+	}{
+		{
+			name:     "key containing a dash",
+			sample:   "DB-HOST=localhost",
+			expected: `line 1: invalid key "DB-HOST"`,
+		},
+		{
+			name:     "quoted value",
+			sample:   "# comment\nDB_HOST=\"localhost\"",
+			expected: `line 2: value for key "DB_HOST" must not be quoted`,
+		},
+		{
+			name:     "interpolated value",
+			sample:   "DB_URL=postgres://${DB_HOST}",
+			expected: `line 1: value for key "DB_URL" must not contain interpolation`,
+		},
+		{
+			name:     "missing separator",
+			sample:   "DB_HOST",
+			expected: "line 1: missing '=' separator",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			err := parser.Unmarshal([]byte(testCase.sample), &input)
+			if err == nil {
+				t.Fatal("expected parser to return an error")
+			}
+
+			if !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("unexpected error. expected %q to contain %q", err.Error(), testCase.expected)
+			}
+		})
+	}
+}
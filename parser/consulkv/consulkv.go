@@ -0,0 +1,93 @@
+package consulkv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Separator is the separator between the segments of a path.
+const Separator = "/"
+
+// Parser is a parser for Consul KV and Vault secret exports, which
+// flattens the exported tree into a map of full paths to values.
+type Parser struct{}
+
+// Unmarshal unmarshals Consul KV and Vault secret exports.
+//
+// Two formats are supported. A nested JSON tree, such as a Vault secret export, is
+// flattened so that every value is keyed by its full path, where the keys of the
+// objects along the way are joined by the separator (e.g. app/db/password). Arrays
+// and empty objects are values of their own. The output of consul kv export, a list
+// of objects with a key and a base64 encoded value, is keyed by the key of every
+// entry, and the values are decoded.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return fmt.Errorf("unmarshal consul kv: %w", err)
+	}
+
+	result := make(map[string]interface{})
+	switch tree := tree.(type) {
+	case map[string]interface{}:
+		flatten(result, "", tree)
+	case []interface{}:
+		if err := addExportEntries(result, tree); err != nil {
+			return fmt.Errorf("read consul kv export: %w", err)
+		}
+	default:
+		return fmt.Errorf("consul kv must be an object or a consul kv export list, got %T", tree)
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal consul kv to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal consul kv json: %w", err)
+	}
+
+	return nil
+}
+
+func flatten(result map[string]interface{}, prefix string, tree map[string]interface{}) {
+	for key, value := range tree {
+		path := key
+		if prefix != "" {
+			path = prefix + Separator + key
+		}
+
+		if subtree, ok := value.(map[string]interface{}); ok && len(subtree) > 0 {
+			flatten(result, path, subtree)
+			continue
+		}
+
+		result[path] = value
+	}
+}
+
+func addExportEntries(result map[string]interface{}, entries []interface{}) error {
+	for i, entry := range entries {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("entry %d: expected an object, got %T", i, entry)
+		}
+
+		key, ok := fields["key"].(string)
+		if !ok {
+			return fmt.Errorf("entry %d: missing key", i)
+		}
+
+		// Folders are exported as keys with a trailing separator and no value.
+		value, _ := fields["value"].(string)
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return fmt.Errorf("entry %d: decode value of %v: %w", i, key, err)
+		}
+
+		result[key] = string(decoded)
+	}
+
+	return nil
+}
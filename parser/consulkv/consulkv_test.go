@@ -0,0 +1,76 @@
+package consulkv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConsulKVParser(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected map[string]interface{}
+	}{
+		{
+			name: "nested tree",
+			input: `{
+				"app": {
+					"db": {"password": "hunter2", "port": 5432},
+					"hosts": ["a", "b"],
+					"features": {}
+				}
+			}`,
+			expected: map[string]interface{}{
+				"app/db/password": "hunter2",
+				"app/db/port":     float64(5432),
+				"app/hosts":       []interface{}{"a", "b"},
+				"app/features":    map[string]interface{}{},
+			},
+		},
+		{
+			name: "consul kv export",
+			input: `[
+				{"key": "app/", "flags": 0, "value": ""},
+				{"key": "app/db/password", "flags": 0, "value": "aHVudGVyMg=="}
+			]`,
+			expected: map[string]interface{}{
+				"app/":            "",
+				"app/db/password": "hunter2",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			if err := parser.Unmarshal([]byte(testCase.input), &input); err != nil {
+				t.Fatalf("parser should not have thrown an error: %v", err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, input) {
+				t.Errorf("Unexpected input. expected %v actual %v", testCase.expected, input)
+			}
+		})
+	}
+}
+
+func TestConsulKVParserInvalid(t *testing.T) {
+	testCases := []string{
+		`"app"`,
+		`[{"value": "aHVudGVyMg=="}]`,
+		`[{"key": "app/db/password", "value": "not base64!"}]`,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			if err := parser.Unmarshal([]byte(testCase), &input); err == nil {
+				t.Errorf("expected an error for %v", testCase)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Parser is a CSV parser.
+type Parser struct {
+
+	// NoHeader parses files without a header row, in which case every
+	// row is parsed into a list of its fields.
+	NoHeader bool
+}
+
+// Unmarshal unmarshals CSV files into a list of rows, where every row is an
+// object keyed by the columns of the header row. All of the fields are strings,
+// and every row must have the same number of fields as the header row.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+
+	var header []string
+	rows := []interface{}{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read csv: %w", err)
+		}
+
+		if p.NoHeader {
+			rows = append(rows, record)
+			continue
+		}
+
+		if header == nil {
+			if err := validateHeader(record); err != nil {
+				return fmt.Errorf("header: %w", err)
+			}
+
+			header = record
+			continue
+		}
+
+		row := make(map[string]interface{})
+		for i, column := range header {
+			row[column] = record[i]
+		}
+
+		rows = append(rows, row)
+	}
+
+	j, err := json.Marshal(rows)
+	if err != nil {
+		return fmt.Errorf("marshal csv to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal csv json: %w", err)
+	}
+
+	return nil
+}
+
+// validateHeader checks that the columns of the header row can be used as
+// the keys of the rows, which requires every column to have a unique name.
+func validateHeader(header []string) error {
+	columns := make(map[string]bool)
+	for i, column := range header {
+		if strings.TrimSpace(column) == "" {
+			return fmt.Errorf("column %d has no name, use --csv-no-header for files without a header row", i+1)
+		}
+
+		if columns[column] {
+			return fmt.Errorf("duplicate column %q", column)
+		}
+
+		columns[column] = true
+	}
+
+	return nil
+}
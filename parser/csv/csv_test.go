@@ -0,0 +1,102 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCSVParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `name,region,tags
+web,eu-west-1,"frontend,public"
+db,us-east-1,"say ""hi"""
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"name": "web", "region": "eu-west-1", "tags": "frontend,public"},
+		map[string]interface{}{"name": "db", "region": "us-east-1", "tags": `say "hi"`},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected rows. expected %v actual %v", expected, input)
+	}
+}
+
+func TestCSVParserNoHeader(t *testing.T) {
+	parser := &Parser{NoHeader: true}
+	sample := "web,eu-west-1\ndb,us-east-1\n"
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		[]interface{}{"web", "eu-west-1"},
+		[]interface{}{"db", "us-east-1"},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected rows. expected %v actual %v", expected, input)
+	}
+}
+
+func TestCSVParserEmpty(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(""), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if !reflect.DeepEqual(input, []interface{}{}) {
+		t.Errorf("Unexpected rows. expected [] actual %v", input)
+	}
+}
+
+func TestCSVParserErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		sample   string
+		expected string
+	}{
+		{
+			name:     "inconsistent column count",
+			sample:   "name,region\nweb,eu-west-1\ndb\n",
+			expected: "wrong number of fields",
+		},
+		{
+			name:     "duplicate column",
+			sample:   "name,name\nweb,db\n",
+			expected: `duplicate column "name"`,
+		},
+		{
+			name:     "unnamed column",
+			sample:   "name,\nweb,db\n",
+			expected: "column 2 has no name",
+		},
+		{
+			name:     "unterminated quote",
+			sample:   "name\n\"web\n",
+			expected: "read csv",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			err := parser.Unmarshal([]byte(testCase.sample), &input)
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("Unexpected error. expected %v actual %v", testCase.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package dhall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Parser is a Dhall parser. Dhall files are evaluated to their normal form
+// and converted to JSON with dhall-to-json, which must be installed.
+type Parser struct {
+
+	// Path is the path of the file that is parsed. Relative imports are
+	// resolved from the directory of the file.
+	Path string
+
+	// NoRemote forbids importing expressions from URLs, both in the file
+	// itself and in the local files that it imports.
+	NoRemote bool
+}
+
+// A remote import is a URL that is not part of a text literal.
+var remoteImportRegex = regexp.MustCompile(`(^|[\s(\[{,=:])(https?://[^\s)\]},]+)`)
+
+// A local import is a path to a file, or an environment variable.
+var localImportRegex = regexp.MustCompile(`(^|[\s(\[{,=:])((?:\.\.?|~)?/[^\s)\]},]+|env:[A-Za-z_][A-Za-z0-9_]*)`)
+
+// blockedProxy is an address that cannot be connected to, which is used as the
+// proxy of dhall-to-json so that no remote import can be fetched, even one that
+// is not found when the imports are checked.
+const blockedProxy = "http://127.0.0.1:0"
+
+// Unmarshal unmarshals Dhall files.
+func (d *Parser) Unmarshal(p []byte, v interface{}) error {
+
+	// Without a path, which is the case for standard input, imports are
+	// resolved from the working directory.
+	dir := "."
+	if d.Path != "" && d.Path != "-" {
+		dir = filepath.Dir(d.Path)
+	}
+
+	if d.NoRemote {
+		if err := checkRemoteImports(p, dir, make(map[string]bool)); err != nil {
+			return err
+		}
+	}
+
+	path, err := exec.LookPath("dhall-to-json")
+	if err != nil {
+		return fmt.Errorf("dhall-to-json is required to parse dhall files: %w", err)
+	}
+
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd := exec.Command(path)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(p)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if d.NoRemote {
+		cmd.Env = os.Environ()
+		for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+			cmd.Env = append(cmd.Env, name+"="+blockedProxy)
+		}
+		cmd.Env = append(cmd.Env, "NO_PROXY=", "no_proxy=")
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("evaluate dhall: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("unmarshal dhall json: %w", err)
+	}
+
+	return nil
+}
+
+// checkRemoteImports returns an error when the expression imports an expression from a
+// URL, either directly or through the local files and environment variables that it
+// imports. Relative imports are resolved from the given directory.
+func checkRemoteImports(p []byte, dir string, seen map[string]bool) error {
+	code := withoutLiterals(p)
+	if match := remoteImportRegex.FindSubmatch(code); match != nil {
+		return fmt.Errorf("remote import of %s is not allowed", match[2])
+	}
+
+	for _, match := range localImportRegex.FindAllSubmatch(code, -1) {
+		location := string(match[2])
+
+		var contents []byte
+		importDir := dir
+		if strings.HasPrefix(location, "env:") {
+			contents = []byte(os.Getenv(strings.TrimPrefix(location, "env:")))
+		} else {
+			path := location
+			if strings.HasPrefix(path, "~") {
+				home, err := os.UserHomeDir()
+				if err != nil {
+					return fmt.Errorf("get home directory: %w", err)
+				}
+
+				path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+			} else if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+
+			// A file that cannot be read is reported when dhall-to-json evaluates the imports.
+			fileContents, err := ioutil.ReadFile(path)
+			if err != nil {
+				continue
+			}
+
+			contents = fileContents
+			importDir = filepath.Dir(path)
+		}
+
+		if err := checkRemoteImports(contents, importDir, seen); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withoutLiterals returns the source with the text literals and comments replaced by
+// spaces, so that URLs and paths inside of them are not mistaken for imports. Line
+// breaks are kept, so that the lines of the source do not change.
+func withoutLiterals(p []byte) []byte {
+	code := make([]byte, len(p))
+	copy(code, p)
+
+	blank := func(start int, end int) {
+		for i := start; i < end && i < len(code); i++ {
+			if code[i] != '\n' {
+				code[i] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(code); i++ {
+		switch {
+		case code[i] == '"':
+			end := i + 1
+			for end < len(code) && code[end] != '"' {
+				if code[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			blank(i, end+1)
+			i = end
+		case bytes.HasPrefix(code[i:], []byte("''")):
+			end := bytes.Index(code[i+2:], []byte("''"))
+			if end < 0 {
+				end = len(code)
+			} else {
+				end += i + 4
+			}
+			blank(i, end)
+			i = end - 1
+		case bytes.HasPrefix(code[i:], []byte("--")):
+			end := bytes.IndexByte(code[i:], '\n')
+			if end < 0 {
+				end = len(code) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+		case bytes.HasPrefix(code[i:], []byte("{-")):
+			end := bytes.Index(code[i:], []byte("-}"))
+			if end < 0 {
+				end = len(code) - i - 2
+			}
+			blank(i, i+end+2)
+			i += end + 1
+		}
+	}
+
+	return code
+}
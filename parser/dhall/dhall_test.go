@@ -0,0 +1,149 @@
+package dhall
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDhallParser(t *testing.T) {
+	if _, err := exec.LookPath("dhall-to-json"); err != nil {
+		t.Skip("dhall-to-json is not installed")
+	}
+
+	sample := `let port = 8080
+
+let Server = { port : Natural, tls : Bool, ca : Optional Text }
+
+in  { name = "api"
+    , server = { port = port, tls = True, ca = None Text } : Server
+    , labels = toMap { team = "platform" }
+    , replicas = [ +1, -1 ]
+    }`
+
+	var input interface{}
+	if err := new(Parser).Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name": "api",
+		"server": map[string]interface{}{
+			"port": 8080.0,
+			"tls":  true,
+			"ca":   nil,
+		},
+		"labels": map[string]interface{}{
+			"team": "platform",
+		},
+		"replicas": []interface{}{1.0, -1.0},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected result. expected %v actual %v", expected, input)
+	}
+}
+
+func TestDhallParserRelativeImports(t *testing.T) {
+	if _, err := exec.LookPath("dhall-to-json"); err != nil {
+		t.Skip("dhall-to-json is not installed")
+	}
+
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "app", "ports.dhall"), `{ http = 8080 }`)
+	writeFile(t, filepath.Join(root, "app", "config.dhall"), `{ ports = ./ports.dhall }`)
+
+	path := filepath.Join(root, "app", "config.dhall")
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read file: %v", err)
+	}
+
+	var input interface{}
+	if err := (&Parser{Path: path}).Unmarshal(contents, &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"ports": map[string]interface{}{"http": 8080.0},
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected result. expected %v actual %v", expected, input)
+	}
+}
+
+func TestDhallParserNoRemote(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "prelude.dhall"), `https://prelude.dhall-lang.org/v20.1.0/package.dhall`)
+
+	testCases := []struct {
+		desc     string
+		contents string
+		expected string
+	}{
+		{
+			desc: "direct import",
+			contents: `-- Documentation: https://docs.dhall-lang.org
+let description = "see https://example.com"
+let Prelude = https://prelude.dhall-lang.org/v20.1.0/package.dhall
+
+in  { description = description }`,
+			expected: "remote import of https://prelude.dhall-lang.org/v20.1.0/package.dhall is not allowed",
+		},
+		{
+			desc:     "import through a local file",
+			contents: `let Prelude = ./prelude.dhall in { name = "api" }`,
+			expected: "remote import of https://prelude.dhall-lang.org/v20.1.0/package.dhall is not allowed",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path := filepath.Join(root, "config.dhall")
+
+			var input interface{}
+			err := (&Parser{Path: path, NoRemote: true}).Unmarshal([]byte(tc.contents), &input)
+			if err == nil {
+				t.Fatal("expected an error for a remote import")
+			}
+
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Unexpected error. expected %q, got %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestCheckRemoteImports(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, filepath.Join(root, "lib", "ports.dhall"), `-- see https://example.com
+{ http = 8080, docs = "https://example.com" }`)
+
+	sample := `{- Documentation: https://docs.dhall-lang.org -}
+let ports = ./lib/ports.dhall
+
+in  { ports = ports, description = ''
+      see https://example.com
+      '' }`
+
+	if err := checkRemoteImports([]byte(sample), root, make(map[string]bool)); err != nil {
+		t.Errorf("URLs in comments and text literals should be allowed: %v", err)
+	}
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		t.Fatalf("create directory: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+}
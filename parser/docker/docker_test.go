@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -68,3 +69,42 @@ RUN go build -o conftest`
 		t.Errorf("expected command to be in stage 1, not stage: %v", stage)
 	}
 }
+
+func TestParser_Unmarshal_Continuation(t *testing.T) {
+	parser := Parser{}
+
+	sample := `FROM alpine:3.11
+RUN apk add --no-cache \
+    # the client is used by the health check
+    curl \
+    git
+USER nobody # not a comment
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	commands := input.([]interface{})[0].([]interface{})
+
+	var cmds []string
+	for _, command := range commands {
+		cmds = append(cmds, command.(map[string]interface{})["Cmd"].(string))
+	}
+
+	expected := []string{"from", "run", "user"}
+	if strings.Join(cmds, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Unexpected commands. expected %v actual %v", expected, cmds)
+	}
+
+	run := commands[1].(map[string]interface{})["Value"].([]interface{})
+	if len(run) != 1 || !strings.Contains(run[0].(string), "curl") || !strings.Contains(run[0].(string), "git") || strings.Contains(run[0].(string), "health check") {
+		t.Errorf("Unexpected value of the folded RUN command. actual %v", run)
+	}
+
+	user := commands[2].(map[string]interface{})["Value"].([]interface{})
+	if len(user) != 1 || user[0] != "nobody # not a comment" {
+		t.Errorf("Unexpected value of the USER command. actual %v", user)
+	}
+}
@@ -0,0 +1,166 @@
+package gemfilelock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser is a parser for Gemfile.lock files, the lockfiles of Bundler.
+type Parser struct{}
+
+// Gem is a single gem that is locked by a Gemfile.lock file.
+type Gem struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Platform is the platform of a gem that is specific to a platform,
+	// e.g. x86_64-linux for nokogiri (1.13.1-x86_64-linux).
+	Platform string `json:"platform"`
+
+	// Source is the kind of source that the gem is installed from, which
+	// is gem for a gem server, git for a git repository or path for a
+	// directory, and Remote is the location of that source.
+	Source   string `json:"source"`
+	Remote   string `json:"remote"`
+	Revision string `json:"revision,omitempty"`
+
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Dependency is a gem that a locked gem depends on.
+type Dependency struct {
+	Name        string `json:"name"`
+	Requirement string `json:"requirement"`
+}
+
+// The sections that list the gems of a source, such as GEM, and whose
+// name is used as the source of their gems.
+var sourceSections = map[string]string{
+	"GEM":           "gem",
+	"GIT":           "git",
+	"PATH":          "path",
+	"PLUGIN SOURCE": "plugin",
+}
+
+// A spec is a name followed by an optional version or requirement in
+// parentheses, e.g. rack (2.2.3) or rack (>= 2.0, < 3).
+var specRegex = regexp.MustCompile(`^(\S+)(?: \(([^)]*)\))?$`)
+
+// Unmarshal unmarshals Gemfile.lock files.
+//
+// The gems of every source section (GEM, GIT, PATH and PLUGIN SOURCE) are returned as
+// a single list, in the order in which they appear. Every gem has its name, version
+// and platform, the source and remote that it is installed from, and the gems that
+// it depends on along with their requirements. The other sections, such as PLATFORMS
+// and DEPENDENCIES, are not included.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	gems := []Gem{}
+
+	var source, remote, revision string
+	var inSpecs bool
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimRight(scanner.Text(), " \r")
+		if line == "" {
+			continue
+		}
+
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		content := strings.TrimSpace(line)
+
+		// A line without indentation starts a new section.
+		if indent == 0 {
+			source = sourceSections[content]
+			remote, revision = "", ""
+			inSpecs = false
+			continue
+		}
+
+		if source == "" {
+			continue
+		}
+
+		switch indent {
+		case 2:
+			inSpecs = content == "specs:"
+			if value := strings.TrimPrefix(content, "remote: "); value != content {
+				remote = value
+			}
+
+			if value := strings.TrimPrefix(content, "revision: "); value != content {
+				revision = value
+			}
+
+		case 4:
+			if !inSpecs {
+				continue
+			}
+
+			match := specRegex.FindStringSubmatch(content)
+			if match == nil {
+				return fmt.Errorf("invalid gem on line %d: %s", number, content)
+			}
+
+			version, platform := splitPlatform(match[2])
+			gem := Gem{
+				Name:         match[1],
+				Version:      version,
+				Platform:     platform,
+				Source:       source,
+				Remote:       remote,
+				Revision:     revision,
+				Dependencies: []Dependency{},
+			}
+
+			gems = append(gems, gem)
+
+		case 6:
+			if !inSpecs {
+				continue
+			}
+
+			if len(gems) == 0 {
+				return fmt.Errorf("dependency without a gem on line %d: %s", number, content)
+			}
+
+			match := specRegex.FindStringSubmatch(content)
+			if match == nil {
+				return fmt.Errorf("invalid dependency on line %d: %s", number, content)
+			}
+
+			last := &gems[len(gems)-1]
+			last.Dependencies = append(last.Dependencies, Dependency{Name: match[1], Requirement: match[2]})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan lockfile: %w", err)
+	}
+
+	j, err := json.Marshal(gems)
+	if err != nil {
+		return fmt.Errorf("marshal gems to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal gems json: %w", err)
+	}
+
+	return nil
+}
+
+// splitPlatform splits the version of a gem that is specific to a platform,
+// e.g. 1.13.1-x86_64-linux, into the version and the platform. RubyGems
+// versions cannot contain a dash, so the platform starts at the first one.
+func splitPlatform(version string) (string, string) {
+	parts := strings.SplitN(version, "-", 2)
+	if len(parts) == 1 {
+		return version, ""
+	}
+
+	return parts[0], parts[1]
+}
@@ -0,0 +1,83 @@
+package gemfilelock
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGemfileLockParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `GIT
+  remote: https://github.com/rails/rails.git
+  revision: 8f1b3c5e0f2a9d7b6c4e1a0f9d8c7b6a5e4d3c2b
+  specs:
+    activesupport (7.1.0.alpha)
+
+GEM
+  remote: https://rubygems.org/
+  specs:
+    nokogiri (1.13.1-x86_64-linux)
+      racc (~> 1.4)
+    rack (2.2.3)
+
+PLATFORMS
+  x86_64-linux
+
+DEPENDENCIES
+  activesupport!
+  nokogiri
+  rack (>= 2.0, < 3)
+
+BUNDLED WITH
+   2.3.5
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"name":         "activesupport",
+			"version":      "7.1.0.alpha",
+			"platform":     "",
+			"source":       "git",
+			"remote":       "https://github.com/rails/rails.git",
+			"revision":     "8f1b3c5e0f2a9d7b6c4e1a0f9d8c7b6a5e4d3c2b",
+			"dependencies": []interface{}{},
+		},
+		map[string]interface{}{
+			"name":     "nokogiri",
+			"version":  "1.13.1",
+			"platform": "x86_64-linux",
+			"source":   "gem",
+			"remote":   "https://rubygems.org/",
+			"dependencies": []interface{}{
+				map[string]interface{}{"name": "racc", "requirement": "~> 1.4"},
+			},
+		},
+		map[string]interface{}{
+			"name":         "rack",
+			"version":      "2.2.3",
+			"platform":     "",
+			"source":       "gem",
+			"remote":       "https://rubygems.org/",
+			"dependencies": []interface{}{},
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected gems. expected %v actual %v", expected, input)
+	}
+}
+
+func TestGemfileLockParserInvalidGem(t *testing.T) {
+	parser := &Parser{}
+	sample := "GEM\n  remote: https://rubygems.org/\n  specs:\n    rack 2.2.3\n"
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+		t.Error("expected an error for a gem without parentheses around its version")
+	}
+}
@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The defined Helm file types are the types of files
+// that are found when scanning a Helm chart.
+const (
+	HelmValues    = "values"
+	HelmManifests = "manifests"
+)
+
+var helmValuesFileRegex = regexp.MustCompile(`^values([.-][^/]*)?\.ya?ml$`)
+
+// HelmFileType returns the type of Helm file of the configuration at the given path:
+//
+//   - HelmValues for chart values files, which are named values.yaml, or have a
+//     values. or values- prefix (e.g. values-production.yaml), and are not a
+//     Kubernetes resource.
+//   - HelmManifests for rendered manifests, where every document is a Kubernetes
+//     resource with an apiVersion and a kind.
+//
+// An empty string is returned for all other configurations.
+func HelmFileType(path string, config interface{}) string {
+	documents, ok := config.([]interface{})
+	if !ok {
+		documents = []interface{}{config}
+	}
+
+	var resources int
+	var nonEmpty int
+	for _, document := range documents {
+		if document == nil {
+			continue
+		}
+
+		nonEmpty++
+		if isKubernetesResource(document) {
+			resources++
+		}
+	}
+
+	if helmValuesFileRegex.MatchString(strings.ToLower(filepath.Base(path))) && resources == 0 {
+		return HelmValues
+	}
+
+	if nonEmpty > 0 && resources == nonEmpty {
+		return HelmManifests
+	}
+
+	return ""
+}
+
+func isKubernetesResource(document interface{}) bool {
+	object, ok := document.(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	_, hasAPIVersion := object["apiVersion"]
+	_, hasKind := object["kind"]
+	return hasAPIVersion && hasKind
+}
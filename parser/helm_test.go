@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestHelmFileType(t *testing.T) {
+	deployment := map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment"}
+	service := map[string]interface{}{"apiVersion": "v1", "kind": "Service"}
+	values := map[string]interface{}{"replicaCount": 1, "image": map[string]interface{}{"tag": "latest"}}
+
+	testCases := []struct {
+		path     string
+		config   interface{}
+		expected string
+	}{
+		{path: "chart/values.yaml", config: values, expected: HelmValues},
+		{path: "chart/values-production.yml", config: values, expected: HelmValues},
+		{path: "chart/Values.prod.yaml", config: values, expected: HelmValues},
+		{path: "chart/values.yaml", config: nil, expected: HelmValues},
+		{path: "rendered/deployment.yaml", config: deployment, expected: HelmManifests},
+		{path: "rendered/all.yaml", config: []interface{}{deployment, nil, service}, expected: HelmManifests},
+		{path: "chart/values.yaml", config: deployment, expected: HelmManifests},
+		{path: "rendered/mixed.yaml", config: []interface{}{deployment, values}, expected: ""},
+		{path: "chart/Chart.yaml", config: map[string]interface{}{"apiVersion": "v2", "name": "chart"}, expected: ""},
+		{path: "chart/myvalues.yaml", config: values, expected: ""},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.path, func(t *testing.T) {
+			actual := HelmFileType(testCase.path, testCase.config)
+			if actual != testCase.expected {
+				t.Errorf("Unexpected Helm file type. expected %q actual %q", testCase.expected, actual)
+			}
+		})
+	}
+}
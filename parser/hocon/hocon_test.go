@@ -32,3 +32,60 @@ func TestHoconUnmarshal(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestHoconSubstitutions(t *testing.T) {
+	parser := &Parser{}
+	sample := `app {
+	name = "demo"
+	timeout = 10s
+	memory = 512M
+	server {
+		port = 8080
+	}
+}
+
+service {
+	name = ${app.name}
+	port = ${app.server.port}
+}`
+
+	var input map[string]interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	app, ok := input["app"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Unexpected app. expected an object actual %v", input["app"])
+	}
+
+	server, ok := app["server"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Unexpected server. expected a nested object actual %v", app["server"])
+	}
+
+	if server["port"] != float64(8080) {
+		t.Errorf("Unexpected port. expected %v actual %v", 8080, server["port"])
+	}
+
+	if app["timeout"] != "10s" {
+		t.Errorf("Unexpected timeout. expected %v actual %v", "10s", app["timeout"])
+	}
+
+	if app["memory"] != "512M" {
+		t.Errorf("Unexpected memory. expected %v actual %v", "512M", app["memory"])
+	}
+
+	service, ok := input["service"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Unexpected service. expected an object actual %v", input["service"])
+	}
+
+	if service["name"] != "demo" {
+		t.Errorf("Unexpected substitution. expected %v actual %v", "demo", service["name"])
+	}
+
+	if service["port"] != float64(8080) {
+		t.Errorf("Unexpected substitution. expected %v actual %v", 8080, service["port"])
+	}
+}
@@ -0,0 +1,113 @@
+package iam
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Parser is an AWS IAM policy document parser.
+type Parser struct{}
+
+// The statement fields that can either contain a single string or a list of strings.
+var listFields = []string{"Action", "NotAction", "Resource", "NotResource"}
+
+// The statement fields that can contain a principal.
+var principalFields = []string{"Principal", "NotPrincipal"}
+
+// Unmarshal unmarshals AWS IAM policy documents.
+//
+// IAM allows a number of fields to be written either as a single value or as
+// a list of values. To give policies a uniform shape to reason over, the policy
+// document is normalized so that:
+//
+//   - Statement is always a list of statements.
+//   - Action, NotAction, Resource and NotResource are always lists.
+//   - Principal and NotPrincipal are always a map of principal type to a list
+//     of principals, where the "*" wildcard is expanded to {"AWS": ["*"]}.
+//
+// Condition blocks are left untouched.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("unmarshal iam policy: %w", err)
+	}
+
+	if statement, ok := document["Statement"]; ok {
+		statements, err := normalizeStatements(statement)
+		if err != nil {
+			return fmt.Errorf("normalize statements: %w", err)
+		}
+
+		document["Statement"] = statements
+	}
+
+	j, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("marshal iam policy to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal iam policy json: %w", err)
+	}
+
+	return nil
+}
+
+func normalizeStatements(statement interface{}) ([]interface{}, error) {
+	statements := toList(statement)
+	for i, s := range statements {
+		current, ok := s.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("statement %d is not an object", i)
+		}
+
+		for _, field := range listFields {
+			if value, ok := current[field]; ok {
+				current[field] = toList(value)
+			}
+		}
+
+		for _, field := range principalFields {
+			if value, ok := current[field]; ok {
+				principal, err := normalizePrincipal(value)
+				if err != nil {
+					return nil, fmt.Errorf("statement %d: %w", i, err)
+				}
+
+				current[field] = principal
+			}
+		}
+	}
+
+	return statements, nil
+}
+
+func normalizePrincipal(principal interface{}) (map[string]interface{}, error) {
+	switch value := principal.(type) {
+	case string:
+		if value != "*" {
+			return nil, fmt.Errorf("invalid principal %q", value)
+		}
+
+		return map[string]interface{}{"AWS": []interface{}{"*"}}, nil
+
+	case map[string]interface{}:
+		normalized := make(map[string]interface{})
+		for principalType, principals := range value {
+			normalized[principalType] = toList(principals)
+		}
+
+		return normalized, nil
+
+	default:
+		return nil, fmt.Errorf("invalid principal %v", value)
+	}
+}
+
+func toList(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+
+	return []interface{}{value}
+}
@@ -0,0 +1,78 @@
+package iam
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIAMParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+	"Version": "2012-10-17",
+	"Statement": [
+		{
+			"Effect": "Allow",
+			"Action": "s3:GetObject",
+			"Resource": "arn:aws:s3:::bucket/*",
+			"Principal": "*",
+			"Condition": {"Bool": {"aws:SecureTransport": "true"}}
+		},
+		{
+			"Effect": "Deny",
+			"Action": ["s3:DeleteObject", "s3:PutObject"],
+			"Resource": ["arn:aws:s3:::bucket/*"],
+			"Principal": {"AWS": "arn:aws:iam::123456789012:root"}
+		}
+	]
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	statements := input.(map[string]interface{})["Statement"].([]interface{})
+	if len(statements) != 2 {
+		t.Fatalf("expected 2 statements, got %d", len(statements))
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"Effect":    "Allow",
+			"Action":    []interface{}{"s3:GetObject"},
+			"Resource":  []interface{}{"arn:aws:s3:::bucket/*"},
+			"Principal": map[string]interface{}{"AWS": []interface{}{"*"}},
+			"Condition": map[string]interface{}{"Bool": map[string]interface{}{"aws:SecureTransport": "true"}},
+		},
+		map[string]interface{}{
+			"Effect":    "Deny",
+			"Action":    []interface{}{"s3:DeleteObject", "s3:PutObject"},
+			"Resource":  []interface{}{"arn:aws:s3:::bucket/*"},
+			"Principal": map[string]interface{}{"AWS": []interface{}{"arn:aws:iam::123456789012:root"}},
+		},
+	}
+
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Unexpected statements. expected %v actual %v", expected, statements)
+	}
+}
+
+func TestIAMParserSingleStatement(t *testing.T) {
+	parser := &Parser{}
+	sample := `{"Statement": {"Effect": "Allow", "NotAction": "iam:*", "Resource": "*"}}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	statements := input.(map[string]interface{})["Statement"].([]interface{})
+	if len(statements) != 1 {
+		t.Fatalf("expected 1 statement, got %d", len(statements))
+	}
+
+	notAction := statements[0].(map[string]interface{})["NotAction"]
+	if !reflect.DeepEqual(notAction, []interface{}{"iam:*"}) {
+		t.Errorf("Unexpected NotAction. expected %v actual %v", []interface{}{"iam:*"}, notAction)
+	}
+}
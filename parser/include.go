@@ -0,0 +1,356 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/open-policy-agent/conftest/parser/json"
+	"github.com/open-policy-agent/conftest/parser/yaml"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// maxIncludeDepth is the maximum number of nested includes, which stops
+// includes that do not form a cycle, but never end, such as generated files.
+const maxIncludeDepth = 32
+
+// includeTag is the tag of the YAML values that include a file, such as
+// !include common.yaml.
+const includeTag = "!include"
+
+// includeResolver follows the includes of a file, and keeps track of
+// the files that are being included to detect include cycles.
+type includeResolver struct {
+	allowRemote bool
+	client      *http.Client
+	stack       []string
+}
+
+// resolveIncludes returns the configuration with the includes that it contains
+// replaced by the contents of the files that they refer to. Includes are objects
+// with a $ref key, such as {"$ref": "common.json#/definitions/labels"}, where the
+// file is relative to the file that contains the include. References within the
+// same file, which start with #, are left as they are.
+func resolveIncludes(path string, config interface{}, allowRemote bool) (interface{}, error) {
+	resolver := includeResolver{
+		allowRemote: allowRemote,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		stack:       []string{filepath.Clean(path)},
+	}
+
+	return resolver.resolve(config, path)
+}
+
+// supportsIncludes returns whether includes are resolved for files of the
+// given parser, which are the formats that have a convention for includes.
+func supportsIncludes(fileParser Parser) bool {
+	switch fileParser.(type) {
+	case *yaml.Parser, *json.Parser:
+		return true
+	default:
+		return false
+	}
+}
+
+// rewriteIncludeTags replaces the !include tags of YAML files with $ref objects,
+// so that they are resolved in the same way as the includes of JSON files. The
+// tags would otherwise be dropped by the YAML parser, which keeps only the path.
+//
+// The tags are replaced in the parsed documents, so strings that only contain
+// the text !include are left as they are. The documents are only encoded again
+// when they contain tags, otherwise the contents are returned unchanged.
+func rewriteIncludeTags(contents []byte) ([]byte, error) {
+	var documents []*yamlv3.Node
+	var replaced bool
+	decoder := yamlv3.NewDecoder(bytes.NewReader(contents))
+	for {
+		var document yamlv3.Node
+		err := decoder.Decode(&document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode yaml: %w", err)
+		}
+
+		if replaceIncludeTags(&document) {
+			replaced = true
+		}
+
+		documents = append(documents, &document)
+	}
+
+	if !replaced {
+		return contents, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	for _, document := range documents {
+		if err := encoder.Encode(document); err != nil {
+			return nil, fmt.Errorf("encode yaml: %w", err)
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("encode yaml: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// replaceIncludeTags replaces the scalars tagged with !include in the given node
+// and its children with a $ref object, and returns whether any were replaced.
+func replaceIncludeTags(node *yamlv3.Node) bool {
+	if node.Kind == yamlv3.ScalarNode && node.Tag == includeTag {
+		*node = yamlv3.Node{
+			Kind:  yamlv3.MappingNode,
+			Tag:   "!!map",
+			Style: yamlv3.FlowStyle,
+			Content: []*yamlv3.Node{
+				{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: "$ref"},
+				{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: node.Value},
+			},
+		}
+
+		return true
+	}
+
+	var replaced bool
+	for _, child := range node.Content {
+		if replaceIncludeTags(child) {
+			replaced = true
+		}
+	}
+
+	return replaced
+}
+
+func (r *includeResolver) resolve(value interface{}, base string) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok && !strings.HasPrefix(ref, "#") {
+			included, err := r.include(ref, base)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(v) == 1 {
+				return included, nil
+			}
+
+			// The other keys of an include override the keys of the included
+			// object, so that an included file can be used as a template.
+			object, ok := included.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("include %q has other keys, so it must refer to an object", ref)
+			}
+
+			merged := make(map[string]interface{})
+			for key, child := range object {
+				merged[key] = child
+			}
+
+			for key, child := range v {
+				if key == "$ref" {
+					continue
+				}
+
+				resolved, err := r.resolve(child, base)
+				if err != nil {
+					return nil, err
+				}
+
+				merged[key] = resolved
+			}
+
+			return merged, nil
+		}
+
+		for key, child := range v {
+			resolved, err := r.resolve(child, base)
+			if err != nil {
+				return nil, err
+			}
+
+			v[key] = resolved
+		}
+
+		return v, nil
+
+	case []interface{}:
+		for i, child := range v {
+			resolved, err := r.resolve(child, base)
+			if err != nil {
+				return nil, err
+			}
+
+			v[i] = resolved
+		}
+
+		return v, nil
+
+	default:
+		return value, nil
+	}
+}
+
+// include returns the resolved contents of the file that the reference refers to,
+// or the part of the file that the JSON Pointer after the # of the reference
+// refers to, such as common.json#/definitions/labels.
+func (r *includeResolver) include(ref string, base string) (interface{}, error) {
+	location := ref
+	var fragment string
+	if i := strings.Index(ref, "#"); i >= 0 {
+		location, fragment = ref[:i], ref[i+1:]
+	}
+
+	location, remote, err := r.locate(location, base)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", ref, err)
+	}
+
+	for _, including := range r.stack {
+		if including == location {
+			return nil, fmt.Errorf("include cycle: %s -> %s", strings.Join(r.stack, " -> "), location)
+		}
+	}
+
+	if len(r.stack) > maxIncludeDepth {
+		return nil, fmt.Errorf("include %q: more than %d nested includes", ref, maxIncludeDepth)
+	}
+
+	var contents []byte
+	var fileParser Parser
+	if remote {
+		contents, err = r.fetch(location)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", ref, err)
+		}
+
+		parsedURL, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: parse url: %w", ref, err)
+		}
+
+		fileParser, err = NewFromPath(parsedURL.Path)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", ref, err)
+		}
+	} else {
+		contents, err = getConfigurationContent(location)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", ref, err)
+		}
+
+		fileParser, err = NewFromPath(location)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", ref, err)
+		}
+	}
+
+	if _, ok := fileParser.(*yaml.Parser); ok {
+		contents, err = rewriteIncludeTags(contents)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", ref, err)
+		}
+	}
+
+	var included interface{}
+	if err := fileParser.Unmarshal(contents, &included); err != nil {
+		return nil, fmt.Errorf("include %q: parse: %w", ref, err)
+	}
+
+	if supportsIncludes(fileParser) {
+		r.stack = append(r.stack, location)
+		included, err = r.resolve(included, location)
+		r.stack = r.stack[:len(r.stack)-1]
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pointer, err := parsePointer(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", ref, err)
+	}
+
+	value, err := pointerGet(included, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("include %q: %w", ref, err)
+	}
+
+	return value, nil
+}
+
+// locate returns the location of the included file, which is relative to the
+// file that includes it, and whether the file has to be fetched from a URL.
+func (r *includeResolver) locate(location string, base string) (string, bool, error) {
+	if location == "" {
+		return "", false, fmt.Errorf("missing file")
+	}
+
+	baseURL, err := url.Parse(base)
+	baseRemote := err == nil && (baseURL.Scheme == "http" || baseURL.Scheme == "https")
+
+	locationURL, err := url.Parse(location)
+	if err == nil && locationURL.Scheme != "" && !filepath.IsAbs(location) {
+		if locationURL.Scheme != "http" && locationURL.Scheme != "https" {
+			return "", false, fmt.Errorf("unsupported scheme %q", locationURL.Scheme)
+		}
+
+		if !r.allowRemote {
+			return "", false, fmt.Errorf("remote includes are not allowed, use --allow-remote-includes to allow them")
+		}
+
+		return location, true, nil
+	}
+
+	// Relative includes of a file that was fetched from a URL are
+	// fetched from the same server.
+	if baseRemote {
+		if !r.allowRemote {
+			return "", false, fmt.Errorf("remote includes are not allowed, use --allow-remote-includes to allow them")
+		}
+
+		resolved := *baseURL
+		resolved.Path = path.Join(path.Dir(baseURL.Path), filepath.ToSlash(location))
+		resolved.RawQuery = ""
+		return resolved.String(), true, nil
+	}
+
+	if !filepath.IsAbs(location) {
+		location = filepath.Join(filepath.Dir(base), location)
+	}
+
+	return filepath.Clean(location), false, nil
+}
+
+// fetch returns the contents of the file at the given URL.
+func (r *includeResolver) fetch(location string) ([]byte, error) {
+	response, err := r.client.Get(location)
+	if err != nil {
+		return nil, fmt.Errorf("fetch: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch: unexpected status %s", response.Status)
+	}
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	return contents, nil
+}
@@ -0,0 +1,184 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeIncludeFiles(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(contents), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestResolveIncludes(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"deployment.json":       `{"metadata": {"$ref": "common/metadata.json", "name": "web"}, "spec": {"$ref": "common/spec.yaml#/spec"}, "schema": {"$ref": "#/definitions/spec"}}`,
+		"common/metadata.json":  `{"name": "default", "labels": {"$ref": "labels.json"}}`,
+		"common/labels.json":    `{"team": "platform"}`,
+		"common/spec.yaml":      "spec:\n  replicas: 3\n  resources: !include resources.yaml\n",
+		"common/resources.yaml": "limits:\n  cpu: 500m\n",
+	})
+
+	file := filepath.Join(dir, "deployment.json")
+	configurations, err := ParseConfigurationsWithOptions([]string{file}, "", Options{ResolveIncludes: true})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"team": "platform"},
+		},
+		"spec": map[string]interface{}{
+			"replicas":  float64(3),
+			"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": "500m"}},
+		},
+		"schema": map[string]interface{}{"$ref": "#/definitions/spec"},
+	}
+
+	if !reflect.DeepEqual(expected, configurations[file]) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[file])
+	}
+}
+
+func TestResolveIncludeTagsKeepsStrings(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"config.yaml": "msg: \"use !include foo\"\nhelp: |\n  write !include common.yaml\n  to include a file\nlabels: !include labels.yaml\n---\nname: second\n",
+		"labels.yaml": "team: platform\n",
+	})
+
+	file := filepath.Join(dir, "config.yaml")
+	configurations, err := ParseConfigurationsWithOptions([]string{file}, "", Options{ResolveIncludes: true})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"msg":    "use !include foo",
+			"help":   "write !include common.yaml\nto include a file\n",
+			"labels": map[string]interface{}{"team": "platform"},
+		},
+		map[string]interface{}{"name": "second"},
+	}
+
+	if !reflect.DeepEqual(expected, configurations[file]) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[file])
+	}
+}
+
+func TestResolveIncludesDisabled(t *testing.T) {
+	dir := writeIncludeFiles(t, map[string]string{
+		"config.json": `{"labels": {"$ref": "labels.json"}}`,
+	})
+
+	file := filepath.Join(dir, "config.json")
+	configurations, err := ParseConfigurations([]string{file})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	expected := map[string]interface{}{"labels": map[string]interface{}{"$ref": "labels.json"}}
+	if !reflect.DeepEqual(expected, configurations[file]) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[file])
+	}
+}
+
+func TestResolveIncludesErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		expected string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"config.json": `{"a": {"$ref": "a.yaml"}}`,
+				"a.yaml":      "b: !include b.yaml\n",
+				"b.yaml":      "config: !include config.json\n",
+			},
+			expected: "include cycle",
+		},
+		{
+			name: "missing file",
+			files: map[string]string{
+				"config.json": `{"a": {"$ref": "missing.json"}}`,
+			},
+			expected: `include "missing.json"`,
+		},
+		{
+			name: "missing pointer",
+			files: map[string]string{
+				"config.json": `{"a": {"$ref": "a.json#/missing"}}`,
+				"a.json":      `{"b": true}`,
+			},
+			expected: `key "missing" not found`,
+		},
+		{
+			name: "remote",
+			files: map[string]string{
+				"config.json": `{"a": {"$ref": "https://example.com/a.json"}}`,
+			},
+			expected: "remote includes are not allowed",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := writeIncludeFiles(t, testCase.files)
+
+			_, err := ParseConfigurationsWithOptions([]string{filepath.Join(dir, "config.json")}, "", Options{ResolveIncludes: true})
+			if err == nil || !strings.Contains(err.Error(), testCase.expected) {
+				t.Errorf("Unexpected error. expected %v actual %v", testCase.expected, err)
+			}
+		})
+	}
+}
+
+func TestResolveRemoteIncludes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/shared/labels.json":
+			fmt.Fprint(w, `{"team": {"$ref": "team.json"}}`)
+		case "/shared/team.json":
+			fmt.Fprint(w, `"platform"`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := writeIncludeFiles(t, map[string]string{
+		"config.json": fmt.Sprintf(`{"labels": {"$ref": "%s/shared/labels.json"}}`, server.URL),
+	})
+
+	file := filepath.Join(dir, "config.json")
+	configurations, err := ParseConfigurationsWithOptions([]string{file}, "", Options{ResolveIncludes: true, RemoteIncludes: true})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	expected := map[string]interface{}{"labels": map[string]interface{}{"team": "platform"}}
+	if !reflect.DeepEqual(expected, configurations[file]) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[file])
+	}
+}
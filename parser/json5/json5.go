@@ -0,0 +1,33 @@
+package json5
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/yosuke-furukawa/json5/encoding/json5"
+)
+
+// Parser is a JSON5 parser.
+type Parser struct{}
+
+// Unmarshal unmarshals JSON5 files.
+//
+// Infinity and NaN are valid JSON5 numbers, but cannot be represented
+// as JSON, so they result in an error.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var value interface{}
+	if err := json5.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("unmarshal json5: %w", err)
+	}
+
+	j, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal json5 to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal json5 json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package json5
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestJSON5Parser(t *testing.T) {
+	parser := &Parser{}
+	sample := `// The service configuration
+{
+	name: 'web',
+	/* The ports that
+	   are exposed */
+	ports: [80, 443,],
+	"replicas": +3,
+	mask: 0xFF,
+	ratio: .5,
+	$description: "a \
+multi-line string",
+	enabled: true,
+	owner: null,
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"name":         "web",
+		"ports":        []interface{}{float64(80), float64(443)},
+		"replicas":     float64(3),
+		"mask":         float64(255),
+		"ratio":        0.5,
+		"$description": "a multi-line string",
+		"enabled":      true,
+		"owner":        nil,
+	}
+
+	if !reflect.DeepEqual(expected, input) {
+		t.Errorf("Unexpected input. expected %v actual %v", expected, input)
+	}
+}
+
+func TestJSON5ParserInvalid(t *testing.T) {
+	testCases := []string{
+		`{name: 'web'`,
+		`{name 'web'}`,
+		`{ratio: Infinity}`,
+		`[1, 2,, 3]`,
+		`{} {}`,
+		`/* unterminated`,
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			if err := parser.Unmarshal([]byte(testCase), &input); err == nil {
+				t.Errorf("expected an error for %v", testCase)
+			}
+		})
+	}
+}
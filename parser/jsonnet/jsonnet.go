@@ -7,15 +7,37 @@ import (
 	"github.com/google/go-jsonnet"
 )
 
-// Parser is a Jsonnet parser.
-type Parser struct{}
+// Parser is a Jsonnet parser. Jsonnet files are evaluated, and the
+// JSON that they evaluate to is the configuration.
+type Parser struct {
+
+	// Path is the path of the file that is parsed. Relative imports are
+	// resolved from the directory of the file, and errors refer to the
+	// lines of the file.
+	Path string
+
+	// JPath is a list of library directories in which imports that are not
+	// found relative to the file are looked up. When an import exists in
+	// more than one of the directories, the last directory wins, as it does
+	// for the -J flag of the jsonnet command.
+	JPath []string
+}
 
 // Unmarshal unmarshals Jsonnet files.
 func (p *Parser) Unmarshal(data []byte, v interface{}) error {
 	vm := jsonnet.MakeVM()
-	snippetStream, err := vm.EvaluateAnonymousSnippet("", string(data))
+	vm.Importer(&jsonnet.FileImporter{JPaths: p.JPath})
+
+	// Without a path, which is the case for standard input, imports are
+	// resolved from the working directory.
+	filename := p.Path
+	if filename == "-" {
+		filename = ""
+	}
+
+	snippetStream, err := vm.EvaluateAnonymousSnippet(filename, string(data))
 	if err != nil {
-		return fmt.Errorf("evaluate anonymous snippet: %w", err)
+		return fmt.Errorf("evaluate jsonnet: %w", err)
 	}
 
 	if err := json.Unmarshal([]byte(snippetStream), v); err != nil {
@@ -1,6 +1,11 @@
 package jsonnet
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -32,3 +37,55 @@ func TestJsonnetParser(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestJsonnetParserImports(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"lib/labels.libsonnet":   `{ team: "platform" }`,
+		"app/ports.libsonnet":    `{ http: 8080 }`,
+		"app/deployment.jsonnet": `{ labels: import "labels.libsonnet", ports: import "ports.libsonnet" }`,
+	}
+
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	path := filepath.Join(root, "app", "deployment.jsonnet")
+	parser := &Parser{Path: path, JPath: []string{filepath.Join(root, "lib")}}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(files["app/deployment.jsonnet"]), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"labels": map[string]interface{}{"team": "platform"},
+		"ports":  map[string]interface{}{"http": float64(8080)},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, input)
+	}
+}
+
+func TestJsonnetParserErrorLocation(t *testing.T) {
+	parser := &Parser{Path: "deployment.jsonnet"}
+	sample := "{\n  replicas: error \"replicas are required\",\n}"
+
+	var input interface{}
+	err := parser.Unmarshal([]byte(sample), &input)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if !strings.Contains(err.Error(), "deployment.jsonnet:2") {
+		t.Errorf("expected the error to refer to line 2 of the file, got: %v", err)
+	}
+}
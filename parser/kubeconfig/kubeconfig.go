@@ -0,0 +1,120 @@
+package kubeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// Parser is a Kubernetes kubeconfig parser.
+type Parser struct{}
+
+// Unmarshal unmarshals Kubernetes kubeconfig files.
+//
+// The contexts, clusters and users of a kubeconfig are lists of named entries that
+// reference each other by name. To allow policies to check a context together with
+// its cluster and user, the following fields are added to the kubeconfig:
+//
+//   - resolvedContexts is a map of context name to the resolved context, which has
+//     the name and namespace of the context, and the cluster and user that the
+//     context references. The cluster and user contain their name and their fields.
+//   - resolvedCurrentContext is the resolved context of current-context, when
+//     current-context references an existing context.
+//
+// A cluster or user that is referenced by a context but does not exist only
+// contains its name. The original fields of the kubeconfig are left untouched.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("unmarshal kubeconfig: %w", err)
+	}
+
+	if config == nil {
+		config = make(map[string]interface{})
+	}
+
+	clusters := namedEntries(config["clusters"], "cluster")
+	users := namedEntries(config["users"], "user")
+	contexts := namedEntries(config["contexts"], "context")
+
+	resolvedContexts := make(map[string]interface{})
+	for name, context := range contexts {
+		resolved := map[string]interface{}{
+			"name": name,
+		}
+
+		if namespace, ok := context["namespace"]; ok {
+			resolved["namespace"] = namespace
+		}
+
+		if clusterName, ok := context["cluster"].(string); ok {
+			resolved["cluster"] = resolve(clusterName, clusters)
+		}
+
+		if userName, ok := context["user"].(string); ok {
+			resolved["user"] = resolve(userName, users)
+		}
+
+		resolvedContexts[name] = resolved
+	}
+
+	config["resolvedContexts"] = resolvedContexts
+	if currentContext, ok := config["current-context"].(string); ok {
+		if resolved, ok := resolvedContexts[currentContext]; ok {
+			config["resolvedCurrentContext"] = resolved
+		}
+	}
+
+	j, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("marshal kubeconfig to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal kubeconfig json: %w", err)
+	}
+
+	return nil
+}
+
+// namedEntries returns the entries of a kubeconfig list, such as the clusters,
+// as a map of the name of the entry to the fields under the given key.
+func namedEntries(list interface{}, key string) map[string]map[string]interface{} {
+	entries := make(map[string]map[string]interface{})
+
+	items, ok := list.([]interface{})
+	if !ok {
+		return entries
+	}
+
+	for _, item := range items {
+		entry, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		name, ok := entry["name"].(string)
+		if !ok {
+			continue
+		}
+
+		fields, _ := entry[key].(map[string]interface{})
+		entries[name] = fields
+	}
+
+	return entries
+}
+
+// resolve returns the fields of the entry with the given name, together with its name.
+func resolve(name string, entries map[string]map[string]interface{}) map[string]interface{} {
+	resolved := map[string]interface{}{
+		"name": name,
+	}
+
+	for key, value := range entries[name] {
+		resolved[key] = value
+	}
+
+	return resolved
+}
@@ -0,0 +1,84 @@
+package kubeconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestKubeconfigParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `apiVersion: v1
+kind: Config
+current-context: development
+clusters:
+- name: development
+  cluster:
+    server: https://dev.example.com
+    insecure-skip-tls-verify: true
+- name: production
+  cluster:
+    server: https://prod.example.com
+    certificate-authority: ca.crt
+users:
+- name: developer
+  user:
+    token: secret
+contexts:
+- name: development
+  context:
+    cluster: development
+    user: developer
+    namespace: dev
+- name: production
+  context:
+    cluster: production
+    user: admin`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	config := input.(map[string]interface{})
+
+	development := map[string]interface{}{
+		"name":      "development",
+		"namespace": "dev",
+		"cluster": map[string]interface{}{
+			"name":                     "development",
+			"server":                   "https://dev.example.com",
+			"insecure-skip-tls-verify": true,
+		},
+		"user": map[string]interface{}{
+			"name":  "developer",
+			"token": "secret",
+		},
+	}
+
+	expected := map[string]interface{}{
+		"development": development,
+		"production": map[string]interface{}{
+			"name": "production",
+			"cluster": map[string]interface{}{
+				"name":                  "production",
+				"server":                "https://prod.example.com",
+				"certificate-authority": "ca.crt",
+			},
+			"user": map[string]interface{}{
+				"name": "admin",
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, config["resolvedContexts"]) {
+		t.Errorf("Unexpected resolved contexts. expected %v actual %v", expected, config["resolvedContexts"])
+	}
+
+	if !reflect.DeepEqual(development, config["resolvedCurrentContext"]) {
+		t.Errorf("Unexpected resolved current context. expected %v actual %v", development, config["resolvedCurrentContext"])
+	}
+
+	if _, ok := config["clusters"]; !ok {
+		t.Error("expected the original clusters to be kept")
+	}
+}
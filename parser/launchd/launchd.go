@@ -0,0 +1,99 @@
+package launchd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-policy-agent/conftest/parser/plist"
+)
+
+// Parser is a parser for macOS launchd job definitions.
+type Parser struct{}
+
+// Unmarshal unmarshals launchd job definitions, which are property lists.
+//
+// Besides the keys of the job, the following fields are added so that
+// policies do not need to handle the different ways a job can be defined:
+//
+//   - program is the executable of the job, which is Program when it is set,
+//     and the first element of ProgramArguments otherwise.
+//   - arguments are the arguments passed to the program, which are the
+//     elements of ProgramArguments after the first.
+//   - user is the UserName of the job, or root when the job does not set a
+//     UserName. launchd only runs jobs as root when they are daemons, as
+//     agents run as the user that is logged in.
+//   - schedule describes when the job runs: atLoad is RunAtLoad, interval is
+//     StartInterval in seconds (0 when not set), calendar is always a list of
+//     the StartCalendarInterval entries, and keepAlive is true when KeepAlive
+//     is set to true or to a dict of conditions.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var job map[string]interface{}
+	if err := new(plist.Parser).Unmarshal(data, &job); err != nil {
+		return fmt.Errorf("unmarshal launchd job: %w", err)
+	}
+
+	if job == nil {
+		return fmt.Errorf("launchd job must be a dict")
+	}
+
+	programArguments, _ := job["ProgramArguments"].([]interface{})
+
+	program, _ := job["Program"].(string)
+	if program == "" && len(programArguments) > 0 {
+		program, _ = programArguments[0].(string)
+	}
+
+	arguments := []interface{}{}
+	if len(programArguments) > 1 {
+		arguments = programArguments[1:]
+	}
+
+	user, _ := job["UserName"].(string)
+	if user == "" {
+		user = "root"
+	}
+
+	interval, ok := job["StartInterval"].(float64)
+	if !ok {
+		interval = 0
+	}
+
+	calendar := []interface{}{}
+	switch value := job["StartCalendarInterval"].(type) {
+	case map[string]interface{}:
+		calendar = append(calendar, value)
+	case []interface{}:
+		calendar = value
+	}
+
+	var keepAlive bool
+	switch value := job["KeepAlive"].(type) {
+	case bool:
+		keepAlive = value
+	case map[string]interface{}:
+		keepAlive = true
+	}
+
+	runAtLoad, _ := job["RunAtLoad"].(bool)
+
+	job["program"] = program
+	job["arguments"] = arguments
+	job["user"] = user
+	job["schedule"] = map[string]interface{}{
+		"atLoad":    runAtLoad,
+		"interval":  interval,
+		"calendar":  calendar,
+		"keepAlive": keepAlive,
+	}
+
+	j, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshal launchd job to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal launchd job json: %w", err)
+	}
+
+	return nil
+}
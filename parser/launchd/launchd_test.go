@@ -0,0 +1,107 @@
+package launchd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLaunchdParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.example.backup</string>
+	<key>ProgramArguments</key>
+	<array>
+		<string>/usr/local/bin/backup</string>
+		<string>--target</string>
+		<string>/Volumes/Backup</string>
+	</array>
+	<key>StartInterval</key>
+	<integer>3600</integer>
+	<key>RunAtLoad</key>
+	<true/>
+</dict>
+</plist>`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Label":            "com.example.backup",
+		"ProgramArguments": []interface{}{"/usr/local/bin/backup", "--target", "/Volumes/Backup"},
+		"StartInterval":    3600.0,
+		"RunAtLoad":        true,
+		"program":          "/usr/local/bin/backup",
+		"arguments":        []interface{}{"--target", "/Volumes/Backup"},
+		"user":             "root",
+		"schedule": map[string]interface{}{
+			"atLoad":    true,
+			"interval":  3600.0,
+			"calendar":  []interface{}{},
+			"keepAlive": false,
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected result. expected %v actual %v", expected, input)
+	}
+}
+
+func TestLaunchdParserCalendarInterval(t *testing.T) {
+	parser := &Parser{}
+	sample := `<plist version="1.0">
+<dict>
+	<key>Program</key>
+	<string>/usr/local/bin/report</string>
+	<key>UserName</key>
+	<string>reporter</string>
+	<key>StartCalendarInterval</key>
+	<dict>
+		<key>Hour</key>
+		<integer>3</integer>
+	</dict>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+</dict>
+</plist>`
+
+	var input map[string]interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if input["program"] != "/usr/local/bin/report" {
+		t.Errorf("Unexpected program. expected %v actual %v", "/usr/local/bin/report", input["program"])
+	}
+
+	if input["user"] != "reporter" {
+		t.Errorf("Unexpected user. expected %v actual %v", "reporter", input["user"])
+	}
+
+	expectedSchedule := map[string]interface{}{
+		"atLoad":    false,
+		"interval":  0.0,
+		"calendar":  []interface{}{map[string]interface{}{"Hour": 3.0}},
+		"keepAlive": true,
+	}
+
+	if !reflect.DeepEqual(input["schedule"], expectedSchedule) {
+		t.Errorf("Unexpected schedule. expected %v actual %v", expectedSchedule, input["schedule"])
+	}
+}
+
+func TestLaunchdParserNotADict(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(`<plist><array/></plist>`), &input); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,355 @@
+package makefile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser is a parser for Makefiles.
+type Parser struct{}
+
+// Target is a target of a Makefile, along with its prerequisites and recipe.
+type Target struct {
+	Target  string   `json:"target"`
+	Prereqs []string `json:"prereqs"`
+
+	// Recipe contains the lines of the recipe, without the tab that
+	// they are prefixed with.
+	Recipe []string `json:"recipe"`
+
+	// Phony is true when the target is listed as a prerequisite
+	// of the special .PHONY target.
+	Phony bool `json:"phony"`
+}
+
+var (
+	assignmentRegex    = regexp.MustCompile(`^(?:(?:export|override|private)\s+)*([^\s:#=]+)\s*(=|:=|::=|:::=|\?=|\+=|!=)\s*(.*)$`)
+	referenceRegex     = regexp.MustCompile(`\$[({]([^$(){}:\s]+)[)}]`)
+	specialTargetRegex = regexp.MustCompile(`^\.[A-Z_]+$`)
+)
+
+// directives are the keywords that start a line which is neither a rule nor
+// an assignment. Conditionals are not evaluated, so the rules of every branch
+// of a conditional are parsed.
+var directives = map[string]bool{
+	"include":  true,
+	"-include": true,
+	"sinclude": true,
+	"ifeq":     true,
+	"ifneq":    true,
+	"ifdef":    true,
+	"ifndef":   true,
+	"else":     true,
+	"endif":    true,
+	"export":   true,
+	"unexport": true,
+	"undefine": true,
+	"load":     true,
+	"-load":    true,
+	"vpath":    true,
+}
+
+// Unmarshal unmarshals Makefiles into a list of targets.
+//
+// A line ending in a backslash continues on the next line, where the lines are
+// joined with a single space. Lines that start with a tab after a rule are the
+// recipe of the rule, and a recipe can also follow the prerequisites after a
+// semicolon. Variables that are assigned in the Makefile are expanded in the
+// names of the targets and prerequisites, but not in the recipes.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	targets := []*Target{}
+	targetsByName := make(map[string]*Target)
+	target := func(name string) *Target {
+		if existing, ok := targetsByName[name]; ok {
+			return existing
+		}
+
+		t := &Target{Target: name, Prereqs: []string{}, Recipe: []string{}}
+		targetsByName[name] = t
+		targets = append(targets, t)
+		return t
+	}
+
+	variables := make(map[string]string)
+	var current []*Target
+	var inDefine bool
+
+	lines, err := logicalLines(data)
+	if err != nil {
+		return fmt.Errorf("scan makefile: %w", err)
+	}
+
+	for _, line := range lines {
+		if inDefine {
+			if strings.TrimSpace(line.text) == "endef" {
+				inDefine = false
+			}
+			continue
+		}
+
+		// Recipe lines are passed to the shell as they are, so comments
+		// are only removed from the other lines.
+		if strings.HasPrefix(line.text, "\t") && current != nil {
+			for _, t := range current {
+				t.Recipe = append(t.Recipe, strings.TrimPrefix(line.text, "\t"))
+			}
+			continue
+		}
+
+		text := strings.TrimSpace(stripComment(line.text))
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if fields[0] == "define" || (len(fields) > 1 && fields[1] == "define" && (fields[0] == "export" || fields[0] == "override")) {
+			inDefine = true
+			current = nil
+			continue
+		}
+
+		if match := assignmentRegex.FindStringSubmatch(text); match != nil {
+			assign(variables, match[1], match[2], match[3])
+			current = nil
+			continue
+		}
+
+		if directives[fields[0]] {
+			current = nil
+			continue
+		}
+
+		// A line that is only a function call, such as $(eval $(call rule,app))
+		// or $(info building), is expanded by make for its side effects.
+		colon := ruleColon(text)
+		if colon < 0 && isExpansion(text) {
+			current = nil
+			continue
+		}
+
+		if colon < 0 {
+			return fmt.Errorf("line %d: expected a rule or an assignment: %q", line.number, text)
+		}
+
+		names := strings.Fields(expand(text[:colon], variables))
+		rest := strings.TrimPrefix(text[colon+1:], ":")
+
+		// Target specific variables, such as target: VAR = value,
+		// do not add prerequisites to the target.
+		if assignmentRegex.MatchString(strings.TrimSpace(rest)) {
+			current = nil
+			continue
+		}
+
+		var recipe string
+		var hasRecipe bool
+		if semicolon := strings.Index(rest, ";"); semicolon >= 0 {
+			recipe = strings.TrimSpace(rest[semicolon+1:])
+			rest = rest[:semicolon]
+			hasRecipe = true
+		}
+
+		// Static pattern rules, such as $(OBJECTS): %.o: %.c, list the target
+		// pattern before the prerequisite patterns.
+		if patternColon := ruleColon(rest); patternColon >= 0 {
+			rest = rest[patternColon+1:]
+		}
+
+		var prereqs []string
+		for _, prereq := range strings.Fields(expand(rest, variables)) {
+			if prereq != "|" {
+				prereqs = append(prereqs, prereq)
+			}
+		}
+
+		current = nil
+		for _, name := range names {
+			if name == ".PHONY" {
+				for _, prereq := range prereqs {
+					target(prereq).Phony = true
+				}
+				continue
+			}
+
+			if specialTargetRegex.MatchString(name) {
+				continue
+			}
+
+			t := target(name)
+			t.Prereqs = append(t.Prereqs, prereqs...)
+			if hasRecipe {
+				t.Recipe = append(t.Recipe, recipe)
+			}
+
+			current = append(current, t)
+		}
+	}
+
+	j, err := json.Marshal(targets)
+	if err != nil {
+		return fmt.Errorf("marshal makefile to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal makefile json: %w", err)
+	}
+
+	return nil
+}
+
+type logicalLine struct {
+	text   string
+	number int
+}
+
+// logicalLines joins the lines that end in a backslash with the lines that
+// follow them. The leading whitespace of a continued line is replaced by a
+// single space, so that a continued recipe line keeps its tab prefix.
+func logicalLines(data []byte) ([]logicalLine, error) {
+	var lines []logicalLine
+	var continued *logicalLine
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if continued != nil {
+			text = continued.text + " " + strings.TrimLeft(text, " \t")
+		}
+
+		if strings.HasSuffix(text, "\\") {
+			number := lineNumber
+			if continued != nil {
+				number = continued.number
+			}
+
+			continued = &logicalLine{text: strings.TrimRight(strings.TrimSuffix(text, "\\"), " \t"), number: number}
+			continue
+		}
+
+		number := lineNumber
+		if continued != nil {
+			number = continued.number
+		}
+
+		lines = append(lines, logicalLine{text: text, number: number})
+		continued = nil
+	}
+
+	if continued != nil {
+		lines = append(lines, *continued)
+	}
+
+	return lines, scanner.Err()
+}
+
+// assign records the value of a variable assignment. Shell assignments (!=)
+// are not executed, so their variables are left unexpanded.
+func assign(variables map[string]string, name string, operator string, value string) {
+	switch operator {
+	case "?=":
+		if _, ok := variables[name]; !ok {
+			variables[name] = value
+		}
+	case "+=":
+		if existing, ok := variables[name]; ok && existing != "" {
+			variables[name] = existing + " " + value
+		} else {
+			variables[name] = value
+		}
+	case "!=":
+	default:
+		variables[name] = value
+	}
+}
+
+// expand expands the references to the assigned variables, such as $(NAME)
+// and ${NAME}. References to other variables and function calls, such as
+// $(wildcard *.c), are left as they are.
+func expand(text string, variables map[string]string) string {
+	for depth := 0; depth < 10 && referenceRegex.MatchString(text); depth++ {
+		expanded := referenceRegex.ReplaceAllStringFunc(text, func(reference string) string {
+			name := reference[2 : len(reference)-1]
+			if value, ok := variables[name]; ok {
+				return value
+			}
+
+			return reference
+		})
+
+		if expanded == text {
+			break
+		}
+
+		text = expanded
+	}
+
+	return text
+}
+
+// ruleColon returns the index of the first colon that is not part of a
+// variable reference, such as the colon of $(SOURCES:.c=.o).
+func ruleColon(text string) int {
+	var depth int
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(', '{':
+			if i > 0 && text[i-1] == '$' || depth > 0 {
+				depth++
+			}
+		case ')', '}':
+			if depth > 0 {
+				depth--
+			}
+		case ':':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// isExpansion returns true when the text is a single variable reference or
+// function call, such as $(eval ...), with nothing after it.
+func isExpansion(text string) bool {
+	if !strings.HasPrefix(text, "$(") && !strings.HasPrefix(text, "${") {
+		return false
+	}
+
+	var depth int
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '(', '{':
+			depth++
+		case ')', '}':
+			depth--
+			if depth == 0 {
+				return strings.TrimSpace(text[i+1:]) == ""
+			}
+		}
+	}
+
+	return false
+}
+
+// stripComment removes the comment from a line that is not part of a recipe.
+// A # that is escaped with a backslash does not start a comment.
+func stripComment(line string) string {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+
+		if line[i] == '#' {
+			return line[:i]
+		}
+	}
+
+	return line
+}
@@ -0,0 +1,145 @@
+package makefile
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMakefileParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# Build settings
+BINARY := conftest
+SOURCES = main.go \
+	internal/commands/test.go
+GOFLAGS ?= -trimpath
+
+.PHONY: all test clean
+
+all: $(BINARY)
+
+$(BINARY): $(SOURCES) go.mod # the binary
+	go build $(GOFLAGS) \
+		-o $(BINARY) .
+	@echo "built $(BINARY)"
+
+test: export CGO_ENABLED = 0
+test:
+	go test ./...
+
+clean: ; rm -f $(BINARY)
+
+ifeq ($(CI),true)
+release: all
+	./scripts/release.sh
+endif
+
+define HELP
+usage: make all
+endef
+`
+
+	var input []interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"target":  "all",
+			"prereqs": []interface{}{"conftest"},
+			"recipe":  []interface{}{},
+			"phony":   true,
+		},
+		map[string]interface{}{
+			"target":  "test",
+			"prereqs": []interface{}{},
+			"recipe":  []interface{}{"go test ./..."},
+			"phony":   true,
+		},
+		map[string]interface{}{
+			"target":  "clean",
+			"prereqs": []interface{}{},
+			"recipe":  []interface{}{"rm -f $(BINARY)"},
+			"phony":   true,
+		},
+		map[string]interface{}{
+			"target":  "conftest",
+			"prereqs": []interface{}{"main.go", "internal/commands/test.go", "go.mod"},
+			"recipe":  []interface{}{"go build $(GOFLAGS) -o $(BINARY) .", `@echo "built $(BINARY)"`},
+			"phony":   false,
+		},
+		map[string]interface{}{
+			"target":  "release",
+			"prereqs": []interface{}{"all"},
+			"recipe":  []interface{}{"./scripts/release.sh"},
+			"phony":   false,
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected targets. expected %v actual %v", expected, input)
+	}
+}
+
+func TestMakefileParserPatternRules(t *testing.T) {
+	parser := &Parser{}
+	sample := `OBJECTS = a.o b.o
+
+$(OBJECTS): %.o: %.c | build
+	cc -c $< -o $@
+
+%.html: %.md
+	pandoc $< > $@
+`
+
+	var input []interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 3 {
+		t.Fatalf("Unexpected number of targets. expected 3 actual %v", len(input))
+	}
+
+	object := input[1].(map[string]interface{})
+	if object["target"] != "b.o" || !reflect.DeepEqual(object["prereqs"], []interface{}{"%.c", "build"}) {
+		t.Errorf("Unexpected static pattern rule. actual %v", object)
+	}
+
+	pattern := input[2].(map[string]interface{})
+	if pattern["target"] != "%.html" || !reflect.DeepEqual(pattern["recipe"], []interface{}{"pandoc $< > $@"}) {
+		t.Errorf("Unexpected pattern rule. actual %v", pattern)
+	}
+}
+
+func TestMakefileParserInvalidLine(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte("all: build\nnot a rule\n"), &input); err == nil {
+		t.Error("expected an error for a line that is neither a rule nor an assignment")
+	}
+}
+
+func TestMakefileParserFunctionCalls(t *testing.T) {
+	parser := &Parser{}
+	sample := `define rule
+$(1): ; echo $(1)
+endef
+
+$(foreach app,api web,$(eval $(call rule,$(app))))
+$(info building)
+undefine rule
+
+all: build
+`
+
+	var input []interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 1 || input[0].(map[string]interface{})["target"] != "all" {
+		t.Errorf("Unexpected targets. expected only all actual %v", input)
+	}
+}
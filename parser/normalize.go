@@ -0,0 +1,131 @@
+package parser
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// NormalizeCIDRs canonicalizes the CIDR blocks of Kubernetes NetworkPolicy
+// ipBlock fields (ipBlock.cidr and ipBlock.except) in the given configurations,
+// so that CIDRs which describe the same network can be compared for equality.
+// For example, 10.0.0.1/8 is normalized to 10.0.0.0/8. Values that are not valid
+// CIDRs are left unchanged.
+func NormalizeCIDRs(configurations map[string]interface{}) {
+	for path, config := range configurations {
+		configurations[path] = normalizeCIDRs(config)
+	}
+}
+
+func normalizeCIDRs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if key == "ipBlock" {
+				normalizeIPBlock(child)
+			}
+
+			v[key] = normalizeCIDRs(child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			v[i] = normalizeCIDRs(child)
+		}
+	}
+
+	return value
+}
+
+func normalizeIPBlock(value interface{}) {
+	ipBlock, ok := value.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if cidr, ok := ipBlock["cidr"].(string); ok {
+		ipBlock["cidr"] = normalizeCIDR(cidr)
+	}
+
+	if except, ok := ipBlock["except"].([]interface{}); ok {
+		for i, item := range except {
+			if cidr, ok := item.(string); ok {
+				except[i] = normalizeCIDR(cidr)
+			}
+		}
+	}
+}
+
+func normalizeCIDR(cidr string) string {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return cidr
+	}
+
+	return network.String()
+}
+
+// ExpandLabels adds the labels and annotations of Kubernetes resources in the
+// given configurations as lists of entries that are sorted by key, so that
+// policies can iterate over them in a deterministic order. The entries are added
+// to the metadata as labelEntries and annotationEntries, next to the original
+// labels and annotations maps. Every entry has the full key, the prefix and name
+// of the key (e.g. app.kubernetes.io and name for app.kubernetes.io/name), and
+// the value.
+func ExpandLabels(configurations map[string]interface{}) {
+	for _, config := range configurations {
+		expandLabels(config)
+	}
+}
+
+func expandLabels(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			expandLabels(child)
+		}
+
+		metadata, ok := v["metadata"].(map[string]interface{})
+		if !ok {
+			return
+		}
+
+		if labels, ok := metadata["labels"].(map[string]interface{}); ok {
+			metadata["labelEntries"] = labelEntries(labels)
+		}
+
+		if annotations, ok := metadata["annotations"].(map[string]interface{}); ok {
+			metadata["annotationEntries"] = labelEntries(annotations)
+		}
+	case []interface{}:
+		for _, child := range v {
+			expandLabels(child)
+		}
+	}
+}
+
+func labelEntries(labels map[string]interface{}) []interface{} {
+	var keys []string
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		var prefix string
+		name := key
+		if i := strings.LastIndex(key, "/"); i >= 0 {
+			prefix = key[:i]
+			name = key[i+1:]
+		}
+
+		entries = append(entries, map[string]interface{}{
+			"key":    key,
+			"prefix": prefix,
+			"name":   name,
+			"value":  labels[key],
+		})
+	}
+
+	return entries
+}
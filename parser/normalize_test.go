@@ -0,0 +1,108 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeCIDRs(t *testing.T) {
+	configurations := map[string]interface{}{
+		"networkpolicy.yaml": map[string]interface{}{
+			"kind": "NetworkPolicy",
+			"spec": map[string]interface{}{
+				"ingress": []interface{}{
+					map[string]interface{}{
+						"from": []interface{}{
+							map[string]interface{}{
+								"ipBlock": map[string]interface{}{
+									"cidr":   "10.0.0.1/8",
+									"except": []interface{}{"10.1.2.3/16", "2001:db8::1/32", "invalid"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"configmap.yaml": map[string]interface{}{
+			"data": map[string]interface{}{
+				"cidr": "10.0.0.1/8",
+			},
+		},
+	}
+
+	NormalizeCIDRs(configurations)
+
+	expected := map[string]interface{}{
+		"cidr":   "10.0.0.0/8",
+		"except": []interface{}{"10.1.0.0/16", "2001:db8::/32", "invalid"},
+	}
+
+	spec := configurations["networkpolicy.yaml"].(map[string]interface{})["spec"].(map[string]interface{})
+	from := spec["ingress"].([]interface{})[0].(map[string]interface{})["from"].([]interface{})
+	actual := from[0].(map[string]interface{})["ipBlock"]
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected ipBlock. expected %v actual %v", expected, actual)
+	}
+
+	data := configurations["configmap.yaml"].(map[string]interface{})["data"].(map[string]interface{})
+	if data["cidr"] != "10.0.0.1/8" {
+		t.Errorf("Unexpected cidr outside of an ipBlock. expected %v actual %v", "10.0.0.1/8", data["cidr"])
+	}
+}
+
+func TestExpandLabels(t *testing.T) {
+	configurations := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{
+			"kind": "Deployment",
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{
+					"app.kubernetes.io/name": "web",
+					"app":                    "web",
+				},
+				"annotations": map[string]interface{}{
+					"example.com/owner": "team",
+				},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{
+							"tier": "frontend",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ExpandLabels(configurations)
+
+	deployment := configurations["deployment.yaml"].(map[string]interface{})
+	metadata := deployment["metadata"].(map[string]interface{})
+
+	expectedLabels := []interface{}{
+		map[string]interface{}{"key": "app", "prefix": "", "name": "app", "value": "web"},
+		map[string]interface{}{"key": "app.kubernetes.io/name", "prefix": "app.kubernetes.io", "name": "name", "value": "web"},
+	}
+	if !reflect.DeepEqual(expectedLabels, metadata["labelEntries"]) {
+		t.Errorf("Unexpected label entries. expected %v actual %v", expectedLabels, metadata["labelEntries"])
+	}
+
+	expectedAnnotations := []interface{}{
+		map[string]interface{}{"key": "example.com/owner", "prefix": "example.com", "name": "owner", "value": "team"},
+	}
+	if !reflect.DeepEqual(expectedAnnotations, metadata["annotationEntries"]) {
+		t.Errorf("Unexpected annotation entries. expected %v actual %v", expectedAnnotations, metadata["annotationEntries"])
+	}
+
+	if _, ok := metadata["labels"].(map[string]interface{}); !ok {
+		t.Error("Unexpected labels. expected the original labels map to be kept")
+	}
+
+	template := deployment["spec"].(map[string]interface{})["template"].(map[string]interface{})
+	templateMetadata := template["metadata"].(map[string]interface{})
+	if entries, ok := templateMetadata["labelEntries"].([]interface{}); !ok || len(entries) != 1 {
+		t.Errorf("Unexpected template label entries. expected 1 entry actual %v", templateMetadata["labelEntries"])
+	}
+}
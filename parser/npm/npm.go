@@ -0,0 +1,77 @@
+package npm
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Parser is a parser for npm package.json files.
+type Parser struct{}
+
+// Dependency is a single dependency of a package.json file.
+type Dependency struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+
+	// Dev is true when the dependency is one of the devDependencies.
+	Dev bool `json:"dev"`
+}
+
+// Unmarshal unmarshals package.json files.
+//
+// The dependencies and devDependencies of the package are merged into a
+// single mergedDependencies list, sorted by name, where every dependency
+// has a dev field that is true for the devDependencies. This allows a
+// policy to check all of the dependencies in one place. The other fields
+// of the package.json file are kept.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("unmarshal package.json: %w", err)
+	}
+
+	dependencies := []Dependency{}
+	for _, field := range []string{"dependencies", "devDependencies"} {
+		value, ok := document[field]
+		if !ok {
+			continue
+		}
+
+		versions, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an object", field)
+		}
+
+		for name, version := range versions {
+			dependency := Dependency{
+				Name:    name,
+				Version: fmt.Sprint(version),
+				Dev:     field == "devDependencies",
+			}
+
+			dependencies = append(dependencies, dependency)
+		}
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Name != dependencies[j].Name {
+			return dependencies[i].Name < dependencies[j].Name
+		}
+
+		return !dependencies[i].Dev && dependencies[j].Dev
+	})
+
+	document["mergedDependencies"] = dependencies
+
+	j, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("marshal package.json to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal package.json json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package npm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNPMParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+	"name": "app",
+	"dependencies": {
+		"lodash": "4.17.21",
+		"express": "^4.18.2"
+	},
+	"devDependencies": {
+		"jest": "*"
+	}
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	document := input.(map[string]interface{})
+	if document["name"] != "app" {
+		t.Errorf("Unexpected name. expected app actual %v", document["name"])
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"name": "express", "version": "^4.18.2", "dev": false},
+		map[string]interface{}{"name": "jest", "version": "*", "dev": true},
+		map[string]interface{}{"name": "lodash", "version": "4.17.21", "dev": false},
+	}
+
+	if !reflect.DeepEqual(document["mergedDependencies"], expected) {
+		t.Errorf("Unexpected dependencies. expected %v actual %v", expected, document["mergedDependencies"])
+	}
+}
+
+func TestNPMParserWithoutDependencies(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(`{"name": "app"}`), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	dependencies := input.(map[string]interface{})["mergedDependencies"]
+	if !reflect.DeepEqual(dependencies, []interface{}{}) {
+		t.Errorf("Unexpected dependencies. expected an empty list actual %v", dependencies)
+	}
+}
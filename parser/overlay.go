@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ApplyOverlays merges the given overlays, in order, into every one of the
+// configurations. The overlays are merged with JSON Merge Patch (RFC 7386)
+// semantics, see MergePatch. A configuration with multiple documents, such as
+// a multi-document YAML file, has the overlays merged into each document. An
+// error is returned when a configuration or one of its documents is not an
+// object, as the overlay would replace it entirely. Empty documents are kept
+// as they are.
+func ApplyOverlays(configurations map[string]interface{}, overlays []interface{}) error {
+	var paths []string
+	for path := range configurations {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	overlay := func(config interface{}) interface{} {
+		for _, overlay := range overlays {
+			config = MergePatch(config, overlay)
+		}
+
+		return config
+	}
+
+	for _, path := range paths {
+		switch config := configurations[path].(type) {
+		case map[string]interface{}:
+			configurations[path] = overlay(config)
+		case []interface{}:
+			documents := make([]interface{}, len(config))
+			for index, document := range config {
+				if document == nil {
+					continue
+				}
+
+				if _, ok := document.(map[string]interface{}); !ok {
+					return fmt.Errorf("overlay %v: document %d is not an object", path, index)
+				}
+
+				documents[index] = overlay(document)
+			}
+
+			configurations[path] = documents
+		case nil:
+			continue
+		default:
+			return fmt.Errorf("overlay %v: configuration is not an object", path)
+		}
+	}
+
+	return nil
+}
+
+// MergePatch returns the result of applying the patch to the target as a
+// JSON Merge Patch (RFC 7386). When the patch is an object, its fields are
+// merged into the target recursively, where a null value removes the field
+// from the target. Any other patch, including an array, replaces the target.
+// The target is not modified.
+func MergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	merged := make(map[string]interface{})
+	if targetObject, ok := target.(map[string]interface{}); ok {
+		for key, value := range targetObject {
+			merged[key] = value
+		}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+
+		merged[key] = MergePatch(merged[key], value)
+	}
+
+	return merged
+}
@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApplyOverlays(t *testing.T) {
+	base := map[string]interface{}{
+		"kind": "Deployment",
+		"metadata": map[string]interface{}{
+			"name":   "app",
+			"labels": map[string]interface{}{"team": "platform", "tier": "backend"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 1,
+			"ports":    []interface{}{80, 443},
+		},
+	}
+
+	configurations := map[string]interface{}{
+		"base.yaml": base,
+	}
+
+	overlays := []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"tier": nil, "env": "prod"},
+			},
+			"spec": map[string]interface{}{
+				"replicas": 3,
+				"ports":    []interface{}{8080},
+			},
+		},
+		map[string]interface{}{
+			"spec": map[string]interface{}{"replicas": 5},
+			"kind": nil,
+		},
+	}
+
+	if err := ApplyOverlays(configurations, overlays); err != nil {
+		t.Fatalf("apply overlays: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "app",
+			"labels": map[string]interface{}{"team": "platform", "env": "prod"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 5,
+			"ports":    []interface{}{8080},
+		},
+	}
+
+	if !reflect.DeepEqual(configurations["base.yaml"], expected) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations["base.yaml"])
+	}
+
+	if _, ok := base["kind"]; !ok {
+		t.Error("expected the base configuration not to be modified")
+	}
+}
+
+func TestApplyOverlaysMultipleDocuments(t *testing.T) {
+	contents := `kind: Deployment
+metadata:
+  name: app
+---
+kind: Service
+metadata:
+  name: app
+`
+
+	file := filepath.Join(t.TempDir(), "manifests.yaml")
+	if err := ioutil.WriteFile(file, []byte(contents), os.ModePerm); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	config, err := ParseConfigurations([]string{file})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	overlays := []interface{}{
+		map[string]interface{}{
+			"metadata": map[string]interface{}{"labels": map[string]interface{}{"env": "prod"}},
+		},
+	}
+
+	if err := ApplyOverlays(config, overlays); err != nil {
+		t.Fatalf("apply overlays: %v", err)
+	}
+
+	documents, ok := config[file].([]interface{})
+	if !ok {
+		t.Fatalf("Unexpected configuration. expected a list of documents actual %v", config[file])
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"kind":     "Deployment",
+			"metadata": map[string]interface{}{"name": "app", "labels": map[string]interface{}{"env": "prod"}},
+		},
+		map[string]interface{}{
+			"kind":     "Service",
+			"metadata": map[string]interface{}{"name": "app", "labels": map[string]interface{}{"env": "prod"}},
+		},
+	}
+
+	if !reflect.DeepEqual(documents, expected) {
+		t.Errorf("Unexpected documents. expected %v actual %v", expected, documents)
+	}
+}
+
+func TestApplyOverlaysNotAnObject(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config interface{}
+	}{
+		{name: "value", config: "FROM alpine"},
+		{name: "document", config: []interface{}{map[string]interface{}{"kind": "Service"}, []interface{}{"a"}}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			configurations := map[string]interface{}{"config": testCase.config}
+			overlays := []interface{}{map[string]interface{}{"kind": "Deployment"}}
+
+			if err := ApplyOverlays(configurations, overlays); err == nil {
+				t.Error("expected an error when the configuration is not an object")
+			}
+		})
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		target   interface{}
+		patch    interface{}
+		expected interface{}
+	}{
+		{
+			name:     "replaces a value",
+			target:   map[string]interface{}{"a": "b"},
+			patch:    map[string]interface{}{"a": "c"},
+			expected: map[string]interface{}{"a": "c"},
+		},
+		{
+			name:     "adds a value",
+			target:   map[string]interface{}{"a": "b"},
+			patch:    map[string]interface{}{"b": "c"},
+			expected: map[string]interface{}{"a": "b", "b": "c"},
+		},
+		{
+			name:     "removes a value",
+			target:   map[string]interface{}{"a": "b", "b": "c"},
+			patch:    map[string]interface{}{"a": nil},
+			expected: map[string]interface{}{"b": "c"},
+		},
+		{
+			name:     "replaces an array",
+			target:   map[string]interface{}{"a": []interface{}{"b"}},
+			patch:    map[string]interface{}{"a": "c"},
+			expected: map[string]interface{}{"a": "c"},
+		},
+		{
+			name:     "replaces a value that is not an object",
+			target:   map[string]interface{}{"a": "foo"},
+			patch:    map[string]interface{}{"a": map[string]interface{}{"b": "c", "d": nil}},
+			expected: map[string]interface{}{"a": map[string]interface{}{"b": "c"}},
+		},
+		{
+			name:     "replaces the target with an array",
+			target:   map[string]interface{}{"a": "b"},
+			patch:    []interface{}{"c"},
+			expected: []interface{}{"c"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			actual := MergePatch(testCase.target, testCase.patch)
+
+			if !reflect.DeepEqual(actual, testCase.expected) {
+				t.Errorf("Unexpected result. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
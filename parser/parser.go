@@ -2,6 +2,8 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -9,17 +11,40 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/open-policy-agent/conftest/parser/ansibleinventory"
+	"github.com/open-policy-agent/conftest/parser/avro"
+	"github.com/open-policy-agent/conftest/parser/cargolock"
+	"github.com/open-policy-agent/conftest/parser/conf"
+	"github.com/open-policy-agent/conftest/parser/configmapenv"
+	"github.com/open-policy-agent/conftest/parser/consulkv"
+	"github.com/open-policy-agent/conftest/parser/csv"
 	"github.com/open-policy-agent/conftest/parser/cue"
+	"github.com/open-policy-agent/conftest/parser/dhall"
 	"github.com/open-policy-agent/conftest/parser/docker"
 	"github.com/open-policy-agent/conftest/parser/edn"
+	"github.com/open-policy-agent/conftest/parser/gemfilelock"
 	"github.com/open-policy-agent/conftest/parser/hcl1"
 	"github.com/open-policy-agent/conftest/parser/hcl2"
 	"github.com/open-policy-agent/conftest/parser/hocon"
+	"github.com/open-policy-agent/conftest/parser/iam"
 	"github.com/open-policy-agent/conftest/parser/ignore"
 	"github.com/open-policy-agent/conftest/parser/ini"
 	"github.com/open-policy-agent/conftest/parser/json"
+	"github.com/open-policy-agent/conftest/parser/json5"
 	"github.com/open-policy-agent/conftest/parser/jsonnet"
+	"github.com/open-policy-agent/conftest/parser/kubeconfig"
+	"github.com/open-policy-agent/conftest/parser/launchd"
+	"github.com/open-policy-agent/conftest/parser/makefile"
+	"github.com/open-policy-agent/conftest/parser/npm"
+	"github.com/open-policy-agent/conftest/parser/plist"
+	"github.com/open-policy-agent/conftest/parser/prometheusrules"
 	"github.com/open-policy-agent/conftest/parser/properties"
+	"github.com/open-policy-agent/conftest/parser/proto"
+	"github.com/open-policy-agent/conftest/parser/pyproject"
+	"github.com/open-policy-agent/conftest/parser/requirements"
+	"github.com/open-policy-agent/conftest/parser/sentinel"
+	"github.com/open-policy-agent/conftest/parser/systemdnetwork"
+	"github.com/open-policy-agent/conftest/parser/tfstate"
 	"github.com/open-policy-agent/conftest/parser/toml"
 	"github.com/open-policy-agent/conftest/parser/vcl"
 	"github.com/open-policy-agent/conftest/parser/xml"
@@ -29,21 +54,45 @@ import (
 // The defined parsers are the parsers that are valid for
 // parsing files.
 const (
-	CUE        = "cue"
-	Dockerfile = "dockerfile"
-	EDN        = "edn"
-	HCL1       = "hcl1"
-	HCL2       = "hcl2"
-	HOCON      = "hocon"
-	IGNORE     = "ignore"
-	INI        = "ini"
-	JSON       = "json"
-	JSONNET    = "jsonnet"
-	PROPERTIES = "properties"
-	TOML       = "toml"
-	VCL        = "vcl"
-	XML        = "xml"
-	YAML       = "yaml"
+	ANSIBLEINVENTORY  = "ansible-inventory"
+	AVRO              = "avro"
+	CARGOLOCK         = "cargo-lock"
+	CONF              = "conf"
+	CONFIGMAPENV      = "configmap-env"
+	CONSULKV          = "consul-kv"
+	CSV               = "csv"
+	CUE               = "cue"
+	DHALL             = "dhall"
+	Dockerfile        = "dockerfile"
+	EDN               = "edn"
+	GEMFILELOCK       = "gemfile-lock"
+	HCL1              = "hcl1"
+	HCL2              = "hcl2"
+	HOCON             = "hocon"
+	IAM               = "iam"
+	IGNORE            = "ignore"
+	INI               = "ini"
+	JSON              = "json"
+	JSON5             = "json5"
+	JSONNET           = "jsonnet"
+	KUBECONFIG        = "kubeconfig"
+	LAUNCHD           = "launchd"
+	MAKEFILE          = "makefile"
+	NPM               = "npm"
+	PLIST             = "plist"
+	PROMETHEUSRULES   = "prometheus-rules"
+	PROPERTIES        = "properties"
+	PROPERTIESORDERED = "properties-ordered"
+	PROTO             = "proto"
+	PYPROJECT         = "pyproject"
+	REQUIREMENTS      = "requirements"
+	SENTINEL          = "sentinel"
+	SYSTEMDNETWORK    = "systemd-network"
+	TFSTATE           = "tfstate"
+	TOML              = "toml"
+	VCL               = "vcl"
+	XML               = "xml"
+	YAML              = "yaml"
 )
 
 // Parser defines all of the methods that every parser
@@ -85,6 +134,54 @@ func New(parser string) (Parser, error) {
 		return &ignore.Parser{}, nil
 	case PROPERTIES:
 		return &properties.Parser{}, nil
+	case PROPERTIESORDERED:
+		return &properties.Parser{PreserveOrder: true}, nil
+	case CONFIGMAPENV:
+		return &configmapenv.Parser{}, nil
+	case IAM:
+		return &iam.Parser{}, nil
+	case PROTO:
+		return &proto.Parser{}, nil
+	case TFSTATE:
+		return &tfstate.Parser{}, nil
+	case JSON5:
+		return &json5.Parser{}, nil
+	case CONSULKV:
+		return &consulkv.Parser{}, nil
+	case CONF:
+		return &conf.Parser{}, nil
+	case KUBECONFIG:
+		return &kubeconfig.Parser{}, nil
+	case DHALL:
+		return &dhall.Parser{}, nil
+	case AVRO:
+		return &avro.Parser{}, nil
+	case SYSTEMDNETWORK:
+		return &systemdnetwork.Parser{}, nil
+	case NPM:
+		return &npm.Parser{}, nil
+	case REQUIREMENTS:
+		return &requirements.Parser{}, nil
+	case PYPROJECT:
+		return &pyproject.Parser{}, nil
+	case ANSIBLEINVENTORY:
+		return &ansibleinventory.Parser{}, nil
+	case PLIST:
+		return &plist.Parser{}, nil
+	case LAUNCHD:
+		return &launchd.Parser{}, nil
+	case MAKEFILE:
+		return &makefile.Parser{}, nil
+	case CSV:
+		return &csv.Parser{}, nil
+	case SENTINEL:
+		return &sentinel.Parser{}, nil
+	case PROMETHEUSRULES:
+		return &prometheusrules.Parser{}, nil
+	case CARGOLOCK:
+		return &cargolock.Parser{}, nil
+	case GEMFILELOCK:
+		return &gemfilelock.Parser{}, nil
 	default:
 		return nil, fmt.Errorf("unknown parser: %v", parser)
 	}
@@ -93,11 +190,35 @@ func New(parser string) (Parser, error) {
 // NewFromPath returns a file parser based on the file type
 // that exists at the given path.
 func NewFromPath(path string) (Parser, error) {
+	parser, err := New(nameFromPath(path))
+	if err != nil {
+		return nil, fmt.Errorf("new: %w", err)
+	}
+
+	return parser, nil
+}
+
+// nameFromPath returns the name of the parser for the file type that exists at
+// the given path. The name is not guaranteed to be a known parser.
+func nameFromPath(path string) string {
 
 	// We use the YAML parser as the default when passing in configuration
 	// data through standard input. This can be overridden by using the parser flag.
 	if path == "-" {
-		return New(YAML)
+		return YAML
+	}
+
+	// Compressed files are parsed by the parser of the file that was
+	// compressed, e.g. the YAML parser for deployment.yaml.gz. Compressed
+	// files without an extension, such as compressed logs, are not parsed
+	// as YAML, as the files without an extension are by default.
+	if uncompressed, ok := trimCompression(path); ok {
+		name := nameFromPath(uncompressed)
+		if name == YAML && filepath.Ext(uncompressed) == "" {
+			return "gz"
+		}
+
+		return name
 	}
 
 	fileName := strings.ToLower(filepath.Base(path))
@@ -111,44 +232,123 @@ func NewFromPath(path string) (Parser, error) {
 	// Dockerfile, or have Dockerfile as its extension.
 	//
 	// For example: Dockerfile, Dockerfile.debug, dev.Dockerfile
-	if fileName == "dockerfile" || strings.HasPrefix(fileName, "dockerfile.") || fileExtension == "dockerfile" {
-		return New(Dockerfile)
+	if fileName == "dockerfile" || strings.HasPrefix(fileName, "dockerfile.") {
+		return Dockerfile
 	}
 
-	if fileExtension == "yml" || fileExtension == "yaml" {
-		return New(YAML)
+	// Requirements files are text files, so they are detected by their name,
+	// e.g. requirements.txt or requirements-dev.txt.
+	if strings.HasPrefix(fileName, "requirements") && fileExtension == "txt" {
+		return REQUIREMENTS
 	}
 
-	if fileExtension == "tf" || fileExtension == "tfvars" {
-		return New(HCL2)
+	if fileName == "pyproject.toml" {
+		return PYPROJECT
 	}
 
-	if fileExtension == "gitignore" || fileExtension == "dockerignore" {
-		return New(IGNORE)
+	// Lockfiles are detected by their name, as the .lock extension is
+	// used by the lockfiles of many package managers.
+	if fileName == "cargo.lock" {
+		return CARGOLOCK
 	}
 
-	parser, err := New(fileExtension)
-	if err != nil {
-		return nil, fmt.Errorf("new: %w", err)
+	if fileName == "gemfile.lock" || fileName == "gems.locked" {
+		return GEMFILELOCK
 	}
 
-	return parser, nil
+	// Makefiles usually do not have an extension, e.g. Makefile or GNUmakefile.
+	if fileName == "makefile" || fileName == "gnumakefile" {
+		return MAKEFILE
+	}
+
+	if name, ok := extensionParsers[fileExtension]; ok {
+		return name
+	}
+
+	return fileExtension
+}
+
+// extensionParsers maps the file extensions, without the leading dot, to the
+// name of the parser that they select.
+var extensionParsers = map[string]string{
+	"avsc":         AVRO,
+	"csv":          CSV,
+	"cue":          CUE,
+	"dhall":        DHALL,
+	"dockerfile":   Dockerfile,
+	"dockerignore": IGNORE,
+	"edn":          EDN,
+	"gitignore":    IGNORE,
+	"hocon":        HOCON,
+	"ini":          INI,
+	"json":         JSON,
+	"json5":        JSON5,
+	"jsonnet":      JSONNET,
+	"mk":           MAKEFILE,
+	"netdev":       SYSTEMDNETWORK,
+	"network":      SYSTEMDNETWORK,
+	"npmignore":    IGNORE,
+	"plist":        PLIST,
+	"properties":   PROPERTIES,
+	"proto":        PROTO,
+	"sentinel":     SENTINEL,
+	"tf":           HCL2,
+	"tfstate":      TFSTATE,
+	"tfvars":       HCL2,
+	"toml":         TOML,
+	"vcl":          VCL,
+	"xml":          XML,
+	"yaml":         YAML,
+	"yml":          YAML,
+
+	// Jsonnet libraries are usually imported by other Jsonnet files,
+	// but can be evaluated on their own as well.
+	"libsonnet": JSONNET,
+
+	// The .conf and .cfg extensions are used by many formats, so the
+	// format is determined from the contents of the file instead.
+	"conf": CONF,
+	"cfg":  CONF,
 }
 
 // Parsers returns a list of the supported Parsers.
 func Parsers() []string {
 	parsers := []string{
+		ANSIBLEINVENTORY,
+		AVRO,
+		CARGOLOCK,
+		CONF,
+		CONFIGMAPENV,
+		CONSULKV,
+		CSV,
 		CUE,
+		DHALL,
 		Dockerfile,
 		EDN,
+		GEMFILELOCK,
 		HCL1,
 		HCL2,
 		HOCON,
+		IAM,
 		IGNORE,
 		INI,
 		JSON,
+		JSON5,
 		JSONNET,
+		KUBECONFIG,
+		LAUNCHD,
+		MAKEFILE,
+		NPM,
+		PLIST,
+		PROMETHEUSRULES,
 		PROPERTIES,
+		PROPERTIESORDERED,
+		PROTO,
+		PYPROJECT,
+		REQUIREMENTS,
+		SENTINEL,
+		SYSTEMDNETWORK,
+		TFSTATE,
 		TOML,
 		VCL,
 		XML,
@@ -172,7 +372,7 @@ func FileSupported(path string) bool {
 // list of files. The result will be a map where the key is the file name of
 // the configuration.
 func ParseConfigurations(files []string) (map[string]interface{}, error) {
-	configurations, err := parseConfigurations(files, "")
+	configurations, _, err := parseConfigurations(files, "", Options{})
 	if err != nil {
 		return nil, err
 	}
@@ -182,9 +382,65 @@ func ParseConfigurations(files []string) (map[string]interface{}, error) {
 
 // ParseConfigurationsAs parses the files as the given file type and returns the
 // configurations given in the file list. The result will be a map where the key
-// is the file name of the configuration.
+// is the file name of the configuration. See NewFromPathAs for the supported
+// values of the parser.
 func ParseConfigurationsAs(files []string, parser string) (map[string]interface{}, error) {
-	configurations, err := parseConfigurations(files, parser)
+	configurations, _, err := parseConfigurations(files, parser, Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	return configurations, nil
+}
+
+// Options represents the options available when parsing configurations.
+type Options struct {
+
+	// StrictYAML reports duplicate keys in YAML files as an error,
+	// rather than silently keeping the value of the last key.
+	StrictYAML bool
+
+	// HelmSourceComments records the path in the Source comment that
+	// precedes each of the documents in YAML files.
+	HelmSourceComments bool
+
+	// DhallNoRemote forbids Dhall files from importing expressions from URLs.
+	DhallNoRemote bool
+
+	// CSVNoHeader parses CSV files that do not start with a header row.
+	CSVNoHeader bool
+
+	// JsonnetPath is a list of library directories in which the imports of
+	// Jsonnet files are looked up, after the directory of the file itself.
+	JsonnetPath []string
+
+	// ResolveIncludes replaces the includes of YAML and JSON files, such as
+	// $ref objects and !include tags, with the files that they include.
+	ResolveIncludes bool
+
+	// RemoteIncludes allows includes to refer to files on HTTP servers,
+	// rather than only to files on the local filesystem.
+	RemoteIncludes bool
+
+	// StrictInput returns an error for files that are not recognized by their
+	// name or extension, rather than parsing them as YAML. See CheckRecognized.
+	StrictInput bool
+}
+
+// ParseConfigurationsWithSources parses the files in the same way as
+// ParseConfigurationsWithOptions, and also returns the Source comments of the YAML
+// files when HelmSourceComments is set. The sources are a map where the key is the
+// file name of the configuration, and the value contains the source of each document.
+func ParseConfigurationsWithSources(files []string, parser string, options Options) (map[string]interface{}, map[string][]string, error) {
+	return parseConfigurations(files, parser, options)
+}
+
+// ParseConfigurationsWithOptions parses the files using the given parser and options, and
+// returns the configurations given in the file list. The result will be a map where the
+// key is the file name of the configuration. See NewFromPathAs for the supported values
+// of the parser, where an empty parser selects the parser based on the file type.
+func ParseConfigurationsWithOptions(files []string, parser string, options Options) (map[string]interface{}, error) {
+	configurations, _, err := parseConfigurations(files, parser, options)
 	if err != nil {
 		return nil, err
 	}
@@ -192,9 +448,56 @@ func ParseConfigurationsAs(files []string, parser string) (map[string]interface{
 	return configurations, nil
 }
 
+// RemoveEmptyConfigurations removes the documents that are empty, such as the document of
+// an empty YAML file, from the given configurations. Files whose documents are all empty
+// are removed entirely, and their paths are returned in sorted order.
+func RemoveEmptyConfigurations(configs map[string]interface{}) []string {
+	var emptyFiles []string
+	for path, config := range configs {
+		subconfigs, exist := config.([]interface{})
+		if !exist {
+			if isEmptyConfiguration(config) {
+				delete(configs, path)
+				emptyFiles = append(emptyFiles, path)
+			}
+			continue
+		}
+
+		var nonEmpty []interface{}
+		for _, subconfig := range subconfigs {
+			if !isEmptyConfiguration(subconfig) {
+				nonEmpty = append(nonEmpty, subconfig)
+			}
+		}
+
+		if len(nonEmpty) == 0 {
+			delete(configs, path)
+			emptyFiles = append(emptyFiles, path)
+			continue
+		}
+
+		configs[path] = nonEmpty
+	}
+
+	sort.Strings(emptyFiles)
+	return emptyFiles
+}
+
+func isEmptyConfiguration(config interface{}) bool {
+	if config == nil {
+		return true
+	}
+
+	object, ok := config.(map[string]interface{})
+	return ok && len(object) == 0
+}
+
 // CombineConfigurations takes the given configurations and combines them into a single
 // configuration. The result will be a map that contains a single key with a value of
 // Combined.
+//
+// Each configuration is identified by its full path rather than its file name, so that
+// files with the same name in different directories do not collide.
 func CombineConfigurations(configs map[string]interface{}) map[string]interface{} {
 	type configuration struct {
 		Path     string      `json:"path"`
@@ -235,34 +538,296 @@ func CombineConfigurations(configs map[string]interface{}) map[string]interface{
 	return combinedConfigurations
 }
 
-func parseConfigurations(paths []string, parser string) (map[string]interface{}, error) {
+// NewFromPathAs returns a file parser for the file at the given path using the given parser.
+//
+// The parser can either be the name of a single parser that is used for every file, or a
+// comma-separated list of extension overrides (e.g. .conf=ini,.cfg=toml). When overrides
+// are given, files with an extension that is not overridden fall back to NewFromPath.
+//
+// The parser can also be a comma-separated list of parser names (e.g. yaml,hcl2), which
+// restricts the files to those parsers. The parser of every file is detected as it is by
+// NewFromPath, and an error is returned for files that are detected as any other parser.
+// The list can also contain extensions instead of parser names (e.g. yaml,tf).
+func NewFromPathAs(path string, parser string) (Parser, error) {
+	if parser == "" {
+		return NewFromPath(path)
+	}
+
+	name, err := NameFromPathAs(path, parser)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(name)
+}
+
+// NameFromPathAs returns the name of the parser that NewFromPathAs uses
+// for the file at the given path, e.g. yaml or dockerfile.
+func NameFromPathAs(path string, parser string) (string, error) {
+	if parser == "" {
+		return nameFromPath(path), nil
+	}
+
+	if !strings.Contains(parser, "=") {
+		if strings.Contains(parser, ",") {
+			return nameFromAllowed(path, parser)
+		}
+
+		return parser, nil
+	}
+
+	overrides, err := parseOverrides(parser)
+	if err != nil {
+		return "", fmt.Errorf("parse overrides: %w", err)
+	}
+
+	uncompressed, _ := trimCompression(path)
+
+	// Overrides can be compound extensions, such as .auto.tfvars or .tfvars.json,
+	// in which case the longest extension that the file name ends with is used.
+	fileName := strings.ToLower(filepath.Base(uncompressed))
+	var match string
+	for extension := range overrides {
+		if strings.HasSuffix(fileName, extension) && len(extension) > len(match) {
+			match = extension
+		}
+	}
+
+	if match != "" {
+		return overrides[match], nil
+	}
+
+	return nameFromPath(path), nil
+}
+
+// nameFromAllowed returns the detected name of the parser for the file at the given
+// path, when it is one of the comma-separated parsers that are allowed. The allowed
+// parsers can also be given by an extension that selects them, e.g. tf for hcl2.
+func nameFromAllowed(path string, value string) (string, error) {
+	var allowed []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if _, err := New(name); err != nil {
+			alias := nameFromPath("file." + strings.TrimPrefix(name, "."))
+			if _, aliasErr := New(alias); aliasErr != nil {
+				return "", fmt.Errorf("allowed parsers: %w", err)
+			}
+
+			name = alias
+		}
+
+		allowed = append(allowed, name)
+	}
+
+	name := nameFromPath(path)
+	for _, allowedName := range allowed {
+		if name == allowedName {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("%v is detected as %q, which is not one of the allowed parsers (%v)", path, name, strings.Join(allowed, ", "))
+}
+
+// trimCompression returns the path without its compression extension (e.g. .gz),
+// and whether the path had a compression extension.
+func trimCompression(path string) (string, bool) {
+	if strings.ToLower(filepath.Ext(path)) != ".gz" {
+		return path, false
+	}
+
+	return path[:len(path)-len(filepath.Ext(path))], true
+}
+
+// recognizedNames are the names of the files that are recognized by their
+// name rather than their extension, as listed in the errors of CheckRecognized.
+var recognizedNames = []string{
+	"Cargo.lock",
+	"Dockerfile",
+	"Gemfile.lock",
+	"GNUmakefile",
+	"Makefile",
+	"gems.locked",
+	"pyproject.toml",
+	"requirements*.txt",
+}
+
+// RecognizedExtensions returns the sorted file extensions that select a parser,
+// e.g. .toml, .yml and .tf.
+func RecognizedExtensions() []string {
+	var extensions []string
+	for extension := range extensionParsers {
+		extensions = append(extensions, "."+extension)
+	}
+
+	sort.Strings(extensions)
+	return extensions
+}
+
+// CheckRecognized returns an error when the file at the given path is not recognized
+// by its name or extension, which means that it would only be parsed as YAML because
+// YAML is the default for files without an extension. The error lists the recognized
+// extensions. Files are always recognized when the parser forces a single parser, as
+// is standard input. See NewFromPathAs for the supported values of the parser.
+func CheckRecognized(path string, parser string) error {
+	if path == "-" || (parser != "" && !strings.ContainsAny(parser, "=,")) {
+		return nil
+	}
+
+	name, err := NameFromPathAs(path, parser)
+	if err != nil {
+		return err
+	}
+
+	uncompressed, _ := trimCompression(path)
+	_, unknownErr := New(name)
+	if unknownErr == nil && (name != YAML || filepath.Ext(uncompressed) != "") {
+		return nil
+	}
+
+	return fmt.Errorf("%v is not a recognized file type. Recognized extensions: %v. Recognized file names: %v", path, strings.Join(RecognizedExtensions(), ", "), strings.Join(recognizedNames, ", "))
+}
+
+// FileSupportedAs returns true if the file at the given path is a file that
+// can be parsed, taking any extension overrides and allowed parsers into account.
+func FileSupportedAs(path string, parser string) bool {
+	if strings.Contains(parser, "=") || strings.Contains(parser, ",") {
+		_, err := NewFromPathAs(path, parser)
+		return err == nil
+	}
+
+	return FileSupported(path)
+}
+
+// contentDetectors are the detectors of the parsers for extensions that are shared by
+// several formats, which return whether contents are in a format that the parser parses.
+var contentDetectors = map[string]func([]byte) bool{
+	CONF: conf.Detect,
+}
+
+// ContentsSupportedAs returns false when the file at the given path is parsed by a
+// parser for an extension that is shared by several formats, such as the conf parser
+// for .conf files, and the contents of the file are in a format that the parser does
+// not parse, such as an nginx configuration. Files are always supported when the
+// parser forces a single parser. See NewFromPathAs for the supported values of the parser.
+func ContentsSupportedAs(path string, parser string) (bool, error) {
+	if path == "-" || (parser != "" && !strings.ContainsAny(parser, "=,")) {
+		return true, nil
+	}
+
+	name, err := NameFromPathAs(path, parser)
+	if err != nil {
+		return false, err
+	}
+
+	detect, ok := contentDetectors[name]
+	if !ok {
+		return true, nil
+	}
+
+	contents, err := getConfigurationContent(path)
+	if err != nil {
+		return false, fmt.Errorf("get configuration content: %w", err)
+	}
+
+	return detect(contents), nil
+}
+
+// parseOverrides parses a comma-separated list of extension overrides
+// (e.g. .conf=ini,.cfg=toml) into a map of extension to parser name.
+func parseOverrides(value string) (map[string]string, error) {
+	overrides := make(map[string]string)
+	for _, override := range strings.Split(value, ",") {
+		parts := strings.SplitN(strings.TrimSpace(override), "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid override %q, expected <extension>=<parser>", override)
+		}
+
+		extension := strings.ToLower(parts[0])
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+
+		if _, err := New(parts[1]); err != nil {
+			return nil, fmt.Errorf("override %q: %w", override, err)
+		}
+
+		overrides[extension] = parts[1]
+	}
+
+	return overrides, nil
+}
+
+func parseConfigurations(paths []string, parser string, options Options) (map[string]interface{}, map[string][]string, error) {
 	parsedConfigurations := make(map[string]interface{})
+	sources := make(map[string][]string)
 	for _, path := range paths {
-		var fileParser Parser
-		var err error
-		if parser == "" {
-			fileParser, err = NewFromPath(path)
-		} else {
-			fileParser, err = New(parser)
+		if options.StrictInput {
+			if err := CheckRecognized(path, parser); err != nil {
+				return nil, nil, err
+			}
 		}
+
+		fileParser, err := NewFromPathAs(path, parser)
 		if err != nil {
-			return nil, fmt.Errorf("new parser: %w", err)
+			return nil, nil, fmt.Errorf("new parser: %w", err)
+		}
+
+		yamlParser, isYAML := fileParser.(*yaml.Parser)
+		if isYAML {
+			yamlParser.Strict = options.StrictYAML
+		}
+
+		if dhallParser, ok := fileParser.(*dhall.Parser); ok {
+			dhallParser.Path = path
+			dhallParser.NoRemote = options.DhallNoRemote
+		}
+
+		if csvParser, ok := fileParser.(*csv.Parser); ok {
+			csvParser.NoHeader = options.CSVNoHeader
+		}
+
+		if jsonnetParser, ok := fileParser.(*jsonnet.Parser); ok {
+			jsonnetParser.Path = path
+			jsonnetParser.JPath = options.JsonnetPath
 		}
 
 		contents, err := getConfigurationContent(path)
 		if err != nil {
-			return nil, fmt.Errorf("get configuration content: %w", err)
+			return nil, nil, fmt.Errorf("get configuration content: %w", err)
+		}
+
+		// The contents with the rewritten include tags are only parsed, so that
+		// the source comments are read from the contents of the file itself.
+		parseContents := contents
+		resolveFileIncludes := options.ResolveIncludes && supportsIncludes(fileParser)
+		if resolveFileIncludes && isYAML {
+			parseContents, err = rewriteIncludeTags(contents)
+			if err != nil {
+				return nil, nil, fmt.Errorf("rewrite include tags of %v: %w", path, err)
+			}
 		}
 
 		var parsed interface{}
-		if err := fileParser.Unmarshal(contents, &parsed); err != nil {
-			return nil, fmt.Errorf("parser unmarshal: %w", err)
+		if err := fileParser.Unmarshal(parseContents, &parsed); err != nil {
+			return nil, nil, fmt.Errorf("parser unmarshal: %w", err)
+		}
+
+		if resolveFileIncludes {
+			parsed, err = resolveIncludes(path, parsed, options.RemoteIncludes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("resolve includes of %v: %w", path, err)
+			}
 		}
 
 		parsedConfigurations[path] = parsed
+
+		if isYAML && options.HelmSourceComments {
+			sources[path] = yaml.SourceComments(contents)
+		}
 	}
 
-	return parsedConfigurations, nil
+	return parsedConfigurations, sources, nil
 }
 
 func getConfigurationContent(path string) ([]byte, error) {
@@ -285,5 +850,18 @@ func getConfigurationContent(path string) ([]byte, error) {
 		return nil, fmt.Errorf("open file: %w", err)
 	}
 
+	if _, ok := trimCompression(path); ok {
+		reader, err := gzip.NewReader(bytes.NewReader(contents))
+		if err != nil {
+			return nil, fmt.Errorf("open gzip: %w", err)
+		}
+		defer reader.Close()
+
+		contents, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("read gzip: %w", err)
+		}
+	}
+
 	return contents, nil
 }
@@ -1,12 +1,40 @@
 package parser
 
 import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/open-policy-agent/conftest/parser/avro"
+	"github.com/open-policy-agent/conftest/parser/cargolock"
+	"github.com/open-policy-agent/conftest/parser/conf"
+	"github.com/open-policy-agent/conftest/parser/csv"
 	"github.com/open-policy-agent/conftest/parser/docker"
+	"github.com/open-policy-agent/conftest/parser/gemfilelock"
+	"github.com/open-policy-agent/conftest/parser/hcl1"
 	"github.com/open-policy-agent/conftest/parser/hcl2"
+	"github.com/open-policy-agent/conftest/parser/hocon"
 	"github.com/open-policy-agent/conftest/parser/ignore"
+	"github.com/open-policy-agent/conftest/parser/ini"
+	jsonparser "github.com/open-policy-agent/conftest/parser/json"
+	"github.com/open-policy-agent/conftest/parser/json5"
+	"github.com/open-policy-agent/conftest/parser/jsonnet"
+	"github.com/open-policy-agent/conftest/parser/makefile"
+	"github.com/open-policy-agent/conftest/parser/plist"
+	"github.com/open-policy-agent/conftest/parser/prometheusrules"
+	"github.com/open-policy-agent/conftest/parser/proto"
+	"github.com/open-policy-agent/conftest/parser/pyproject"
+	"github.com/open-policy-agent/conftest/parser/requirements"
+	"github.com/open-policy-agent/conftest/parser/sentinel"
+	"github.com/open-policy-agent/conftest/parser/systemdnetwork"
+	"github.com/open-policy-agent/conftest/parser/tfstate"
+	"github.com/open-policy-agent/conftest/parser/toml"
 	"github.com/open-policy-agent/conftest/parser/yaml"
 )
 
@@ -21,6 +49,16 @@ func TestNewFromPath(t *testing.T) {
 			&yaml.Parser{},
 			false,
 		},
+		{
+			"nginx.conf",
+			&conf.Parser{},
+			false,
+		},
+		{
+			"setup.CFG",
+			&conf.Parser{},
+			false,
+		},
 		{
 			"test.yaml",
 			&yaml.Parser{},
@@ -81,6 +119,141 @@ func TestNewFromPath(t *testing.T) {
 			&ignore.Parser{},
 			false,
 		},
+		{
+			"test.json5",
+			&json5.Parser{},
+			false,
+		},
+		{
+			"deployment.jsonnet",
+			&jsonnet.Parser{},
+			false,
+		},
+		{
+			"lib/labels.libsonnet",
+			&jsonnet.Parser{},
+			false,
+		},
+		{
+			".npmignore",
+			&ignore.Parser{},
+			false,
+		},
+		{
+			"test.proto",
+			&proto.Parser{},
+			false,
+		},
+		{
+			"user.avsc",
+			&avro.Parser{},
+			false,
+		},
+		{
+			"10-eth0.network",
+			&systemdnetwork.Parser{},
+			false,
+		},
+		{
+			"bond0.netdev",
+			&systemdnetwork.Parser{},
+			false,
+		},
+		{
+			"pyproject.toml",
+			&pyproject.Parser{},
+			false,
+		},
+		{
+			"exports/users.csv",
+			&csv.Parser{},
+			false,
+		},
+		{
+			"restrict-instance-types.sentinel",
+			&sentinel.Parser{},
+			false,
+		},
+		{
+			"Makefile",
+			&makefile.Parser{},
+			false,
+		},
+		{
+			"GNUmakefile",
+			&makefile.Parser{},
+			false,
+		},
+		{
+			"build/rules.mk",
+			&makefile.Parser{},
+			false,
+		},
+		{
+			"Cargo.lock",
+			&cargolock.Parser{},
+			false,
+		},
+		{
+			"app/Gemfile.lock",
+			&gemfilelock.Parser{},
+			false,
+		},
+		{
+			"gems.locked",
+			&gemfilelock.Parser{},
+			false,
+		},
+		{
+			"yarn.lock",
+			nil,
+			true,
+		},
+		{
+			"requirements.txt",
+			&requirements.Parser{},
+			false,
+		},
+		{
+			"requirements-dev.txt",
+			&requirements.Parser{},
+			false,
+		},
+		{
+			"terraform.tfstate",
+			&tfstate.Parser{},
+			false,
+		},
+		{
+			"com.example.backup.plist",
+			&plist.Parser{},
+			false,
+		},
+		{
+			"terraform.auto.tfvars",
+			&hcl2.Parser{},
+			false,
+		},
+		{
+			"terraform.tfvars.json",
+			&jsonparser.Parser{},
+			false,
+		},
+		{
+			"deployment.yaml.gz",
+			&yaml.Parser{},
+			false,
+		},
+		{
+			"terraform.tfvars.GZ",
+			&hcl2.Parser{},
+			false,
+		},
+		{
+			"archive.tar.gz",
+			nil,
+			true,
+		},
 		{
 			"file.unknown",
 			nil,
@@ -105,3 +278,419 @@ func TestNewFromPath(t *testing.T) {
 		})
 	}
 }
+
+func TestCombineConfigurationsWithDuplicateFileNames(t *testing.T) {
+	root := t.TempDir()
+
+	var files []string
+	for _, directory := range []string{"frontend", "backend"} {
+		if err := os.Mkdir(filepath.Join(root, directory), os.ModePerm); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+
+		file := filepath.Join(root, directory, "values.yaml")
+		if err := ioutil.WriteFile(file, []byte("service: "+directory), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+
+		files = append(files, file)
+	}
+
+	configurations, err := ParseConfigurations(files)
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	combined, err := json.Marshal(CombineConfigurations(configurations)["Combined"])
+	if err != nil {
+		t.Fatalf("marshal combined configurations: %v", err)
+	}
+
+	var actual []map[string]interface{}
+	if err := json.Unmarshal(combined, &actual); err != nil {
+		t.Fatalf("unmarshal combined configurations: %v", err)
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"path":     filepath.Join(root, "backend", "values.yaml"),
+			"contents": map[string]interface{}{"service": "backend"},
+		},
+		{
+			"path":     filepath.Join(root, "frontend", "values.yaml"),
+			"contents": map[string]interface{}{"service": "frontend"},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected combined configurations. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestNewFromPathAs(t *testing.T) {
+	testCases := []struct {
+		path     string
+		parser   string
+		expected Parser
+		wantErr  bool
+	}{
+		{
+			path:     "test.conf",
+			parser:   "hocon",
+			expected: &hocon.Parser{},
+		},
+		{
+			path:     "test.conf",
+			parser:   ".conf=ini,.cfg=toml",
+			expected: &ini.Parser{},
+		},
+		{
+			path:     "test.CFG",
+			parser:   ".conf=ini,cfg=toml",
+			expected: &toml.Parser{},
+		},
+		{
+			path:     "test.yaml",
+			parser:   ".conf=ini,.cfg=toml",
+			expected: &yaml.Parser{},
+		},
+		{
+			path:     "prod.auto.tfvars",
+			parser:   ".tfvars=hcl1,.auto.tfvars=hcl2",
+			expected: &hcl2.Parser{},
+		},
+		{
+			path:     "prod.tfvars",
+			parser:   ".tfvars=hcl1,.auto.tfvars=hcl2",
+			expected: &hcl1.Parser{},
+		},
+		{
+			path:     "prod.tfvars.json",
+			parser:   ".tfvars=hcl1",
+			expected: &jsonparser.Parser{},
+		},
+		{
+			path:     "alerts.yaml",
+			parser:   "prometheus-rules",
+			expected: &prometheusrules.Parser{},
+		},
+		{
+			path:     "nginx.conf.gz",
+			parser:   ".conf=ini",
+			expected: &ini.Parser{},
+		},
+		{
+			path:     "deployment.yaml",
+			parser:   "yaml,hcl2",
+			expected: &yaml.Parser{},
+		},
+		{
+			path:     "main.tf",
+			parser:   "yaml, hcl2",
+			expected: &hcl2.Parser{},
+		},
+		{
+			path:    "config.json",
+			parser:  "yaml,hcl2",
+			wantErr: true,
+		},
+		{
+			path:    "README",
+			parser:  "json,hcl2",
+			wantErr: true,
+		},
+		{
+			path:     "main.tf",
+			parser:   "yaml,tf",
+			expected: &hcl2.Parser{},
+		},
+		{
+			path:     "deployment.yml",
+			parser:   "json,.yml",
+			expected: &yaml.Parser{},
+		},
+		{
+			path:    "config.json",
+			parser:  "yaml,tf",
+			wantErr: true,
+		},
+		{
+			path:    "main.tf",
+			parser:  "yaml,unknown",
+			wantErr: true,
+		},
+		{
+			path:    "test.unknown",
+			parser:  ".conf=ini",
+			wantErr: true,
+		},
+		{
+			path:    "test.conf",
+			parser:  ".conf=unknown",
+			wantErr: true,
+		},
+		{
+			path:    "test.conf",
+			parser:  ".conf=ini,.cfg",
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.path+" "+testCase.parser, func(t *testing.T) {
+			actual, err := NewFromPathAs(testCase.path, testCase.parser)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal("from path as:", err)
+			}
+
+			if reflect.TypeOf(actual) != reflect.TypeOf(testCase.expected) {
+				t.Errorf("Unexpected parser. expected %T actual %T", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFileSupportedAsAllowedParsers(t *testing.T) {
+	testCases := map[string]bool{
+		"deployment.yaml":    true,
+		"main.tf":            true,
+		"package.json":       false,
+		"Dockerfile":         false,
+		"deployment.yaml.gz": true,
+	}
+
+	for path, expected := range testCases {
+		if actual := FileSupportedAs(path, "yaml,hcl2"); actual != expected {
+			t.Errorf("Unexpected support of %v. expected %v actual %v", path, expected, actual)
+		}
+	}
+}
+
+func TestContentsSupportedAs(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"app.conf":   "[server]\nport = 8080",
+		"nginx.conf": "user nginx;\nworker_processes auto;",
+		"app.yaml":   "user nginx;",
+	}
+
+	for name, contents := range files {
+		if err := ioutil.WriteFile(filepath.Join(root, name), []byte(contents), 0600); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+
+	testCases := []struct {
+		path     string
+		parser   string
+		expected bool
+	}{
+		{path: "app.conf", expected: true},
+		{path: "nginx.conf", expected: false},
+		{path: "nginx.conf", parser: "conf", expected: true},
+		{path: "nginx.conf", parser: "conf,yaml", expected: false},
+		{path: "app.yaml", expected: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.path+" "+testCase.parser, func(t *testing.T) {
+			actual, err := ContentsSupportedAs(filepath.Join(root, testCase.path), testCase.parser)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if actual != testCase.expected {
+				t.Errorf("Unexpected support. expected %v actual %v", testCase.expected, actual)
+			}
+		})
+	}
+}
+
+func TestFileSupportedCompressed(t *testing.T) {
+	testCases := map[string]bool{
+		"deployment.yaml.gz":  true,
+		"terraform.tfvars.gz": true,
+		"Dockerfile.gz":       true,
+		"access.log.gz":       false,
+		"backup.tar.gz":       false,
+		"LICENSE.gz":          false,
+	}
+
+	for path, expected := range testCases {
+		if actual := FileSupported(path); actual != expected {
+			t.Errorf("Unexpected support of %v. expected %v actual %v", path, expected, actual)
+		}
+
+		if actual := FileSupportedAs(path, ".conf=ini"); actual != expected {
+			t.Errorf("Unexpected support of %v with overrides. expected %v actual %v", path, expected, actual)
+		}
+	}
+}
+
+func TestCheckRecognized(t *testing.T) {
+	testCases := []struct {
+		path    string
+		parser  string
+		wantErr bool
+	}{
+		{path: "deployment.yaml"},
+		{path: "main.tf"},
+		{path: "Dockerfile"},
+		{path: "Makefile"},
+		{path: "deployment.yaml.gz"},
+		{path: "-"},
+		{path: "LICENSE", wantErr: true},
+		{path: "LICENSE.gz", wantErr: true},
+		{path: "notes.unknown", wantErr: true},
+		{path: "LICENSE", parser: "yaml"},
+		{path: "notes.unknown", parser: ".unknown=yaml"},
+		{path: "LICENSE", parser: ".conf=ini", wantErr: true},
+		{path: "LICENSE", parser: "yaml,json", wantErr: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.path+" "+testCase.parser, func(t *testing.T) {
+			err := CheckRecognized(testCase.path, testCase.parser)
+			if testCase.wantErr && err == nil {
+				t.Fatal("expected an error")
+			}
+
+			if !testCase.wantErr && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestCheckRecognizedListsExtensions(t *testing.T) {
+	err := CheckRecognized("LICENSE", "")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, extension := range []string{".yaml", ".yml", ".json", ".tf"} {
+		if !strings.Contains(err.Error(), extension) {
+			t.Errorf("expected the error to list the %v extension, got: %v", extension, err)
+		}
+	}
+}
+
+func TestRecognizedExtensions(t *testing.T) {
+	extensions := RecognizedExtensions()
+
+	// Every recognized extension must select the parser that it is listed for,
+	// and the names of parsers that are not extensions must not be listed.
+	for _, extension := range extensions {
+		if name := nameFromPath("file" + extension); name == YAML && extension != ".yaml" && extension != ".yml" {
+			t.Errorf("extension %v is parsed as YAML by default", extension)
+		}
+
+		if _, err := NewFromPath("file" + extension); err != nil {
+			t.Errorf("extension %v does not select a parser: %v", extension, err)
+		}
+	}
+
+	for _, name := range []string{CONFIGMAPENV, PROMETHEUSRULES, PROPERTIESORDERED, REQUIREMENTS} {
+		for _, extension := range extensions {
+			if extension == "."+name {
+				t.Errorf("expected the parser name %v not to be listed as an extension", name)
+			}
+		}
+	}
+}
+
+func TestRemoveEmptyConfigurations(t *testing.T) {
+	root := t.TempDir()
+
+	contents := map[string]string{
+		"empty.yaml":     "",
+		"team.yaml":      "team: platform",
+		"documents.yaml": "user: alice\n---\n\n---\nuser: bob",
+	}
+
+	var files []string
+	for name, content := range contents {
+		file := filepath.Join(root, name)
+		if err := ioutil.WriteFile(file, []byte(content), os.ModePerm); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+
+		files = append(files, file)
+	}
+
+	configurations, err := ParseConfigurations(files)
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	emptyFiles := RemoveEmptyConfigurations(configurations)
+
+	expectedEmptyFiles := []string{filepath.Join(root, "empty.yaml")}
+	if !reflect.DeepEqual(expectedEmptyFiles, emptyFiles) {
+		t.Errorf("Unexpected empty files. expected %v actual %v", expectedEmptyFiles, emptyFiles)
+	}
+
+	combined, err := json.Marshal(CombineConfigurations(configurations)["Combined"])
+	if err != nil {
+		t.Fatalf("marshal combined configurations: %v", err)
+	}
+
+	var actual []map[string]interface{}
+	if err := json.Unmarshal(combined, &actual); err != nil {
+		t.Fatalf("unmarshal combined configurations: %v", err)
+	}
+
+	expected := []map[string]interface{}{
+		{
+			"path":     filepath.Join(root, "documents.yaml"),
+			"contents": map[string]interface{}{"user": "alice"},
+		},
+		{
+			"path":     filepath.Join(root, "documents.yaml"),
+			"contents": map[string]interface{}{"user": "bob"},
+		},
+		{
+			"path":     filepath.Join(root, "team.yaml"),
+			"contents": map[string]interface{}{"team": "platform"},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected combined configurations. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestParseCompressedConfigurations(t *testing.T) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte("team: platform")); err != nil {
+		t.Fatalf("write gzip: %v", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close gzip: %v", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "team.yaml.gz")
+	if err := ioutil.WriteFile(file, buf.Bytes(), os.ModePerm); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	configurations, err := ParseConfigurations([]string{file})
+	if err != nil {
+		t.Fatalf("parse configurations: %v", err)
+	}
+
+	expected := map[string]interface{}{"team": "platform"}
+	if !reflect.DeepEqual(expected, configurations[file]) {
+		t.Errorf("Unexpected configuration. expected %v actual %v", expected, configurations[file])
+	}
+}
@@ -0,0 +1,314 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ApplyPatch returns the result of applying the operations to the target as a
+// JSON Patch (RFC 6902). Every operation is an object with an op, which is one
+// of add, remove, replace, move, copy and test, and a path, which is a JSON
+// Pointer (RFC 6901). The operations are applied in order, and an error is
+// returned for the first operation that cannot be applied. The target is not
+// modified.
+func ApplyPatch(target interface{}, operations []interface{}) (interface{}, error) {
+	document := deepCopy(target)
+	for i, operation := range operations {
+		var err error
+		document, err = applyOperation(document, operation)
+		if err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return document, nil
+}
+
+func applyOperation(document interface{}, operation interface{}) (interface{}, error) {
+	fields, ok := operation.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("operation must be an object")
+	}
+
+	op, ok := fields["op"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing op")
+	}
+
+	path, err := operationPointer(fields, "path")
+	if err != nil {
+		return nil, err
+	}
+
+	switch op {
+	case "add", "replace", "test":
+		value, ok := fields["value"]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing value", op)
+		}
+
+		if op == "test" {
+			current, err := pointerGet(document, path)
+			if err != nil {
+				return nil, fmt.Errorf("test: %w", err)
+			}
+
+			if !jsonEqual(current, value) {
+				return nil, fmt.Errorf("test: value at %q does not match", fields["path"])
+			}
+
+			return document, nil
+		}
+
+		if op == "replace" && len(path) > 0 {
+			if document, err = pointerRemove(document, path); err != nil {
+				return nil, fmt.Errorf("replace: %w", err)
+			}
+		}
+
+		return pointerAdd(document, path, deepCopy(value))
+
+	case "remove":
+		return pointerRemove(document, path)
+
+	case "move", "copy":
+		from, err := operationPointer(fields, "from")
+		if err != nil {
+			return nil, err
+		}
+
+		value, err := pointerGet(document, from)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if op == "move" {
+			if document, err = pointerRemove(document, from); err != nil {
+				return nil, fmt.Errorf("move: %w", err)
+			}
+		}
+
+		return pointerAdd(document, path, deepCopy(value))
+
+	default:
+		return nil, fmt.Errorf("unknown op %q", op)
+	}
+}
+
+// operationPointer returns the JSON Pointer in the given field of the
+// operation as a list of reference tokens.
+func operationPointer(operation map[string]interface{}, field string) ([]string, error) {
+	pointer, ok := operation[field].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing %s", field)
+	}
+
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", field, err)
+	}
+
+	return tokens, nil
+}
+
+// parsePointer returns the reference tokens of the given JSON Pointer.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("pointer %q must start with /", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// pointerGet returns the value at the given path of the document.
+func pointerGet(document interface{}, path []string) (interface{}, error) {
+	current := document
+	for _, token := range path {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			child, ok := value[token]
+			if !ok {
+				return nil, fmt.Errorf("key %q not found", token)
+			}
+			current = child
+
+		case []interface{}:
+			index, err := arrayIndex(token, len(value)-1)
+			if err != nil {
+				return nil, err
+			}
+			current = value[index]
+
+		default:
+			return nil, fmt.Errorf("cannot get %q of a value that is not an object or an array", token)
+		}
+	}
+
+	return current, nil
+}
+
+// pointerAdd returns the document with the value added at the given path. Adding a
+// value to an object sets the key, while adding a value to an array inserts it
+// at the index, where - appends it to the end of the array.
+func pointerAdd(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, fmt.Errorf("add: %w", err)
+	}
+
+	token := path[len(path)-1]
+	switch parentValue := parent.(type) {
+	case map[string]interface{}:
+		parentValue[token] = value
+		return document, nil
+
+	case []interface{}:
+		index := len(parentValue)
+		if token != "-" {
+			index, err = arrayIndex(token, len(parentValue))
+			if err != nil {
+				return nil, fmt.Errorf("add: %w", err)
+			}
+		}
+
+		updated := make([]interface{}, 0, len(parentValue)+1)
+		updated = append(updated, parentValue[:index]...)
+		updated = append(updated, value)
+		updated = append(updated, parentValue[index:]...)
+		return pointerReplace(document, path[:len(path)-1], updated)
+
+	default:
+		return nil, fmt.Errorf("add: cannot add %q to a value that is not an object or an array", token)
+	}
+}
+
+// pointerRemove returns the document with the value at the given path removed.
+func pointerRemove(document interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("remove: cannot remove the whole document")
+	}
+
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, fmt.Errorf("remove: %w", err)
+	}
+
+	token := path[len(path)-1]
+	switch parentValue := parent.(type) {
+	case map[string]interface{}:
+		if _, ok := parentValue[token]; !ok {
+			return nil, fmt.Errorf("remove: key %q not found", token)
+		}
+
+		delete(parentValue, token)
+		return document, nil
+
+	case []interface{}:
+		index, err := arrayIndex(token, len(parentValue)-1)
+		if err != nil {
+			return nil, fmt.Errorf("remove: %w", err)
+		}
+
+		updated := make([]interface{}, 0, len(parentValue)-1)
+		updated = append(updated, parentValue[:index]...)
+		updated = append(updated, parentValue[index+1:]...)
+		return pointerReplace(document, path[:len(path)-1], updated)
+
+	default:
+		return nil, fmt.Errorf("remove: cannot remove %q from a value that is not an object or an array", token)
+	}
+}
+
+// pointerReplace returns the document with the value at the given path replaced.
+// Arrays change length when values are added or removed, so the array itself
+// has to be replaced in its parent.
+func pointerReplace(document interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := pointerGet(document, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	token := path[len(path)-1]
+	switch parentValue := parent.(type) {
+	case map[string]interface{}:
+		parentValue[token] = value
+	case []interface{}:
+		index, err := arrayIndex(token, len(parentValue)-1)
+		if err != nil {
+			return nil, err
+		}
+		parentValue[index] = value
+	}
+
+	return document, nil
+}
+
+// arrayIndex parses the token as an index of an array, which must
+// not be greater than the given last index.
+func arrayIndex(token string, last int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+
+	if index > last {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+
+	return index, nil
+}
+
+// deepCopy returns a copy of the value where all of the objects
+// and arrays are copied, so that the copy can be modified.
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			copied[key] = deepCopy(child)
+		}
+		return copied
+
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, child := range v {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+
+	default:
+		return v
+	}
+}
+
+// jsonEqual returns true when both values are equal once they are encoded as
+// JSON, so that numbers of different types, such as 1 and 1.0, are equal.
+func jsonEqual(a interface{}, b interface{}) bool {
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	if aErr != nil || bErr != nil {
+		return reflect.DeepEqual(a, b)
+	}
+
+	return string(aJSON) == string(bJSON)
+}
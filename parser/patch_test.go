@@ -0,0 +1,133 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	target := func() interface{} {
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"replicas": 1.0,
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:1.0"},
+				},
+			},
+			"a/b": "slash",
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		operations []interface{}
+		expected   interface{}
+		wantErr    bool
+	}{
+		{
+			name: "add replace and remove",
+			operations: []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/spec/replicas", "value": 3.0},
+				map[string]interface{}{"op": "add", "path": "/spec/containers/-", "value": map[string]interface{}{"name": "sidecar"}},
+				map[string]interface{}{"op": "remove", "path": "/a~1b"},
+			},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 3.0,
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:1.0"},
+						map[string]interface{}{"name": "sidecar"},
+					},
+				},
+			},
+		},
+		{
+			name: "insert into an array",
+			operations: []interface{}{
+				map[string]interface{}{"op": "add", "path": "/spec/containers/0", "value": "first"},
+			},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 1.0,
+					"containers": []interface{}{
+						"first",
+						map[string]interface{}{"name": "app", "image": "app:1.0"},
+					},
+				},
+				"a/b": "slash",
+			},
+		},
+		{
+			name: "move copy and test",
+			operations: []interface{}{
+				map[string]interface{}{"op": "test", "path": "/spec/replicas", "value": 1},
+				map[string]interface{}{"op": "copy", "from": "/spec/containers/0/image", "path": "/image"},
+				map[string]interface{}{"op": "move", "from": "/a~1b", "path": "/spec/note"},
+			},
+			expected: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"replicas": 1.0,
+					"containers": []interface{}{
+						map[string]interface{}{"name": "app", "image": "app:1.0"},
+					},
+					"note": "slash",
+				},
+				"image": "app:1.0",
+			},
+		},
+		{
+			name: "failed test",
+			operations: []interface{}{
+				map[string]interface{}{"op": "test", "path": "/spec/replicas", "value": 2.0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "remove missing key",
+			operations: []interface{}{
+				map[string]interface{}{"op": "remove", "path": "/spec/missing"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "index out of bounds",
+			operations: []interface{}{
+				map[string]interface{}{"op": "replace", "path": "/spec/containers/1", "value": "x"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown op",
+			operations: []interface{}{
+				map[string]interface{}{"op": "merge", "path": "/spec"},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			original := target()
+			actual, err := ApplyPatch(original, testCase.operations)
+			if testCase.wantErr {
+				if err == nil {
+					t.Error("expected an error")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("apply patch: %v", err)
+			}
+
+			if !reflect.DeepEqual(testCase.expected, actual) {
+				t.Errorf("Unexpected result. expected %v actual %v", testCase.expected, actual)
+			}
+
+			if !reflect.DeepEqual(target(), original) {
+				t.Errorf("Expected the target to be unchanged. actual %v", original)
+			}
+		})
+	}
+}
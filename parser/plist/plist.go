@@ -0,0 +1,218 @@
+package plist
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Parser is a parser for property lists in the XML format, as used by macOS.
+type Parser struct{}
+
+// Unmarshal unmarshals XML property lists.
+//
+// A dict becomes an object, an array becomes an array, string, date and data
+// values become strings, integer and real values become numbers, and true and
+// false become booleans. Data values are kept as their base64 encoding. Binary
+// property lists are not supported, and can be converted to XML with
+// plutil -convert xml1.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var root interface{}
+	var found bool
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read plist: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local == "plist" {
+			continue
+		}
+
+		if found {
+			return fmt.Errorf("plist contains more than one root value")
+		}
+
+		root, err = decodeValue(decoder, start)
+		if err != nil {
+			return fmt.Errorf("decode plist: %w", err)
+		}
+		found = true
+	}
+
+	if !found {
+		return fmt.Errorf("plist does not contain a value")
+	}
+
+	j, err := json.Marshal(root)
+	if err != nil {
+		return fmt.Errorf("marshal plist to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal plist json: %w", err)
+	}
+
+	return nil
+}
+
+// decodeValue decodes the value of the element that starts with the given start element.
+func decodeValue(decoder *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "dict":
+		return decodeDict(decoder)
+
+	case "array":
+		array := []interface{}{}
+		for {
+			child, err := nextElement(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			if child == nil {
+				return array, nil
+			}
+
+			value, err := decodeValue(decoder, *child)
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+		}
+
+	case "true", "false":
+		if err := decoder.Skip(); err != nil {
+			return nil, err
+		}
+
+		return start.Name.Local == "true", nil
+
+	case "integer":
+		text, err := elementText(decoder, start)
+		if err != nil {
+			return nil, err
+		}
+
+		integer, err := strconv.ParseInt(strings.TrimSpace(text), 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", text)
+		}
+
+		return integer, nil
+
+	case "real":
+		text, err := elementText(decoder, start)
+		if err != nil {
+			return nil, err
+		}
+
+		number, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid real %q", text)
+		}
+
+		return number, nil
+
+	case "string":
+		return elementText(decoder, start)
+
+	case "date":
+		text, err := elementText(decoder, start)
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.TrimSpace(text), nil
+
+	case "data":
+		text, err := elementText(decoder, start)
+		if err != nil {
+			return nil, err
+		}
+
+		return strings.Join(strings.Fields(text), ""), nil
+
+	default:
+		return nil, fmt.Errorf("unknown element %q", start.Name.Local)
+	}
+}
+
+// decodeDict decodes the key and value pairs of a dict element.
+func decodeDict(decoder *xml.Decoder) (interface{}, error) {
+	dict := make(map[string]interface{})
+	for {
+		keyElement, err := nextElement(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		if keyElement == nil {
+			return dict, nil
+		}
+
+		if keyElement.Name.Local != "key" {
+			return nil, fmt.Errorf("expected a key in dict, found %q", keyElement.Name.Local)
+		}
+
+		key, err := elementText(decoder, *keyElement)
+		if err != nil {
+			return nil, err
+		}
+
+		valueElement, err := nextElement(decoder)
+		if err != nil {
+			return nil, err
+		}
+
+		if valueElement == nil {
+			return nil, fmt.Errorf("missing value for key %q", key)
+		}
+
+		value, err := decodeValue(decoder, *valueElement)
+		if err != nil {
+			return nil, err
+		}
+
+		dict[key] = value
+	}
+}
+
+// nextElement returns the next child element, or nil when the
+// element that contains the children ends.
+func nextElement(decoder *xml.Decoder) (*xml.StartElement, error) {
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			return &t, nil
+		case xml.EndElement:
+			return nil, nil
+		}
+	}
+}
+
+// elementText returns the text of the element that starts with the given start element.
+func elementText(decoder *xml.Decoder, start xml.StartElement) (string, error) {
+	var text string
+	if err := decoder.DecodeElement(&text, &start); err != nil {
+		return "", err
+	}
+
+	return text, nil
+}
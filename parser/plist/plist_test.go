@@ -0,0 +1,79 @@
+package plist
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlistParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>com.example.backup</string>
+	<key>Disabled</key>
+	<false/>
+	<key>Nice</key>
+	<integer>-5</integer>
+	<key>Ratio</key>
+	<real>0.5</real>
+	<key>Created</key>
+	<date>2021-06-01T12:00:00Z</date>
+	<key>Token</key>
+	<data>
+	aGVsbG8=
+	</data>
+	<key>Paths</key>
+	<array>
+		<string>/var/log</string>
+		<dict/>
+	</array>
+</dict>
+</plist>`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Label":    "com.example.backup",
+		"Disabled": false,
+		"Nice":     -5.0,
+		"Ratio":    0.5,
+		"Created":  "2021-06-01T12:00:00Z",
+		"Token":    "aGVsbG8=",
+		"Paths":    []interface{}{"/var/log", map[string]interface{}{}},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected result. expected %v actual %v", expected, input)
+	}
+}
+
+func TestPlistParserInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "empty", input: `<plist version="1.0"></plist>`},
+		{name: "missing value", input: `<plist><dict><key>Label</key></dict></plist>`},
+		{name: "value without key", input: `<plist><dict><string>value</string></dict></plist>`},
+		{name: "invalid integer", input: `<plist><integer>one</integer></plist>`},
+		{name: "unknown element", input: `<plist><set/></plist>`},
+		{name: "unclosed element", input: `<plist><dict>`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			if err := parser.Unmarshal([]byte(testCase.input), &input); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
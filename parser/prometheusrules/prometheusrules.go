@@ -0,0 +1,85 @@
+package prometheusrules
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ghodss/yaml"
+)
+
+// Parser is a parser for Prometheus rule files, which are the files that
+// contain the alerting and recording rules of Prometheus.
+type Parser struct{}
+
+type ruleFile struct {
+	Groups []ruleGroup `json:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string     `json:"name"`
+	Rules []ruleSpec `json:"rules"`
+}
+
+type ruleSpec struct {
+	Alert       string            `json:"alert"`
+	Record      string            `json:"record"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for"`
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+// Rule is a single rule of a rule file, along with the group it belongs to.
+type Rule struct {
+	Group       string            `json:"group"`
+	Alert       string            `json:"alert,omitempty"`
+	Record      string            `json:"record,omitempty"`
+	Expr        string            `json:"expr"`
+	For         string            `json:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Unmarshal unmarshals Prometheus rule files.
+//
+// The groups of the file are flattened into a single list of rules, where every
+// rule has the name of its group. The expression of a rule is kept verbatim, as
+// PromQL is not parsed.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("unmarshal rule file: %w", err)
+	}
+
+	rules := []Rule{}
+	for _, group := range file.Groups {
+		for i, spec := range group.Rules {
+			if spec.Alert == "" && spec.Record == "" {
+				return fmt.Errorf("rule %d of group %q is neither an alert nor a recording rule", i, group.Name)
+			}
+
+			rule := Rule{
+				Group:       group.Name,
+				Alert:       spec.Alert,
+				Record:      spec.Record,
+				Expr:        spec.Expr,
+				For:         spec.For,
+				Labels:      spec.Labels,
+				Annotations: spec.Annotations,
+			}
+
+			rules = append(rules, rule)
+		}
+	}
+
+	j, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("marshal rules to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal rules json: %w", err)
+	}
+
+	return nil
+}
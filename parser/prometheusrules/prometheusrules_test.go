@@ -0,0 +1,87 @@
+package prometheusrules
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrometheusRulesParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `groups:
+- name: example
+  rules:
+  - alert: HighRequestLatency
+    expr: job:request_latency_seconds:mean5m{job="myjob"} > 0.5
+    for: 10m
+    labels:
+      severity: page
+    annotations:
+      summary: High request latency
+  - alert: InstanceDown
+    expr: |
+      up{job=~"api|web"}
+        == 0
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{
+			"group":       "example",
+			"alert":       "HighRequestLatency",
+			"expr":        `job:request_latency_seconds:mean5m{job="myjob"} > 0.5`,
+			"for":         "10m",
+			"labels":      map[string]interface{}{"severity": "page"},
+			"annotations": map[string]interface{}{"summary": "High request latency"},
+		},
+		map[string]interface{}{
+			"group": "example",
+			"alert": "InstanceDown",
+			"expr":  "up{job=~\"api|web\"}\n  == 0\n",
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected rules. expected %v actual %v", expected, input)
+	}
+}
+
+func TestPrometheusRulesParserRecordingRule(t *testing.T) {
+	parser := &Parser{}
+	sample := `groups:
+- name: recordings
+  rules:
+  - record: job:http_inprogress_requests:sum
+    expr: sum by (job) (http_inprogress_requests)
+`
+
+	var input []map[string]interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 1 || input[0]["record"] != "job:http_inprogress_requests:sum" {
+		t.Errorf("Unexpected rules: %v", input)
+	}
+
+	if _, ok := input[0]["alert"]; ok {
+		t.Errorf("recording rule should not have an alert: %v", input[0])
+	}
+}
+
+func TestPrometheusRulesParserInvalidRule(t *testing.T) {
+	parser := &Parser{}
+	sample := `groups:
+- name: example
+  rules:
+  - expr: up == 0
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+		t.Error("expected an error for a rule without an alert or a record")
+	}
+}
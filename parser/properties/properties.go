@@ -7,8 +7,17 @@ import (
 	prop "github.com/magiconair/properties"
 )
 
+// KeysField is the name of the field that contains the keys of the
+// properties in the order in which they appear in the file.
+const KeysField = "__keys__"
+
 // Parser is a properties parser.
-type Parser struct{}
+type Parser struct {
+
+	// PreserveOrder adds the keys of the properties, in the order in
+	// which they appear in the file, to the result as the KeysField.
+	PreserveOrder bool
+}
 
 func (pp *Parser) Unmarshal(p []byte, v interface{}) error {
 	rawProps, err := prop.LoadString(string(p))
@@ -16,7 +25,14 @@ func (pp *Parser) Unmarshal(p []byte, v interface{}) error {
 		return fmt.Errorf("Could not parse properties file: %w", err)
 	}
 
-	result := rawProps.Map()
+	result := make(map[string]interface{})
+	for key, value := range rawProps.Map() {
+		result[key] = value
+	}
+
+	if pp.PreserveOrder {
+		result[KeysField] = rawProps.Keys()
+	}
 
 	j, err := json.Marshal(result)
 	if err != nil {
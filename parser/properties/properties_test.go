@@ -1,6 +1,7 @@
 package properties
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -36,3 +37,44 @@ my-property=some-value`
 		t.Errorf("Failed to parse all properties: expected 2 got %d", inputLen)
 	}
 }
+
+func TestPropertiesParserPreserveOrder(t *testing.T) {
+	parser := &Parser{PreserveOrder: true}
+	sample := `log4j.rootLogger=INFO
+log4j.logger.com.example=DEBUG
+log4j.appender.console=org.apache.log4j.ConsoleAppender
+log4j.additivity.com.example=false`
+
+	var input map[string]interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := []interface{}{
+		"log4j.rootLogger",
+		"log4j.logger.com.example",
+		"log4j.appender.console",
+		"log4j.additivity.com.example",
+	}
+
+	if !reflect.DeepEqual(expected, input[KeysField]) {
+		t.Errorf("Unexpected keys. expected %v actual %v", expected, input[KeysField])
+	}
+
+	if input["log4j.rootLogger"] != "INFO" {
+		t.Errorf("Unexpected property. expected %v actual %v", "INFO", input["log4j.rootLogger"])
+	}
+}
+
+func TestPropertiesParserWithoutOrder(t *testing.T) {
+	parser := &Parser{}
+
+	var input map[string]interface{}
+	if err := parser.Unmarshal([]byte("key=value"), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if _, ok := input[KeysField]; ok {
+		t.Errorf("Unexpected keys. expected none actual %v", input[KeysField])
+	}
+}
@@ -0,0 +1,328 @@
+package proto
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/emicklei/proto"
+)
+
+// Parser is a Protocol Buffers (proto2 and proto3) parser.
+type Parser struct{}
+
+// The largest field number allowed, used for the max keyword in ranges.
+const maxFieldNumber = 536870911
+
+// File represents a parsed .proto file.
+type File struct {
+	Syntax   string            `json:"syntax"`
+	Package  string            `json:"package,omitempty"`
+	Imports  []string          `json:"imports,omitempty"`
+	Options  map[string]string `json:"options,omitempty"`
+	Messages []*Message        `json:"messages,omitempty"`
+	Enums    []*Enum           `json:"enums,omitempty"`
+	Services []*Service        `json:"services,omitempty"`
+}
+
+// Message represents a message definition.
+type Message struct {
+	Name       string            `json:"name"`
+	Fields     []*Field          `json:"fields,omitempty"`
+	Oneofs     []*Oneof          `json:"oneofs,omitempty"`
+	Messages   []*Message        `json:"messages,omitempty"`
+	Enums      []*Enum           `json:"enums,omitempty"`
+	Options    map[string]string `json:"options,omitempty"`
+	Reserved   *Reserved         `json:"reserved,omitempty"`
+	Extensions []Range           `json:"extensions,omitempty"`
+}
+
+// Field represents a field of a message.
+type Field struct {
+	Name      string            `json:"name"`
+	Number    int               `json:"number"`
+	Type      string            `json:"type"`
+	Label     string            `json:"label,omitempty"`
+	KeyType   string            `json:"key_type,omitempty"`
+	ValueType string            `json:"value_type,omitempty"`
+	Oneof     string            `json:"oneof,omitempty"`
+	Options   map[string]string `json:"options,omitempty"`
+}
+
+// Oneof represents a oneof definition inside of a message.
+type Oneof struct {
+	Name   string   `json:"name"`
+	Fields []*Field `json:"fields"`
+}
+
+// Reserved represents the reserved field numbers and names of a message.
+type Reserved struct {
+	Ranges []Range  `json:"ranges,omitempty"`
+	Names  []string `json:"names,omitempty"`
+}
+
+// Range represents an inclusive range of field numbers.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Enum represents an enum definition.
+type Enum struct {
+	Name    string            `json:"name"`
+	Values  []*EnumValue      `json:"values,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// EnumValue represents a single value of an enum.
+type EnumValue struct {
+	Name   string `json:"name"`
+	Number int    `json:"number"`
+}
+
+// Service represents a service definition.
+type Service struct {
+	Name    string            `json:"name"`
+	RPCs    []*RPC            `json:"rpcs,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// RPC represents a single method of a service.
+type RPC struct {
+	Name            string            `json:"name"`
+	RequestType     string            `json:"request_type"`
+	ResponseType    string            `json:"response_type"`
+	ClientStreaming bool              `json:"client_streaming"`
+	ServerStreaming bool              `json:"server_streaming"`
+	Options         map[string]string `json:"options,omitempty"`
+}
+
+// Unmarshal unmarshals .proto files.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	definition, err := proto.NewParser(bytes.NewReader(data)).Parse()
+	if err != nil {
+		return fmt.Errorf("parse proto: %w", err)
+	}
+
+	j, err := json.Marshal(newFile(definition))
+	if err != nil {
+		return fmt.Errorf("marshal proto to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal proto json: %w", err)
+	}
+
+	return nil
+}
+
+func newFile(definition *proto.Proto) *File {
+	file := File{
+		Syntax:  "proto2",
+		Options: make(map[string]string),
+	}
+
+	for _, element := range definition.Elements {
+		switch element := element.(type) {
+		case *proto.Syntax:
+			file.Syntax = element.Value
+		case *proto.Package:
+			file.Package = element.Name
+		case *proto.Import:
+			file.Imports = append(file.Imports, element.Filename)
+		case *proto.Option:
+			file.Options[element.Name] = element.Constant.Source
+		case *proto.Message:
+			if !element.IsExtend {
+				file.Messages = append(file.Messages, newMessage(element.Name, element.Elements))
+			}
+		case *proto.Enum:
+			file.Enums = append(file.Enums, newEnum(element))
+		case *proto.Service:
+			file.Services = append(file.Services, newService(element))
+		}
+	}
+
+	return &file
+}
+
+// newMessage creates a message from the elements of a message or a proto2 group.
+func newMessage(name string, elements []proto.Visitee) *Message {
+	message := Message{
+		Name:    name,
+		Options: make(map[string]string),
+	}
+
+	for _, element := range elements {
+		switch element := element.(type) {
+		case *proto.NormalField:
+			field := newField(element.Field)
+			field.Label = label(element.Optional, element.Required, element.Repeated)
+			message.Fields = append(message.Fields, field)
+
+		case *proto.MapField:
+			field := newField(element.Field)
+			field.Type = "map"
+			field.KeyType = element.KeyType
+			field.ValueType = element.Field.Type
+			message.Fields = append(message.Fields, field)
+
+		// A group both defines a nested message and a field of that message type,
+		// where the name of the field is the lowercased name of the group.
+		case *proto.Group:
+			message.Fields = append(message.Fields, &Field{
+				Name:   strings.ToLower(element.Name),
+				Number: element.Sequence,
+				Type:   element.Name,
+				Label:  label(element.Optional, element.Required, element.Repeated),
+			})
+			message.Messages = append(message.Messages, newMessage(element.Name, element.Elements))
+
+		case *proto.Oneof:
+			oneof := Oneof{
+				Name: element.Name,
+			}
+			for _, oneofElement := range element.Elements {
+				if oneofField, ok := oneofElement.(*proto.OneOfField); ok {
+					field := newField(oneofField.Field)
+					field.Oneof = element.Name
+					oneof.Fields = append(oneof.Fields, field)
+				}
+			}
+			message.Oneofs = append(message.Oneofs, &oneof)
+			message.Fields = append(message.Fields, oneof.Fields...)
+
+		case *proto.Message:
+			if !element.IsExtend {
+				message.Messages = append(message.Messages, newMessage(element.Name, element.Elements))
+			}
+
+		case *proto.Enum:
+			message.Enums = append(message.Enums, newEnum(element))
+
+		case *proto.Option:
+			message.Options[element.Name] = element.Constant.Source
+
+		case *proto.Reserved:
+			if message.Reserved == nil {
+				message.Reserved = &Reserved{}
+			}
+			message.Reserved.Ranges = append(message.Reserved.Ranges, newRanges(element.Ranges)...)
+			message.Reserved.Names = append(message.Reserved.Names, element.FieldNames...)
+
+		case *proto.Extensions:
+			message.Extensions = append(message.Extensions, newRanges(element.Ranges)...)
+		}
+	}
+
+	return &message
+}
+
+func newField(field *proto.Field) *Field {
+	return &Field{
+		Name:    field.Name,
+		Number:  field.Sequence,
+		Type:    field.Type,
+		Options: newOptions(field.Options),
+	}
+}
+
+func label(optional, required, repeated bool) string {
+	switch {
+	case optional:
+		return "optional"
+	case required:
+		return "required"
+	case repeated:
+		return "repeated"
+	}
+
+	return ""
+}
+
+func newOptions(options []*proto.Option) map[string]string {
+	if len(options) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string)
+	for _, option := range options {
+		result[option.Name] = option.Constant.Source
+	}
+
+	return result
+}
+
+func newRanges(ranges []proto.Range) []Range {
+	var result []Range
+	for _, r := range ranges {
+		end := r.To
+		if r.Max {
+			end = maxFieldNumber
+		} else if end == 0 {
+			end = r.From
+		}
+
+		result = append(result, Range{Start: r.From, End: end})
+	}
+
+	return result
+}
+
+func newEnum(definition *proto.Enum) *Enum {
+	enum := Enum{
+		Name:    definition.Name,
+		Options: make(map[string]string),
+	}
+
+	for _, element := range definition.Elements {
+		switch element := element.(type) {
+		case *proto.EnumField:
+			enum.Values = append(enum.Values, &EnumValue{Name: element.Name, Number: element.Integer})
+		case *proto.Option:
+			enum.Options[element.Name] = element.Constant.Source
+		}
+	}
+
+	return &enum
+}
+
+func newService(definition *proto.Service) *Service {
+	service := Service{
+		Name:    definition.Name,
+		Options: make(map[string]string),
+	}
+
+	for _, element := range definition.Elements {
+		switch element := element.(type) {
+		case *proto.RPC:
+			service.RPCs = append(service.RPCs, newRPC(element))
+		case *proto.Option:
+			service.Options[element.Name] = element.Constant.Source
+		}
+	}
+
+	return &service
+}
+
+func newRPC(definition *proto.RPC) *RPC {
+	rpc := RPC{
+		Name:            definition.Name,
+		RequestType:     definition.RequestType,
+		ResponseType:    definition.ReturnsType,
+		ClientStreaming: definition.StreamsRequest,
+		ServerStreaming: definition.StreamsReturns,
+	}
+
+	for _, element := range definition.Elements {
+		if option, ok := element.(*proto.Option); ok {
+			if rpc.Options == nil {
+				rpc.Options = make(map[string]string)
+			}
+			rpc.Options[option.Name] = option.Constant.Source
+		}
+	}
+
+	return &rpc
+}
@@ -0,0 +1,158 @@
+package proto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProtoParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `syntax = "proto3";
+
+package example.v1;
+
+import "google/protobuf/timestamp.proto";
+
+option go_package = "example.com/example/v1";
+
+/* A user of the system. */
+message User {
+  string name = 1;
+  repeated string emails = 2 [deprecated = true];
+  map<string, string> labels = 3;
+
+  // Addresses are nested messages.
+  message Address {
+    string street = 1;
+    int32 number = 2;
+  }
+
+  Address address = 4;
+
+  oneof contact {
+    string phone = 5;
+    string fax = 6;
+  }
+
+  reserved 7, 9 to 11, 40 to max;
+  reserved "password";
+}
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1;
+}
+
+service UserService {
+  rpc GetUser (GetUserRequest) returns (User);
+  rpc WatchUsers (stream WatchRequest) returns (stream User) {
+    option deprecated = true;
+  }
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	file := input.(map[string]interface{})
+	if file["syntax"] != "proto3" {
+		t.Errorf("unexpected syntax: %v", file["syntax"])
+	}
+
+	if file["package"] != "example.v1" {
+		t.Errorf("unexpected package: %v", file["package"])
+	}
+
+	user := file["messages"].([]interface{})[0].(map[string]interface{})
+	fields := user["fields"].([]interface{})
+	if len(fields) != 6 {
+		t.Fatalf("expected 6 fields, got %d", len(fields))
+	}
+
+	expectedEmails := map[string]interface{}{
+		"name":    "emails",
+		"number":  float64(2),
+		"type":    "string",
+		"label":   "repeated",
+		"options": map[string]interface{}{"deprecated": "true"},
+	}
+	if !reflect.DeepEqual(fields[1], expectedEmails) {
+		t.Errorf("unexpected field. expected %v actual %v", expectedEmails, fields[1])
+	}
+
+	nested := user["messages"].([]interface{})[0].(map[string]interface{})
+	if nested["name"] != "Address" {
+		t.Errorf("unexpected nested message: %v", nested["name"])
+	}
+
+	nestedNumber := nested["fields"].([]interface{})[1].(map[string]interface{})
+	if nestedNumber["name"] != "number" || nestedNumber["number"] != float64(2) {
+		t.Errorf("unexpected nested field: %v", nestedNumber)
+	}
+
+	phone := fields[4].(map[string]interface{})
+	if phone["oneof"] != "contact" {
+		t.Errorf("unexpected oneof: %v", phone["oneof"])
+	}
+
+	expectedReserved := map[string]interface{}{
+		"ranges": []interface{}{
+			map[string]interface{}{"start": float64(7), "end": float64(7)},
+			map[string]interface{}{"start": float64(9), "end": float64(11)},
+			map[string]interface{}{"start": float64(40), "end": float64(maxFieldNumber)},
+		},
+		"names": []interface{}{"password"},
+	}
+	if !reflect.DeepEqual(user["reserved"], expectedReserved) {
+		t.Errorf("unexpected reserved. expected %v actual %v", expectedReserved, user["reserved"])
+	}
+
+	rpcs := file["services"].([]interface{})[0].(map[string]interface{})["rpcs"].([]interface{})
+	watch := rpcs[1].(map[string]interface{})
+	if watch["client_streaming"] != true || watch["server_streaming"] != true || watch["request_type"] != "WatchRequest" {
+		t.Errorf("unexpected rpc: %v", watch)
+	}
+}
+
+func TestProtoParserProto2Group(t *testing.T) {
+	parser := &Parser{}
+	sample := `syntax = "proto2";
+
+message SearchResponse {
+  repeated group Result = 1 {
+    required string url = 2;
+  }
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	message := input.(map[string]interface{})["messages"].([]interface{})[0].(map[string]interface{})
+	field := message["fields"].([]interface{})[0].(map[string]interface{})
+	if field["name"] != "result" || field["type"] != "Result" || field["label"] != "repeated" {
+		t.Errorf("unexpected group field: %v", field)
+	}
+
+	group := message["messages"].([]interface{})[0].(map[string]interface{})
+	url := group["fields"].([]interface{})[0].(map[string]interface{})
+	if url["label"] != "required" || url["number"] != float64(2) {
+		t.Errorf("unexpected group message field: %v", url)
+	}
+}
+
+func TestProtoParserError(t *testing.T) {
+	parser := &Parser{}
+	sample := `syntax = "proto3";
+
+message User {
+  string name = ;
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+		t.Error("expected parser to return an error")
+	}
+}
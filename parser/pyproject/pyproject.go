@@ -0,0 +1,279 @@
+package pyproject
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/open-policy-agent/conftest/parser/requirements"
+	"github.com/open-policy-agent/conftest/parser/toml"
+)
+
+// Parser is a parser for Python pyproject.toml files.
+type Parser struct{}
+
+// Dependency is a single dependency of a pyproject.toml file.
+type Dependency struct {
+	requirements.Requirement
+
+	// Group is the name of the optional dependencies or of the Poetry
+	// dependency group that the dependency is part of. It is empty for
+	// the required dependencies of the project.
+	Group string `json:"group"`
+
+	// Python is the version of Python that a Poetry dependency with multiple
+	// constraints applies to, e.g. <3.8. It is empty for other dependencies.
+	Python string `json:"python,omitempty"`
+}
+
+var (
+	poetryConstraintRegex  = regexp.MustCompile(`^(\^|~=|~|===|==|!=|<=|>=|<|>)?\s*(\S+)$`)
+	poetryAlternativeRegex = regexp.MustCompile(`\|\|?`)
+)
+
+// Unmarshal unmarshals pyproject.toml files.
+//
+// The dependencies of the project are extracted into a single mergedDependencies
+// list, where every dependency has the fields of a requirement of a requirements
+// file (see requirements.Requirement) along with its group. The dependencies are
+// read from both the PEP 621 layout (project.dependencies and
+// project.optional-dependencies) and the Poetry layout (tool.poetry.dependencies,
+// tool.poetry.dev-dependencies and tool.poetry.group.<name>.dependencies). The
+// other fields of the pyproject.toml file are kept.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var document map[string]interface{}
+	if err := (&toml.Parser{}).Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("unmarshal pyproject.toml: %w", err)
+	}
+
+	dependencies, err := extractDependencies(document)
+	if err != nil {
+		return fmt.Errorf("extract dependencies: %w", err)
+	}
+	document["mergedDependencies"] = dependencies
+
+	j, err := json.Marshal(document)
+	if err != nil {
+		return fmt.Errorf("marshal pyproject.toml to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal pyproject.toml json: %w", err)
+	}
+
+	return nil
+}
+
+func extractDependencies(document map[string]interface{}) ([]Dependency, error) {
+	dependencies := []Dependency{}
+
+	project, _ := document["project"].(map[string]interface{})
+	pep621, err := pep621Dependencies(project["dependencies"], "")
+	if err != nil {
+		return nil, fmt.Errorf("project.dependencies: %w", err)
+	}
+	dependencies = append(dependencies, pep621...)
+
+	optional, _ := project["optional-dependencies"].(map[string]interface{})
+	for _, group := range sortedKeys(optional) {
+		pep621, err := pep621Dependencies(optional[group], group)
+		if err != nil {
+			return nil, fmt.Errorf("project.optional-dependencies.%s: %w", group, err)
+		}
+		dependencies = append(dependencies, pep621...)
+	}
+
+	tool, _ := document["tool"].(map[string]interface{})
+	poetry, _ := tool["poetry"].(map[string]interface{})
+	if poetry == nil {
+		return dependencies, nil
+	}
+
+	// The dev-dependencies are the dev group of older versions of Poetry,
+	// which can be used alongside the group tables of newer versions.
+	poetryTables := []interface{}{poetry["dependencies"], poetry["dev-dependencies"]}
+	poetryGroups := []string{"", "dev"}
+
+	groups, _ := poetry["group"].(map[string]interface{})
+	for _, name := range sortedKeys(groups) {
+		if groupTable, ok := groups[name].(map[string]interface{}); ok {
+			poetryTables = append(poetryTables, groupTable["dependencies"])
+			poetryGroups = append(poetryGroups, name)
+		}
+	}
+
+	for i, table := range poetryTables {
+		poetryDependencies, err := poetryDependencies(table, poetryGroups[i])
+		if err != nil {
+			return nil, fmt.Errorf("poetry dependencies of group %q: %w", poetryGroups[i], err)
+		}
+		dependencies = append(dependencies, poetryDependencies...)
+	}
+
+	return dependencies, nil
+}
+
+// pep621Dependencies returns the dependencies of a list of PEP 508 strings.
+func pep621Dependencies(value interface{}, group string) ([]Dependency, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("dependencies are not a list")
+	}
+
+	var dependencies []Dependency
+	for _, item := range list {
+		line, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("dependency %v is not a string", item)
+		}
+
+		requirement, err := requirements.ParseRequirement(strings.TrimSpace(line))
+		if err != nil {
+			return nil, fmt.Errorf("parse requirement: %w", err)
+		}
+
+		dependencies = append(dependencies, Dependency{Requirement: requirement, Group: group})
+	}
+
+	return dependencies, nil
+}
+
+// poetryDependencies returns the dependencies of a table of Poetry dependencies, where
+// every dependency is either a version constraint, a table with a version constraint, or
+// a list of such tables for dependencies with multiple constraints, which depend on the
+// version of Python or on markers. Every table of such a list, and every alternative of a
+// constraint with alternatives (e.g. ^1.2 || ^2.0), is a separate dependency with the same
+// name. Dependencies on a git repository, a path or a URL have the @ specifier, with the
+// location as their version. The python dependency is the version of Python that the
+// project supports, so it is not included.
+func poetryDependencies(value interface{}, group string) ([]Dependency, error) {
+	table, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+
+	var dependencies []Dependency
+	for _, name := range sortedKeys(table) {
+		if name == "python" {
+			continue
+		}
+
+		// A list of tables is written either inline or as an array of tables,
+		// which the TOML parser returns as a list of maps.
+		specs := []interface{}{table[name]}
+		switch list := table[name].(type) {
+		case []interface{}:
+			specs = list
+		case []map[string]interface{}:
+			specs = nil
+			for _, spec := range list {
+				specs = append(specs, spec)
+			}
+		}
+
+		for _, spec := range specs {
+			specDependencies, err := poetryDependency(name, spec, group)
+			if err != nil {
+				return nil, err
+			}
+			dependencies = append(dependencies, specDependencies...)
+		}
+	}
+
+	return dependencies, nil
+}
+
+// poetryDependency returns the dependencies of a single Poetry dependency specification,
+// which has a dependency for every alternative of its version constraint.
+func poetryDependency(name string, spec interface{}, group string) ([]Dependency, error) {
+	requirement := requirements.Requirement{
+		Name:        name,
+		Extras:      []string{},
+		Constraints: []requirements.Constraint{},
+	}
+
+	var version string
+	var python string
+	switch spec := spec.(type) {
+	case string:
+		version = spec
+	case map[string]interface{}:
+		version, _ = spec["version"].(string)
+		for _, source := range []string{"git", "path", "url"} {
+			if location, ok := spec[source].(string); ok {
+				requirement.Constraints = append(requirement.Constraints, requirements.Constraint{Specifier: "@", Version: location})
+			}
+		}
+
+		extras, _ := spec["extras"].([]interface{})
+		for _, extra := range extras {
+			requirement.Extras = append(requirement.Extras, fmt.Sprint(extra))
+		}
+
+		requirement.Markers, _ = spec["markers"].(string)
+		python, _ = spec["python"].(string)
+	default:
+		return nil, fmt.Errorf("invalid dependency %v", name)
+	}
+
+	var dependencies []Dependency
+	for _, alternative := range poetryAlternativeRegex.Split(version, -1) {
+		constraints, err := poetryConstraints(alternative)
+		if err != nil {
+			return nil, fmt.Errorf("dependency %v: %w", name, err)
+		}
+
+		alternativeRequirement := requirement
+		alternativeRequirement.Constraints = append(append([]requirements.Constraint{}, requirement.Constraints...), constraints...)
+		if len(alternativeRequirement.Constraints) > 0 {
+			alternativeRequirement.Specifier = alternativeRequirement.Constraints[0].Specifier
+			alternativeRequirement.Version = alternativeRequirement.Constraints[0].Version
+		}
+
+		dependencies = append(dependencies, Dependency{Requirement: alternativeRequirement, Group: group, Python: python})
+	}
+
+	return dependencies, nil
+}
+
+// poetryConstraints parses a Poetry version constraint, such as ^1.2 or >=1.0,<2.0.
+// A version without an operator is an exact version, and * allows any version.
+func poetryConstraints(version string) ([]requirements.Constraint, error) {
+	version = strings.TrimSpace(version)
+	if version == "" || version == "*" {
+		return nil, nil
+	}
+
+	var constraints []requirements.Constraint
+	for _, part := range strings.Split(version, ",") {
+		match := poetryConstraintRegex.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return nil, fmt.Errorf("invalid version constraint %q", part)
+		}
+
+		specifier := match[1]
+		if specifier == "" {
+			specifier = "=="
+		}
+
+		constraints = append(constraints, requirements.Constraint{Specifier: specifier, Version: match[2]})
+	}
+
+	return constraints, nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,160 @@
+package pyproject
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPyprojectParserPEP621(t *testing.T) {
+	parser := &Parser{}
+	sample := `[project]
+name = "app"
+dependencies = [
+  "requests==2.31.0",
+  "flask",
+  "uvicorn[standard]>=0.23,<1.0",
+]
+
+[project.optional-dependencies]
+test = ["pytest>=7"]
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	document := input.(map[string]interface{})
+	if name := document["project"].(map[string]interface{})["name"]; name != "app" {
+		t.Errorf("Unexpected project name. expected app actual %v", name)
+	}
+
+	dependencies := document["mergedDependencies"].([]interface{})
+	if len(dependencies) != 4 {
+		t.Fatalf("Unexpected number of dependencies. expected 4 actual %v", len(dependencies))
+	}
+
+	pinned := dependencies[0].(map[string]interface{})
+	expectedPinned := map[string]interface{}{
+		"name":        "requests",
+		"extras":      []interface{}{},
+		"specifier":   "==",
+		"version":     "2.31.0",
+		"constraints": []interface{}{map[string]interface{}{"specifier": "==", "version": "2.31.0"}},
+		"markers":     "",
+		"group":       "",
+	}
+	if !reflect.DeepEqual(pinned, expectedPinned) {
+		t.Errorf("Unexpected pinned dependency. expected %v actual %v", expectedPinned, pinned)
+	}
+
+	unpinned := dependencies[1].(map[string]interface{})
+	if unpinned["name"] != "flask" || unpinned["specifier"] != "" {
+		t.Errorf("Unexpected unpinned dependency. expected flask without a specifier actual %v", unpinned)
+	}
+
+	optional := dependencies[3].(map[string]interface{})
+	if optional["name"] != "pytest" || optional["group"] != "test" || optional["specifier"] != ">=" {
+		t.Errorf("Unexpected optional dependency. expected pytest>=7 in the test group actual %v", optional)
+	}
+}
+
+func TestPyprojectParserPoetry(t *testing.T) {
+	parser := &Parser{}
+	sample := `[tool.poetry]
+name = "app"
+
+[tool.poetry.dependencies]
+python = "^3.10"
+requests = "^2.31"
+flask = "*"
+django = "4.2.7"
+uvicorn = { version = ">=0.23,<1.0", extras = ["standard"] }
+internal = { git = "https://example.com/internal.git" }
+
+[tool.poetry.group.test.dependencies]
+pytest = "~7.4"
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	dependencies := input.(map[string]interface{})["mergedDependencies"].([]interface{})
+
+	actual := make(map[string][]interface{})
+	for _, d := range dependencies {
+		dependency := d.(map[string]interface{})
+		actual[dependency["name"].(string)] = []interface{}{dependency["specifier"], dependency["version"], dependency["group"]}
+	}
+
+	expected := map[string][]interface{}{
+		"django":   {"==", "4.2.7", ""},
+		"flask":    {"", "", ""},
+		"internal": {"@", "https://example.com/internal.git", ""},
+		"requests": {"^", "2.31", ""},
+		"uvicorn":  {">=", "0.23", ""},
+		"pytest":   {"~", "7.4", "test"},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected dependencies. expected %v actual %v", expected, actual)
+	}
+
+	uvicorn := dependencies[len(dependencies)-2].(map[string]interface{})
+	if !reflect.DeepEqual(uvicorn["extras"], []interface{}{"standard"}) {
+		t.Errorf("Unexpected extras. expected [standard] actual %v", uvicorn["extras"])
+	}
+}
+
+func TestPyprojectParserPoetryMultipleConstraints(t *testing.T) {
+	parser := &Parser{}
+	sample := `[tool.poetry.dependencies]
+python = "^3.7"
+foo = [
+    { version = "<=1.9", python = "<3.8" },
+    { version = "^2.0", python = ">=3.8" },
+]
+bar = "^1.2 || ^2.0"
+baz = { version = ">=1.0,<1.5 | >=2.0", extras = ["cli"] }
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	dependencies := input.(map[string]interface{})["mergedDependencies"].([]interface{})
+
+	var actual [][]interface{}
+	for _, d := range dependencies {
+		dependency := d.(map[string]interface{})
+		actual = append(actual, []interface{}{dependency["name"], dependency["specifier"], dependency["version"], dependency["python"], len(dependency["constraints"].([]interface{}))})
+	}
+
+	expected := [][]interface{}{
+		{"bar", "^", "1.2", nil, 1},
+		{"bar", "^", "2.0", nil, 1},
+		{"baz", ">=", "1.0", nil, 2},
+		{"baz", ">=", "2.0", nil, 1},
+		{"foo", "<=", "1.9", "<3.8", 1},
+		{"foo", "^", "2.0", ">=3.8", 1},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected dependencies. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestPyprojectParserPoetryInvalidConstraint(t *testing.T) {
+	parser := &Parser{}
+	sample := `[tool.poetry.dependencies]
+foo = [{ version = "^1.0" }, { version = "not a version" }]
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err == nil {
+		t.Error("parser should have thrown an error")
+	}
+}
@@ -0,0 +1,149 @@
+package requirements
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser is a parser for pip requirements files (e.g. requirements.txt).
+type Parser struct{}
+
+// Requirement is a single requirement of a requirements file.
+type Requirement struct {
+	Name   string   `json:"name"`
+	Extras []string `json:"extras"`
+
+	// Specifier and Version are the operator and version of the first
+	// constraint, e.g. == and 1.0.0 for name==1.0.0. Both are empty
+	// when the requirement is not constrained.
+	Specifier string `json:"specifier"`
+	Version   string `json:"version"`
+
+	// Constraints contains all of the constraints of the requirement,
+	// for requirements with multiple constraints such as name>=1.0,<2.0.
+	Constraints []Constraint `json:"constraints"`
+
+	// Markers are the environment markers of the requirement, e.g.
+	// python_version < "3.8".
+	Markers string `json:"markers"`
+}
+
+// Constraint is a single version constraint of a requirement.
+type Constraint struct {
+	Specifier string `json:"specifier"`
+	Version   string `json:"version"`
+}
+
+var (
+	requirementRegex = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[([^\]]*)\])?\s*(.*)$`)
+	constraintRegex  = regexp.MustCompile(`^(===|==|!=|~=|<=|>=|<|>)\s*(\S+)$`)
+	commentRegex     = regexp.MustCompile(`(^|\s)#.*$`)
+	optionRegex      = regexp.MustCompile(`\s--[A-Za-z][A-Za-z0-9-]*(?:(?:=|\s+)[^\s-]\S*)?`)
+	directRegex      = regexp.MustCompile(`^(?:[A-Za-z][A-Za-z0-9+.-]*://|file:|\.|/|~)`)
+)
+
+// Unmarshal unmarshals requirements files into a list of requirements.
+//
+// Comments, blank lines and lines with options (e.g. -r other.txt or
+// --index-url) are skipped, and a line ending in a backslash continues
+// on the next line. The options of a requirement (e.g. --hash=sha256:...)
+// are removed, and lines that install from a URL or a path without a
+// name (e.g. ./pkg or https://example.com/pkg.tar.gz) are skipped. A
+// requirement that refers to a URL (name @ url) has the @ specifier,
+// with the URL as its version.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	requirements := []Requirement{}
+
+	var continued string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(commentRegex.ReplaceAllString(scanner.Text(), ""))
+		if strings.HasSuffix(line, "\\") {
+			continued += strings.TrimSuffix(line, "\\")
+			continue
+		}
+		line = strings.TrimSpace(continued + line)
+		continued = ""
+
+		if line == "" || strings.HasPrefix(line, "-") || directRegex.MatchString(line) {
+			continue
+		}
+
+		line = strings.TrimSpace(optionRegex.ReplaceAllString(line, ""))
+
+		requirement, err := ParseRequirement(line)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		requirements = append(requirements, requirement)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan requirements: %w", err)
+	}
+
+	j, err := json.Marshal(requirements)
+	if err != nil {
+		return fmt.Errorf("marshal requirements to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal requirements json: %w", err)
+	}
+
+	return nil
+}
+
+// ParseRequirement parses a single PEP 508 requirement, such as
+// name[extra]>=1.0,<2.0; python_version < "3.8".
+func ParseRequirement(line string) (Requirement, error) {
+	var markers string
+	if i := strings.Index(line, ";"); i >= 0 {
+		markers = strings.TrimSpace(line[i+1:])
+		line = strings.TrimSpace(line[:i])
+	}
+
+	match := requirementRegex.FindStringSubmatch(line)
+	if match == nil {
+		return Requirement{}, fmt.Errorf("invalid requirement %q", line)
+	}
+
+	requirement := Requirement{
+		Name:        match[1],
+		Extras:      []string{},
+		Constraints: []Constraint{},
+		Markers:     markers,
+	}
+
+	for _, extra := range strings.Split(match[2], ",") {
+		if extra = strings.TrimSpace(extra); extra != "" {
+			requirement.Extras = append(requirement.Extras, extra)
+		}
+	}
+
+	rest := strings.TrimSpace(match[3])
+	if strings.HasPrefix(rest, "@") {
+		requirement.Constraints = append(requirement.Constraints, Constraint{Specifier: "@", Version: strings.TrimSpace(rest[1:])})
+	} else if rest != "" {
+		for _, part := range strings.Split(strings.Trim(rest, "()"), ",") {
+			constraint := constraintRegex.FindStringSubmatch(strings.TrimSpace(part))
+			if constraint == nil {
+				return Requirement{}, fmt.Errorf("invalid version constraint %q of %v", part, requirement.Name)
+			}
+
+			requirement.Constraints = append(requirement.Constraints, Constraint{Specifier: constraint[1], Version: constraint[2]})
+		}
+	}
+
+	if len(requirement.Constraints) > 0 {
+		requirement.Specifier = requirement.Constraints[0].Specifier
+		requirement.Version = requirement.Constraints[0].Version
+	}
+
+	return requirement, nil
+}
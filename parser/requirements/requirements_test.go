@@ -0,0 +1,119 @@
+package requirements
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequirementsParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# Pinned dependencies
+--index-url https://pypi.org/simple
+requests==2.31.0
+flask
+uvicorn[standard, watchfiles]>=0.23,<1.0  # the server
+importlib-metadata>=4.0; python_version < "3.8"
+mypkg @ https://example.com/mypkg-1.0.tar.gz
+django \
+    ~=4.2
+`
+
+	var input []interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 6 {
+		t.Fatalf("Unexpected number of requirements. expected 6 actual %v", len(input))
+	}
+
+	pinned := input[0].(map[string]interface{})
+	expectedPinned := map[string]interface{}{
+		"name":        "requests",
+		"extras":      []interface{}{},
+		"specifier":   "==",
+		"version":     "2.31.0",
+		"constraints": []interface{}{map[string]interface{}{"specifier": "==", "version": "2.31.0"}},
+		"markers":     "",
+	}
+	if !reflect.DeepEqual(pinned, expectedPinned) {
+		t.Errorf("Unexpected pinned requirement. expected %v actual %v", expectedPinned, pinned)
+	}
+
+	unpinned := input[1].(map[string]interface{})
+	expectedUnpinned := map[string]interface{}{
+		"name":        "flask",
+		"extras":      []interface{}{},
+		"specifier":   "",
+		"version":     "",
+		"constraints": []interface{}{},
+		"markers":     "",
+	}
+	if !reflect.DeepEqual(unpinned, expectedUnpinned) {
+		t.Errorf("Unexpected unpinned requirement. expected %v actual %v", expectedUnpinned, unpinned)
+	}
+
+	extras := input[2].(map[string]interface{})
+	if !reflect.DeepEqual(extras["extras"], []interface{}{"standard", "watchfiles"}) {
+		t.Errorf("Unexpected extras. expected [standard watchfiles] actual %v", extras["extras"])
+	}
+
+	if constraints := extras["constraints"].([]interface{}); len(constraints) != 2 {
+		t.Errorf("Unexpected constraints. expected 2 actual %v", constraints)
+	}
+
+	markers := input[3].(map[string]interface{})["markers"]
+	if markers != `python_version < "3.8"` {
+		t.Errorf("Unexpected markers. expected %v actual %v", `python_version < "3.8"`, markers)
+	}
+
+	url := input[4].(map[string]interface{})
+	if url["specifier"] != "@" || url["version"] != "https://example.com/mypkg-1.0.tar.gz" {
+		t.Errorf("Unexpected url requirement. expected @ https://example.com/mypkg-1.0.tar.gz actual %v %v", url["specifier"], url["version"])
+	}
+
+	continued := input[5].(map[string]interface{})
+	if continued["name"] != "django" || continued["specifier"] != "~=" || continued["version"] != "4.2" {
+		t.Errorf("Unexpected continued requirement. expected django~=4.2 actual %v", continued)
+	}
+}
+
+func TestRequirementsParserInvalid(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte("requests=>2.0"), &input); err == nil {
+		t.Error("expected an error for an invalid version constraint")
+	}
+}
+
+func TestRequirementsParserHashes(t *testing.T) {
+	parser := &Parser{}
+	sample := `requests==2.31.0 \
+    --hash=sha256:58cd2187c01e70e6e26505bca751777aa9f2ee0b7f4300988b709f44e013003f \
+    --hash=sha256:942c5a758f98d790eaed1a29cb6eefc7ffb0d1cf7af05c3d2791656dbd6ad1e1
+flask==3.0.0; python_version >= "3.8" --hash sha256:cfadcdb638b609361d29ec22360d6070a77d7463dcb3ab08d2c2f2f168845f58
+./vendor/mypkg
+https://example.com/otherpkg-1.0.tar.gz
+git+https://example.com/thirdpkg.git#egg=thirdpkg
+`
+
+	var input []interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 2 {
+		t.Fatalf("Unexpected number of requirements. expected 2 actual %v", len(input))
+	}
+
+	hashed := input[0].(map[string]interface{})
+	if hashed["name"] != "requests" || hashed["specifier"] != "==" || hashed["version"] != "2.31.0" {
+		t.Errorf("Unexpected hashed requirement. expected requests==2.31.0 actual %v", hashed)
+	}
+
+	markers := input[1].(map[string]interface{})
+	if markers["version"] != "3.0.0" || markers["markers"] != `python_version >= "3.8"` {
+		t.Errorf("Unexpected hashed requirement with markers. expected flask==3.0.0 actual %v", markers)
+	}
+}
@@ -0,0 +1,192 @@
+package sentinel
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Parser is a parser for HashiCorp Sentinel policies. The policies are not
+// evaluated, only the structure of their top-level statements is parsed.
+type Parser struct{}
+
+// Import is an import of a Sentinel policy, such as import "tfplan/v2" as tfplan.
+type Import struct {
+	Name string `json:"name"`
+
+	// Alias is the name that the import is referred to by, which
+	// is the name of the import when it is not imported with as.
+	Alias string `json:"alias"`
+}
+
+// Param is a parameter of a Sentinel policy.
+type Param struct {
+	Name string `json:"name"`
+
+	// Default is the expression of the default value, as it is written
+	// in the policy. It is empty when the parameter has no default.
+	Default string `json:"default"`
+}
+
+// Rule is a rule of a Sentinel policy.
+type Rule struct {
+	Name string `json:"name"`
+
+	// When is the condition of the rule, as it is written in the
+	// policy. It is empty when the rule has no condition.
+	When string `json:"when"`
+}
+
+// Policy is the structure of a Sentinel policy.
+type Policy struct {
+	Imports []Import `json:"imports"`
+	Params  []Param  `json:"params"`
+	Rules   []Rule   `json:"rules"`
+}
+
+var (
+	importRegex = regexp.MustCompile(`^import\s+"([^"]+)"(?:\s+as\s+([A-Za-z_][A-Za-z0-9_]*))?$`)
+	paramRegex  = regexp.MustCompile(`^param\s+([A-Za-z_][A-Za-z0-9_]*)(?:\s+default\s+([\s\S]+))?$`)
+	ruleRegex   = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*=\s*rule\b\s*(?:when\s+([\s\S]*?))?\s*\{`)
+)
+
+// Unmarshal unmarshals the top-level imports, parameters and rules of Sentinel
+// policies. Other statements, such as variables and functions, are skipped.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	statements, err := topLevelStatements(string(data))
+	if err != nil {
+		return err
+	}
+
+	policy := Policy{
+		Imports: []Import{},
+		Params:  []Param{},
+		Rules:   []Rule{},
+	}
+
+	for _, statement := range statements {
+		if match := importRegex.FindStringSubmatch(statement); match != nil {
+			alias := match[2]
+			if alias == "" {
+				alias = match[1]
+			}
+
+			policy.Imports = append(policy.Imports, Import{Name: match[1], Alias: alias})
+			continue
+		}
+
+		if match := paramRegex.FindStringSubmatch(statement); match != nil {
+			policy.Params = append(policy.Params, Param{Name: match[1], Default: strings.TrimSpace(match[2])})
+			continue
+		}
+
+		if match := ruleRegex.FindStringSubmatch(statement); match != nil {
+			policy.Rules = append(policy.Rules, Rule{Name: match[1], When: strings.Join(strings.Fields(match[2]), " ")})
+		}
+	}
+
+	j, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("marshal sentinel to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal sentinel json: %w", err)
+	}
+
+	return nil
+}
+
+// topLevelStatements splits the policy into its top-level statements, without
+// comments. A statement ends at a newline that is not inside of brackets, so
+// the body of a rule is part of the statement of the rule.
+func topLevelStatements(source string) ([]string, error) {
+	var statements []string
+	var statement strings.Builder
+	var brackets []byte
+	line := 1
+
+	closing := map[byte]byte{'{': '}', '(': ')', '[': ']'}
+	for i := 0; i < len(source); i++ {
+		c := source[i]
+		switch {
+		case c == '"' || c == '`':
+			end := stringEnd(source, i)
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated string", line)
+			}
+
+			line += strings.Count(source[i:end], "\n")
+			statement.WriteString(source[i:end])
+			i = end - 1
+			continue
+		case c == '#' || strings.HasPrefix(source[i:], "//"):
+			end := strings.IndexByte(source[i:], '\n')
+			if end < 0 {
+				end = len(source) - i
+			}
+
+			i += end - 1
+			continue
+		case strings.HasPrefix(source[i:], "/*"):
+			end := strings.Index(source[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated comment", line)
+			}
+
+			line += strings.Count(source[i:i+2+end], "\n")
+			statement.WriteByte(' ')
+			i += end + 3
+			continue
+		case c == '{' || c == '(' || c == '[':
+			brackets = append(brackets, closing[c])
+		case c == '}' || c == ')' || c == ']':
+			if len(brackets) == 0 || brackets[len(brackets)-1] != c {
+				return nil, fmt.Errorf("line %d: unexpected %q", line, c)
+			}
+
+			brackets = brackets[:len(brackets)-1]
+		case c == '\n':
+			line++
+			if len(brackets) == 0 {
+				if text := strings.TrimSpace(statement.String()); text != "" {
+					statements = append(statements, text)
+				}
+
+				statement.Reset()
+				continue
+			}
+		}
+
+		statement.WriteByte(c)
+	}
+
+	if len(brackets) > 0 {
+		return nil, fmt.Errorf("line %d: missing %q", line, brackets[len(brackets)-1])
+	}
+
+	if text := strings.TrimSpace(statement.String()); text != "" {
+		statements = append(statements, text)
+	}
+
+	return statements, nil
+}
+
+// stringEnd returns the index after the end of the string literal that
+// starts at the given index, or -1 when the string is not terminated.
+func stringEnd(source string, start int) int {
+	quote := source[start]
+	for i := start + 1; i < len(source); i++ {
+		switch {
+		case source[i] == '\\' && quote == '"':
+			i++
+		case source[i] == quote:
+			return i + 1
+		case source[i] == '\n' && quote == '"':
+			return -1
+		}
+	}
+
+	return -1
+}
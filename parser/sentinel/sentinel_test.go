@@ -0,0 +1,71 @@
+package sentinel
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSentinelParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# Restricts the instance types of EC2 instances.
+import "tfplan/v2" as tfplan
+import "strings"
+
+param allowed_types default [
+	"t3.micro", // the smallest instance
+	"t3.small",
+]
+param environment
+
+/*
+ * The instances that are created or updated.
+ */
+instances = filter tfplan.resource_changes as _, rc {
+	rc.type is "aws_instance" and
+	(rc.change.actions contains "create" or rc.change.actions contains "update")
+}
+
+allowed_instance_types = rule when environment is "prod" {
+	all instances as _, instance {
+		instance.change.after.instance_type in allowed_types
+	}
+}
+
+main = rule {
+	allowed_instance_types and strings.has_prefix(environment, "p")
+}
+`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"imports": []interface{}{
+			map[string]interface{}{"name": "tfplan/v2", "alias": "tfplan"},
+			map[string]interface{}{"name": "strings", "alias": "strings"},
+		},
+		"params": []interface{}{
+			map[string]interface{}{"name": "allowed_types", "default": "[\n\t\"t3.micro\", \n\t\"t3.small\",\n]"},
+			map[string]interface{}{"name": "environment", "default": ""},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{"name": "allowed_instance_types", "when": `environment is "prod"`},
+			map[string]interface{}{"name": "main", "when": ""},
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected policy. expected %v actual %v", expected, input)
+	}
+}
+
+func TestSentinelParserUnbalanced(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte("main = rule {\n\ttrue\n"), &input); err == nil {
+		t.Error("expected an error for a rule without a closing brace")
+	}
+}
@@ -0,0 +1,91 @@
+package systemdnetwork
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Parser is a parser for systemd-networkd configuration files, such
+// as .network and .netdev files.
+type Parser struct{}
+
+// Unmarshal unmarshals systemd-networkd configuration files.
+//
+// The files look like INI files, but both sections and keys can be repeated.
+// For example, a .network file can contain multiple Address= lines, and
+// multiple [Route] sections. To give policies a single shape to work with,
+// every section is a list of the occurrences of the section, and every key
+// is a list of its values:
+//
+//	{"Network": [{"Address": ["10.0.0.2/24", "10.0.0.3/24"]}]}
+//
+// As in systemd, an empty assignment (e.g. DNS=) clears the values that
+// were assigned to the key before it, lines starting with # or ; are
+// comments, and a line ending in a backslash continues on the next line.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	result := make(map[string][]map[string][]string)
+
+	var section map[string][]string
+	var continued string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if continued == "" && (len(line) == 0 || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";")) {
+			continue
+		}
+
+		if strings.HasSuffix(line, "\\") {
+			continued += strings.TrimSpace(strings.TrimSuffix(line, "\\")) + " "
+			continue
+		}
+		line = continued + line
+		continued = ""
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return fmt.Errorf("line %d: invalid section header %q", lineNumber, line)
+			}
+
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			section = make(map[string][]string)
+			result[name] = append(result[name], section)
+			continue
+		}
+
+		if section == nil {
+			return fmt.Errorf("line %d: assignment outside of a section", lineNumber)
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("line %d: missing '=' separator", lineNumber)
+		}
+
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+		if value == "" {
+			section[key] = []string{}
+			continue
+		}
+
+		section[key] = append(section[key], value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("scan systemd network file: %w", err)
+	}
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("marshal systemd network file to json: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal systemd network file json: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package systemdnetwork
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSystemdNetworkParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `# Static addresses for the uplink
+[Match]
+Name=eth0
+
+[Network]
+Address=10.0.0.2/24
+Address=10.0.0.3/24
+DNS=1.1.1.1
+DNS=
+DNS=9.9.9.9 \
+    8.8.8.8
+
+[Route]
+Gateway=10.0.0.1
+
+[Route]
+; Default route for the second address
+Destination=192.168.0.0/16
+Gateway=10.0.0.254`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"Match": []interface{}{
+			map[string]interface{}{"Name": []interface{}{"eth0"}},
+		},
+		"Network": []interface{}{
+			map[string]interface{}{
+				"Address": []interface{}{"10.0.0.2/24", "10.0.0.3/24"},
+				"DNS":     []interface{}{"9.9.9.9 8.8.8.8"},
+			},
+		},
+		"Route": []interface{}{
+			map[string]interface{}{"Gateway": []interface{}{"10.0.0.1"}},
+			map[string]interface{}{"Destination": []interface{}{"192.168.0.0/16"}, "Gateway": []interface{}{"10.0.0.254"}},
+		},
+	}
+
+	if !reflect.DeepEqual(input, expected) {
+		t.Errorf("Unexpected result. expected %v actual %v", expected, input)
+	}
+}
+
+func TestSystemdNetworkParserInvalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{name: "assignment outside of a section", input: "Name=eth0"},
+		{name: "missing separator", input: "[Match]\nName"},
+		{name: "unterminated section", input: "[Match"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parser := &Parser{}
+
+			var input interface{}
+			if err := parser.Unmarshal([]byte(testCase.input), &input); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
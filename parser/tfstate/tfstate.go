@@ -0,0 +1,202 @@
+package tfstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Parser is a Terraform state file parser.
+type Parser struct{}
+
+// Resource is a single resource instance found in the state.
+type Resource struct {
+	Address    string                 `json:"address"`
+	Module     string                 `json:"module,omitempty"`
+	Mode       string                 `json:"mode"`
+	Type       string                 `json:"type"`
+	Name       string                 `json:"name"`
+	Index      interface{}            `json:"index,omitempty"`
+	Provider   string                 `json:"provider"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+type state struct {
+	Version   int             `json:"version"`
+	Resources []stateResource `json:"resources"`
+	Modules   []stateModule   `json:"modules"`
+}
+
+// stateResource is a resource in the state format used since Terraform 0.12 (version 4).
+type stateResource struct {
+	Module    string          `json:"module"`
+	Mode      string          `json:"mode"`
+	Type      string          `json:"type"`
+	Name      string          `json:"name"`
+	Provider  string          `json:"provider"`
+	Instances []stateInstance `json:"instances"`
+}
+
+type stateInstance struct {
+	IndexKey   interface{}            `json:"index_key"`
+	Attributes map[string]interface{} `json:"attributes"`
+}
+
+// stateModule is a module in the state format used before Terraform 0.12 (version 3).
+type stateModule struct {
+	Path      []string                       `json:"path"`
+	Resources map[string]legacyStateResource `json:"resources"`
+}
+
+type legacyStateResource struct {
+	Type     string `json:"type"`
+	Provider string `json:"provider"`
+	Primary  struct {
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"primary"`
+}
+
+// Unmarshal unmarshals Terraform state files into a flat list of
+// resource instances, regardless of the version of the state format.
+func (p *Parser) Unmarshal(data []byte, v interface{}) error {
+	var s state
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("unmarshal tfstate: %w", err)
+	}
+
+	var resources []Resource
+	switch {
+	case s.Version >= 4:
+		resources = resourcesFromState(s.Resources)
+	case s.Version == 3 || s.Version == 2 || s.Version == 1:
+		resources = resourcesFromLegacyState(s.Modules)
+	default:
+		return fmt.Errorf("unsupported tfstate version: %v", s.Version)
+	}
+
+	if resources == nil {
+		resources = []Resource{}
+	}
+
+	j, err := json.Marshal(resources)
+	if err != nil {
+		return fmt.Errorf("marshal resources: %w", err)
+	}
+
+	if err := json.Unmarshal(j, v); err != nil {
+		return fmt.Errorf("unmarshal resources: %w", err)
+	}
+
+	return nil
+}
+
+func resourcesFromState(stateResources []stateResource) []Resource {
+	var resources []Resource
+	for _, resource := range stateResources {
+		mode := resource.Mode
+		if mode == "" {
+			mode = "managed"
+		}
+
+		for _, instance := range resource.Instances {
+			address := resource.Type + "." + resource.Name
+			if mode == "data" {
+				address = "data." + address
+			}
+
+			switch index := instance.IndexKey.(type) {
+			case string:
+				address += fmt.Sprintf("[%q]", index)
+			case float64:
+				address += fmt.Sprintf("[%v]", index)
+			}
+
+			if resource.Module != "" {
+				address = resource.Module + "." + address
+			}
+
+			resources = append(resources, Resource{
+				Address:    address,
+				Module:     resource.Module,
+				Mode:       mode,
+				Type:       resource.Type,
+				Name:       resource.Name,
+				Index:      instance.IndexKey,
+				Provider:   resource.Provider,
+				Attributes: instance.Attributes,
+			})
+		}
+	}
+
+	return resources
+}
+
+func resourcesFromLegacyState(modules []stateModule) []Resource {
+	var resources []Resource
+	for _, module := range modules {
+		var modulePath string
+		for _, name := range module.Path {
+			if name == "root" {
+				continue
+			}
+
+			if modulePath != "" {
+				modulePath += "."
+			}
+			modulePath += "module." + name
+		}
+
+		// The resources of a module are stored in a map, so they are sorted
+		// by their key to keep the order of the resources consistent.
+		var keys []string
+		for key := range module.Resources {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			resource := module.Resources[key]
+
+			// Resources are keyed by their address in the module (e.g. aws_instance.web),
+			// which is suffixed with the index when there are multiple instances.
+			mode := "managed"
+			address := key
+			if strings.HasPrefix(address, "data.") {
+				mode = "data"
+				address = strings.TrimPrefix(address, "data.")
+			}
+
+			parts := strings.Split(address, ".")
+			if len(parts) < 2 {
+				continue
+			}
+
+			var index interface{}
+			if len(parts) > 2 {
+				if i, err := strconv.Atoi(parts[2]); err == nil {
+					index = i
+				}
+			}
+
+			fullAddress := key
+			if modulePath != "" {
+				fullAddress = modulePath + "." + key
+			}
+
+			resources = append(resources, Resource{
+				Address:    fullAddress,
+				Module:     modulePath,
+				Mode:       mode,
+				Type:       resource.Type,
+				Name:       parts[1],
+				Index:      index,
+				Provider:   resource.Provider,
+				Attributes: resource.Primary.Attributes,
+			})
+		}
+	}
+
+	return resources
+}
@@ -0,0 +1,139 @@
+package tfstate
+
+import (
+	"testing"
+)
+
+func TestTFStateParser(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+  "version": 4,
+  "terraform_version": "0.14.0",
+  "resources": [
+    {
+      "mode": "managed",
+      "type": "aws_s3_bucket",
+      "name": "logs",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "attributes": {
+            "bucket": "my-logs",
+            "acl": "private"
+          }
+        }
+      ]
+    },
+    {
+      "module": "module.network",
+      "mode": "data",
+      "type": "aws_vpc",
+      "name": "main",
+      "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+      "instances": [
+        {
+          "index_key": 0,
+          "attributes": {
+            "cidr_block": "10.0.0.0/16"
+          }
+        }
+      ]
+    }
+  ]
+}`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	resources, ok := input.([]interface{})
+	if !ok {
+		t.Fatalf("Unexpected input. expected a list of resources actual %v", input)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("Unexpected number of resources. expected %v actual %v", 2, len(resources))
+	}
+
+	bucket := resources[0].(map[string]interface{})
+	if bucket["type"] != "aws_s3_bucket" || bucket["name"] != "logs" || bucket["address"] != "aws_s3_bucket.logs" {
+		t.Errorf("Unexpected resource: %v", bucket)
+	}
+
+	if bucket["attributes"].(map[string]interface{})["acl"] != "private" {
+		t.Errorf("Unexpected attributes: %v", bucket["attributes"])
+	}
+
+	vpc := resources[1].(map[string]interface{})
+	if vpc["mode"] != "data" || vpc["address"] != "module.network.data.aws_vpc.main[0]" {
+		t.Errorf("Unexpected resource: %v", vpc)
+	}
+}
+
+func TestTFStateParserLegacyFormat(t *testing.T) {
+	parser := &Parser{}
+	sample := `{
+  "version": 3,
+  "terraform_version": "0.11.14",
+  "modules": [
+    {
+      "path": ["root"],
+      "resources": {
+        "aws_s3_bucket.logs": {
+          "type": "aws_s3_bucket",
+          "provider": "provider.aws",
+          "primary": {
+            "id": "my-logs",
+            "attributes": {
+              "bucket": "my-logs",
+              "acl": "private"
+            }
+          }
+        }
+      }
+    },
+    {
+      "path": ["root", "network"],
+      "resources": {
+        "aws_subnet.private.1": {
+          "type": "aws_subnet",
+          "provider": "provider.aws",
+          "primary": {
+            "id": "subnet-1",
+            "attributes": {
+              "cidr_block": "10.0.1.0/24"
+            }
+          }
+        }
+      }
+    }
+  ]
+}`
+
+	var input []map[string]interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	if len(input) != 2 {
+		t.Fatalf("Unexpected number of resources. expected %v actual %v", 2, len(input))
+	}
+
+	if input[0]["type"] != "aws_s3_bucket" || input[0]["name"] != "logs" || input[0]["provider"] != "provider.aws" {
+		t.Errorf("Unexpected resource: %v", input[0])
+	}
+
+	if input[1]["address"] != "module.network.aws_subnet.private.1" || input[1]["name"] != "private" || input[1]["index"] != float64(1) {
+		t.Errorf("Unexpected resource: %v", input[1])
+	}
+}
+
+func TestTFStateParserUnsupportedVersion(t *testing.T) {
+	parser := &Parser{}
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(`{"version": 0}`), &input); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
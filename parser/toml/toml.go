@@ -10,6 +10,9 @@ import (
 type Parser struct{}
 
 // Unmarshal unmarshals TOML files.
+//
+// Dotted keys (a.b.c = 1) result in nested tables, and arrays
+// of tables ([[products]]) result in a list of tables.
 func (tp *Parser) Unmarshal(p []byte, v interface{}) error {
 	if err := toml.Unmarshal(p, v); err != nil {
 		return fmt.Errorf("unmarshal toml: %w", err)
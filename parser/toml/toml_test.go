@@ -1,6 +1,8 @@
 package toml
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 )
 
@@ -28,3 +30,48 @@ func TestTomlParser(t *testing.T) {
 		t.Error("there should be at least one item defined in the parsed file, but none found")
 	}
 }
+
+func TestTomlParserNestedStructures(t *testing.T) {
+	parser := &Parser{}
+	sample := `server.http.port = 8080
+
+[[products]]
+name = "Hammer"
+sku = 738594937
+
+[[products]]
+name = "Nail"
+sku = 284758393`
+
+	var input interface{}
+	if err := parser.Unmarshal([]byte(sample), &input); err != nil {
+		t.Fatalf("parser should not have thrown an error: %v", err)
+	}
+
+	// The round trip through JSON matches the shape of the input that is given to Rego.
+	contents, err := json.Marshal(input)
+	if err != nil {
+		t.Fatalf("marshal input: %v", err)
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(contents, &actual); err != nil {
+		t.Fatalf("unmarshal input: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"server": map[string]interface{}{
+			"http": map[string]interface{}{
+				"port": float64(8080),
+			},
+		},
+		"products": []interface{}{
+			map[string]interface{}{"name": "Hammer", "sku": float64(738594937)},
+			map[string]interface{}{"name": "Nail", "sku": float64(284758393)},
+		},
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected input. expected %v actual %v", expected, actual)
+	}
+}
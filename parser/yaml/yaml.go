@@ -2,17 +2,33 @@ package yaml
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 
 	"github.com/ghodss/yaml"
+	yamlv2 "gopkg.in/yaml.v2"
 )
 
 // Parser is a YAML parser.
-type Parser struct{}
+type Parser struct {
+
+	// Strict reports duplicate keys as an error, rather than
+	// silently keeping the value of the last key.
+	Strict bool
+}
 
 // Unmarshal unmarshals YAML files.
 func (yp *Parser) Unmarshal(p []byte, v interface{}) error {
 	subDocuments := separateSubDocuments(p)
+	if yp.Strict {
+		if err := checkDuplicateKeys(subDocuments); err != nil {
+			return fmt.Errorf("check duplicate keys: %w", err)
+		}
+	}
+
 	if len(subDocuments) > 1 {
 		if err := unmarshalMultipleDocuments(subDocuments, v); err != nil {
 			return fmt.Errorf("unmarshal multiple documents: %w", err)
@@ -37,6 +53,78 @@ func separateSubDocuments(data []byte) [][]byte {
 	return bytes.Split(data, []byte(linebreak+"---"+linebreak))
 }
 
+const sourceCommentPrefix = "# Source:"
+
+// SourceComments returns the path in the Source comment that precedes each of the
+// documents, such as the comments that Helm adds to the rendered templates
+// (e.g. # Source: chart/templates/deployment.yaml). The path is empty for the
+// documents that are not preceded by a Source comment.
+func SourceComments(p []byte) []string {
+	var sources []string
+	for _, subDocument := range separateSubDocuments(p) {
+		var source string
+		for _, line := range strings.Split(string(subDocument), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || line == "---" {
+				continue
+			}
+
+			// Only the comments at the start of the document are considered.
+			if !strings.HasPrefix(line, "#") {
+				break
+			}
+
+			if strings.HasPrefix(line, sourceCommentPrefix) {
+				source = strings.TrimSpace(strings.TrimPrefix(line, sourceCommentPrefix))
+				break
+			}
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources
+}
+
+var errorLineRegex = regexp.MustCompile(`^line (\d+): `)
+
+// checkDuplicateKeys returns an error that lists all of the duplicate keys in the
+// given documents, with the line of the file that each duplicate key is found on.
+func checkDuplicateKeys(subDocuments [][]byte) error {
+	var duplicates []string
+	var lineOffset int
+	for _, subDocument := range subDocuments {
+		var document interface{}
+		err := yamlv2.UnmarshalStrict(subDocument, &document)
+
+		var typeError *yamlv2.TypeError
+		if errors.As(err, &typeError) {
+
+			// The lines are relative to the document, so they are
+			// shifted by the lines of the documents before it.
+			for _, message := range typeError.Errors {
+				if match := errorLineRegex.FindStringSubmatch(message); match != nil {
+					line, _ := strconv.Atoi(match[1])
+					message = fmt.Sprintf("line %d: %s", line+lineOffset, strings.TrimPrefix(message, match[0]))
+				}
+
+				duplicates = append(duplicates, message)
+			}
+		} else if err != nil {
+			return fmt.Errorf("unmarshal yaml: %w", err)
+		}
+
+		// Every document is followed by the separator line.
+		lineOffset += bytes.Count(subDocument, []byte("\n")) + 2
+	}
+
+	if len(duplicates) > 0 {
+		return fmt.Errorf("duplicate keys:\n%s", strings.Join(duplicates, "\n"))
+	}
+
+	return nil
+}
+
 func unmarshalMultipleDocuments(subDocuments [][]byte, v interface{}) error {
 	var documentStore []interface{}
 	for _, subDocument := range subDocuments {
@@ -2,6 +2,7 @@ package yaml_test
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/open-policy-agent/conftest/parser/yaml"
@@ -66,3 +67,62 @@ nice: true`),
 		}
 	})
 }
+
+func TestYAMLParserDuplicateKeys(t *testing.T) {
+	sample := []byte(`kind: Deployment
+spec:
+  resources:
+    cpu: 100m
+  resources:
+    memory: 128Mi
+---
+kind: Service
+kind: Ingress`)
+
+	var unmarshalled interface{}
+	if err := new(yaml.Parser).Unmarshal(sample, &unmarshalled); err != nil {
+		t.Fatalf("duplicate keys should be allowed when not strict: %v", err)
+	}
+
+	strictParser := &yaml.Parser{Strict: true}
+	err := strictParser.Unmarshal(sample, &unmarshalled)
+	if err == nil {
+		t.Fatal("expected an error for the duplicate keys")
+	}
+
+	expected := []string{
+		`line 5: key "resources" already set in map`,
+		`line 9: key "kind" already set in map`,
+	}
+	for _, message := range expected {
+		if !strings.Contains(err.Error(), message) {
+			t.Errorf("Unexpected error. expected %q in %v", message, err)
+		}
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	config := []byte(`---
+# Source: chart/templates/service.yaml
+apiVersion: v1
+kind: Service
+---
+# Source: chart/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+---
+apiVersion: v1
+kind: ConfigMap
+# Source: chart/templates/configmap.yaml`)
+
+	expected := []string{
+		"chart/templates/service.yaml",
+		"chart/templates/deployment.yaml",
+		"",
+	}
+
+	actual := yaml.SourceComments(config)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected source comments. expected %v actual %v", expected, actual)
+	}
+}
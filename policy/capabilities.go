@@ -0,0 +1,49 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/open-policy-agent/opa/ast"
+)
+
+// CapabilitiesSafe is the name of the capabilities profile that removes
+// the builtins which are able to make network calls or read the environment.
+const CapabilitiesSafe = "safe"
+
+var unsafeBuiltins = map[string]bool{
+	"http.send":   true,
+	"opa.runtime": true,
+}
+
+// LoadCapabilities returns the capabilities that restrict which builtins
+// policies are allowed to use. The capabilities can either be the name of
+// a profile (e.g. safe), or a path to an OPA capabilities JSON file.
+func LoadCapabilities(capabilities string) (*ast.Capabilities, error) {
+	if capabilities == CapabilitiesSafe {
+		safe := ast.CapabilitiesForThisVersion()
+
+		var builtins []*ast.Builtin
+		for _, builtin := range safe.Builtins {
+			if !unsafeBuiltins[builtin.Name] {
+				builtins = append(builtins, builtin)
+			}
+		}
+		safe.Builtins = builtins
+
+		return safe, nil
+	}
+
+	file, err := os.Open(capabilities)
+	if err != nil {
+		return nil, fmt.Errorf("open capabilities: %w", err)
+	}
+	defer file.Close()
+
+	loaded, err := ast.LoadCapabilitiesJSON(file)
+	if err != nil {
+		return nil, fmt.Errorf("load capabilities json: %w", err)
+	}
+
+	return loaded, nil
+}
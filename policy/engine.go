@@ -3,71 +3,202 @@ package policy
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
 
 	"github.com/open-policy-agent/opa/ast"
+	"github.com/open-policy-agent/opa/bundle"
 	"github.com/open-policy-agent/opa/loader"
 	"github.com/open-policy-agent/opa/rego"
 	"github.com/open-policy-agent/opa/storage"
+	"github.com/open-policy-agent/opa/topdown"
+	"github.com/open-policy-agent/opa/util"
 	"github.com/open-policy-agent/opa/version"
 )
 
 // Engine represents the policy engine.
 type Engine struct {
-	trace    bool
-	modules  map[string]*ast.Module
-	compiler *ast.Compiler
-	store    storage.Store
-	policies map[string]string
-	docs     map[string]string
+	trace         bool
+	traceFormat   string
+	policySource  bool
+	policyRoot    bool
+	rule          string
+	builtinErrors bool
+	rawResults    bool
+	modules       map[string]*ast.Module
+	compiler      *ast.Compiler
+	store         storage.Store
+	policies      map[string]string
+	docs          map[string]string
+
+	// bundleData is the data found in the bundles, which is
+	// loaded into the store together with the data paths.
+	bundleData map[string]interface{}
+
+	// roots maps the path of every policy to the policy path or
+	// bundle that it was loaded from.
+	roots map[string]string
+
+	// dataPaths are the paths that the data in the store was loaded from, and
+	// groupData is the directory with the data of the groups of CheckCombinedBy.
+	dataPaths []string
+	groupData string
+
+	// fallback is the ordered list of namespaces in which a rule shadows
+	// the rules with the same name in the namespaces that follow it.
+	fallback []string
+
+	// wasmBundle is the bundle built with opa build -t wasm that the policies
+	// were loaded from, whose WASM module evaluates the queries when set.
+	wasmBundle     *bundle.Bundle
+	wasmBundlePath string
+
+	// reload loads a new engine in the same way as this engine was loaded,
+	// and mu guards the loaded policies and data while they are replaced.
+	reload func(ctx context.Context) (*Engine, error)
+	mu     sync.RWMutex
+
+	// invalidMessages are the values returned by rules that are not a
+	// message, which are guarded by messagesMu as queries run concurrently.
+	invalidMessages     []InvalidMessage
+	seenInvalidMessages map[InvalidMessage]bool
+	messagesMu          sync.Mutex
+}
+
+// Options represents the options available when loading
+// policies into an Engine.
+type Options struct {
+
+	// Libraries are paths to policies that are compiled alongside the
+	// loaded policies so that they can be imported. The rules found in
+	// libraries are never evaluated directly.
+	Libraries []string
+
+	// Capabilities restrict the builtins that policies are allowed to use.
+	// Policies that use a builtin which is not part of the capabilities fail
+	// to compile. When nil, all builtins are available.
+	Capabilities *ast.Capabilities
+
+	// ExcludeTestFiles excludes the policies in _test.rego files, which
+	// usually only contain Rego unit tests and their helpers.
+	ExcludeTestFiles bool
+
+	// Bundles are paths to OPA bundles, either a directory or a tarball built
+	// with opa build. The policies and data of the bundles are loaded alongside
+	// the policy and data paths.
+	Bundles []string
+
+	// FailOnCompileWarnings fails loading the policies when the compiler reports
+	// warnings in strict mode, such as unused imports and unused variables.
+	FailOnCompileWarnings bool
+
+	// GroupData is a directory with a data directory for each group of CheckCombinedBy,
+	// named after the key of the group (e.g. services/payments). The data of a group is
+	// loaded alongside the data paths, which are used on their own for groups without
+	// a data directory.
+	GroupData string
 }
 
 // Load returns an Engine after loading all of the specified policies.
 func Load(ctx context.Context, policyPaths []string) (*Engine, error) {
-	policies, err := loader.AllRegos(policyPaths)
+	engine, err := load(ctx, policyPaths, Options{})
 	if err != nil {
-		return nil, fmt.Errorf("load: %w", err)
-	} else if len(policies.Modules) == 0 {
-		return nil, fmt.Errorf("no policies found in %v", policyPaths)
+		return nil, err
 	}
 
-	compiler, err := policies.Compiler()
-	if err != nil {
-		return nil, fmt.Errorf("get compiler: %w", err)
+	engine.reload = func(ctx context.Context) (*Engine, error) {
+		return Load(ctx, policyPaths)
 	}
 
-	policyContents := make(map[string]string)
-	for path, module := range policies.ParsedModules() {
-		path = filepath.Clean(path)
-		path = filepath.ToSlash(path)
+	return engine, nil
+}
 
-		policyContents[path] = module.String()
+// LoadWithData returns an Engine after loading all of the specified policies and data paths.
+func LoadWithData(ctx context.Context, policyPaths []string, dataPaths []string) (*Engine, error) {
+	return LoadWithOptions(ctx, policyPaths, dataPaths, Options{})
+}
+
+// LoadWithOptions returns an Engine after loading all of the specified policies and data paths
+// using the given options.
+func LoadWithOptions(ctx context.Context, policyPaths []string, dataPaths []string, options Options) (*Engine, error) {
+	engine, err := load(ctx, policyPaths, options)
+	if err != nil {
+		return nil, fmt.Errorf("loading policies: %w", err)
 	}
 
-	engine := Engine{
-		modules:  policies.ParsedModules(),
-		compiler: compiler,
-		policies: policyContents,
+	if err := engine.loadData(dataPaths); err != nil {
+		return nil, err
 	}
 
-	return &engine, nil
+	engine.reload = func(ctx context.Context) (*Engine, error) {
+		return LoadWithOptions(ctx, policyPaths, dataPaths, options)
+	}
+
+	return engine, nil
 }
 
-// LoadWithData returns an Engine after loading all of the specified policies and data paths.
-func LoadWithData(ctx context.Context, policyPaths []string, dataPaths []string) (*Engine, error) {
-	engine, err := Load(ctx, policyPaths)
+// LoadSourceWithOptions returns an Engine after compiling the given policy source and
+// loading the data paths using the given options. The path is the name of the policy
+// that is used in errors and traces (e.g. stdin.rego). The source must declare its
+// package, and errors when parsing or compiling it include the line that caused them.
+func LoadSourceWithOptions(ctx context.Context, path string, source string, dataPaths []string, options Options) (*Engine, error) {
+	module, err := ast.ParseModule(path, source)
+	if err != nil {
+		return nil, fmt.Errorf("parse module: %w", err)
+	} else if module == nil {
+		return nil, fmt.Errorf("no policies found in %v: source is empty", path)
+	}
+
+	libraries, err := loader.AllRegos(options.Libraries)
+	if err != nil {
+		return nil, fmt.Errorf("load libraries: %w", err)
+	}
+
+	engine, err := newEngine(map[string]*ast.Module{path: module}, libraries.ParsedModules(), options)
 	if err != nil {
 		return nil, fmt.Errorf("loading policies: %w", err)
 	}
 
+	if err := engine.loadData(dataPaths); err != nil {
+		return nil, err
+	}
+
+	engine.reload = func(ctx context.Context) (*Engine, error) {
+		return LoadSourceWithOptions(ctx, path, source, dataPaths, options)
+	}
+
+	return engine, nil
+}
+
+// loadData loads the documents found in the given data paths into the store of the engine.
+func (e *Engine) loadData(dataPaths []string) error {
+	store, documentContents, err := loadDocuments(dataPaths, e.bundleData)
+	if err != nil {
+		return err
+	}
+
+	e.store = store
+	e.docs = documentContents
+	e.dataPaths = dataPaths
+
+	return nil
+}
+
+// loadDocuments returns a store with the documents found in the given data paths and the
+// bundle data, as well as the raw contents of the documents keyed by their path.
+func loadDocuments(dataPaths []string, bundleData map[string]interface{}) (storage.Store, map[string]string, error) {
+
 	// FilteredPaths will recursively find all file paths that contain a valid document
 	// extension from the given list of data paths.
 	allDocumentPaths, err := loader.FilteredPaths(dataPaths, func(abspath string, info os.FileInfo, depth int) bool {
@@ -77,23 +208,27 @@ func LoadWithData(ctx context.Context, policyPaths []string, dataPaths []string)
 		return !contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(info.Name()))
 	})
 	if err != nil {
-		return nil, fmt.Errorf("filter data paths: %w", err)
+		return nil, nil, fmt.Errorf("filter data paths: %w", err)
 	}
 
 	documents, err := loader.NewFileLoader().All(allDocumentPaths)
 	if err != nil {
-		return nil, fmt.Errorf("load documents: %w", err)
+		return nil, nil, fmt.Errorf("load documents: %w", err)
+	}
+
+	if err := mergeDocuments(documents.Documents, bundleData); err != nil {
+		return nil, nil, fmt.Errorf("merge bundle data: %w", err)
 	}
 	store, err := documents.Store()
 	if err != nil {
-		return nil, fmt.Errorf("get documents store: %w", err)
+		return nil, nil, fmt.Errorf("get documents store: %w", err)
 	}
 
 	documentContents := make(map[string]string)
 	for _, documentPath := range allDocumentPaths {
 		contents, err := ioutil.ReadFile(documentPath)
 		if err != nil {
-			return nil, fmt.Errorf("read file: %w", err)
+			return nil, nil, fmt.Errorf("read file: %w", err)
 		}
 
 		documentPath = filepath.Clean(documentPath)
@@ -101,171 +236,1139 @@ func LoadWithData(ctx context.Context, policyPaths []string, dataPaths []string)
 		documentContents[documentPath] = string(contents)
 	}
 
-	engine.store = store
-	engine.docs = documentContents
+	return store, documentContents, nil
+}
+
+func load(ctx context.Context, policyPaths []string, options Options) (*Engine, error) {
+
+	// A policy path that does not exist is most likely a mistake, such as a typo
+	// in the path, so it is reported separately from a path without policies.
+	for _, policyPath := range policyPaths {
+		if _, err := os.Stat(policyPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("policy path %v does not exist", policyPath)
+		}
+	}
+
+	// Policy paths that are bundle tarballs, such as the bundles that opa build -t wasm
+	// outputs, are loaded as bundles. A WASM module on its own cannot be evaluated, as
+	// the rules are discovered from the Rego sources that the bundle contains.
+	var regoPaths []string
+	bundlePaths := append([]string{}, options.Bundles...)
+	for _, policyPath := range policyPaths {
+		switch {
+		case strings.EqualFold(filepath.Ext(policyPath), ".wasm"):
+			return nil, fmt.Errorf("policy path %v is a WASM module: use the bundle built with opa build -t wasm instead", policyPath)
+		case isBundleTarball(policyPath):
+			bundlePaths = append(bundlePaths, policyPath)
+		default:
+			regoPaths = append(regoPaths, policyPath)
+		}
+	}
+
+	policies, err := loader.AllRegos(regoPaths)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+
+	modules := policies.ParsedModules()
+	roots := make(map[string]string)
+	for path := range modules {
+		roots[path] = policyRoot(path, policyPaths)
+	}
+
+	var wasmBundle *bundle.Bundle
+	var wasmBundlePath string
+	bundleData := make(map[string]interface{})
+	for _, bundlePath := range bundlePaths {
+		loaded, err := loader.NewFileLoader().AsBundle(bundlePath)
+		if err != nil {
+			return nil, fmt.Errorf("load bundle %v: %w", bundlePath, err)
+		}
+
+		if len(loaded.WasmModules) > 0 {
+			if !wasmSupported {
+				return nil, fmt.Errorf("bundle %v contains a WASM module: evaluating WASM-compiled policies requires conftest to be built with the opa_wasm build tag", bundlePath)
+			}
+
+			if len(loaded.Modules) == 0 {
+				return nil, fmt.Errorf("bundle %v contains a WASM module without the Rego sources of its rules", bundlePath)
+			}
+
+			wasmBundle = loaded
+			wasmBundlePath = bundlePath
+		}
+
+		for _, module := range loaded.Modules {
+			path := filepath.Join(bundlePath, module.Path)
+			modules[path] = module.Parsed
+			roots[path] = bundlePath
+		}
+
+		if err := mergeDocuments(bundleData, loaded.Data); err != nil {
+			return nil, fmt.Errorf("merge data of bundle %v: %w", bundlePath, err)
+		}
+	}
+
+	// Only the WASM module is evaluated, so the rules of any other policy or library
+	// would be reported as passing without ever being evaluated.
+	if wasmBundle != nil && (len(bundlePaths) > 1 || len(policies.Modules) > 0 || len(options.Libraries) > 0) {
+		return nil, fmt.Errorf("bundle %v contains a WASM module, which cannot be combined with other policies, bundles or libraries", wasmBundlePath)
+	}
+
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no policies found in %v: path exists but does not contain any .rego files", policyPaths)
+	}
+
+	libraries, err := loader.AllRegos(options.Libraries)
+	if err != nil {
+		return nil, fmt.Errorf("load libraries: %w", err)
+	}
+
+	if options.ExcludeTestFiles {
+		for path := range modules {
+			if strings.HasSuffix(path, "_test.rego") {
+				delete(modules, path)
+			}
+		}
+
+		if len(modules) == 0 {
+			return nil, fmt.Errorf("no policies found in %v: path only contains _test.rego files", policyPaths)
+		}
+	}
+
+	engine, err := newEngine(modules, libraries.ParsedModules(), options)
+	if err != nil {
+		return nil, err
+	}
+	engine.bundleData = bundleData
+	engine.roots = roots
+	engine.wasmBundle = wasmBundle
+	engine.wasmBundlePath = wasmBundlePath
+
+	return engine, nil
+}
+
+// isBundleTarball returns whether the given path is a bundle tarball.
+func isBundleTarball(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// policyRoot returns the policy path that the policy at the given path was
+// loaded from. When policy paths are nested, the most specific one is used.
+func policyRoot(path string, policyPaths []string) string {
+	path = filepath.Clean(path)
+
+	var root string
+	for _, policyPath := range policyPaths {
+		cleaned := filepath.Clean(policyPath)
+		if path != cleaned && !strings.HasPrefix(path, cleaned+string(filepath.Separator)) && cleaned != "." {
+			continue
+		}
+
+		if len(policyPath) > len(root) {
+			root = policyPath
+		}
+	}
+
+	return root
+}
+
+// LoadFS returns an Engine after loading all of the policies found in the
+// specified paths of the given filesystem. This allows policies to be read from
+// sources other than the OS filesystem, such as an embedded filesystem.
+func LoadFS(ctx context.Context, fsys fs.FS, policyPaths []string) (*Engine, error) {
+	policies := make(map[string]*ast.Module)
+	for _, policyPath := range policyPaths {
+		err := fs.WalkDir(fsys, policyPath, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if entry.IsDir() || !strings.HasSuffix(path, ".rego") {
+				return nil
+			}
+
+			contents, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				return fmt.Errorf("read file: %w", err)
+			}
+
+			module, err := ast.ParseModule(path, string(contents))
+			if err != nil {
+				return fmt.Errorf("parse module: %w", err)
+			}
+
+			policies[path] = module
+			return nil
+		})
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("policy path %v does not exist", policyPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("walk %v: %w", policyPath, err)
+		}
+	}
+
+	if len(policies) == 0 {
+		return nil, fmt.Errorf("no policies found in %v: path exists but does not contain any .rego files", policyPaths)
+	}
+
+	engine, err := newEngine(policies, nil, Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	engine.reload = func(ctx context.Context) (*Engine, error) {
+		return LoadFS(ctx, fsys, policyPaths)
+	}
+
+	return engine, nil
+}
+
+func newEngine(policies map[string]*ast.Module, libraries map[string]*ast.Module, options Options) (*Engine, error) {
+
+	// Libraries need to be compiled together with the policies so that the policies
+	// are able to import them. However, they are intentionally not part of the engine's
+	// modules, which prevents their rules from being evaluated by Check.
+	if err := checkLibraries(policies, libraries); err != nil {
+		return nil, err
+	}
+
+	allModules := make(map[string]*ast.Module)
+	for path, module := range libraries {
+		allModules[path] = module
+	}
+	for path, module := range policies {
+		allModules[path] = module
+	}
+
+	compiler := ast.NewCompiler()
+	if options.Capabilities != nil {
+		compiler = compiler.WithCapabilities(options.Capabilities)
+	}
+
+	compiler.Compile(allModules)
+	if compiler.Failed() {
+		return nil, fmt.Errorf("get compiler: %w", compiler.Errors)
+	}
+
+	// The strict mode of the compiler reports issues that do not prevent the policies
+	// from being evaluated, such as unused imports. The policies are compiled again
+	// so that these warnings are reported separately from the compile errors.
+	if options.FailOnCompileWarnings {
+		strictCompiler := ast.NewCompiler().WithStrict(true)
+		if options.Capabilities != nil {
+			strictCompiler = strictCompiler.WithCapabilities(options.Capabilities)
+		}
+
+		strictCompiler.Compile(allModules)
+		if strictCompiler.Failed() {
+			return nil, fmt.Errorf("compiler warnings: %w", strictCompiler.Errors)
+		}
+	}
+
+	policyContents := make(map[string]string)
+	for path, module := range policies {
+		path = filepath.Clean(path)
+		path = filepath.ToSlash(path)
+
+		policyContents[path] = module.String()
+	}
+
+	engine := Engine{
+		modules:   policies,
+		compiler:  compiler,
+		policies:  policyContents,
+		groupData: options.GroupData,
+	}
+
+	return &engine, nil
+}
+
+// checkLibraries returns an error when a library is also loaded as a policy, or when a
+// library declares the same package as a policy. The rules of a package are merged when
+// it is compiled, so the deny and warn rules of such a library would be evaluated along
+// with the rules of the policy.
+func checkLibraries(policies map[string]*ast.Module, libraries map[string]*ast.Module) error {
+	policyPaths := make(map[string]bool)
+	packages := make(map[string]string)
+	for path, module := range policies {
+		policyPaths[filepath.Clean(path)] = true
+
+		pkg := module.Package.Path.String()
+		if existing, ok := packages[pkg]; !ok || path < existing {
+			packages[pkg] = path
+		}
+	}
+
+	var libraryPaths []string
+	for path := range libraries {
+		libraryPaths = append(libraryPaths, path)
+	}
+	sort.Strings(libraryPaths)
+
+	for _, path := range libraryPaths {
+		if policyPaths[filepath.Clean(path)] {
+			return fmt.Errorf("library %v is also loaded as a policy", path)
+		}
+
+		pkg := libraries[path].Package.Path.String()
+		if policyPath, ok := packages[pkg]; ok {
+			return fmt.Errorf("library %v declares package %v, which is also declared by the policy %v", path, strings.TrimPrefix(pkg, "data."), policyPath)
+		}
+	}
+
+	return nil
+}
+
+func (e *Engine) EnableTracing() {
+	e.trace = true
+}
+
+// SetTraceFormat sets the format that traces are rendered in when
+// tracing is enabled. See TraceFormats for the supported formats.
+func (e *Engine) SetTraceFormat(format string) {
+	e.traceFormat = format
+}
+
+// SetRule restricts the rules that are evaluated by Check to the rule with the given
+// name (e.g. deny_latest_tag). When the name is empty, all of the rules are evaluated.
+func (e *Engine) SetRule(rule string) {
+	e.rule = rule
+}
+
+// SetNamespaceFallback makes the given namespaces a fallback chain, ordered from
+// the highest to the lowest precedence. A rule in one of these namespaces is not
+// evaluated when a namespace earlier in the chain has a rule with the same name,
+// so that a more specific namespace can override the rules of a common one.
+// Namespaces that are not part of the chain are not affected.
+func (e *Engine) SetNamespaceFallback(namespaces []string) {
+	e.fallback = namespaces
+}
+
+// Rules returns the unique, sorted list of rules in the given namespace that are
+// evaluated by Check (e.g. warn and deny).
+func (e *Engine) Rules(namespace string) []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rules, _ := e.getRules(namespace)
+	return rules
+}
+
+// EnableBuiltinErrors enables reporting the errors that occur when evaluating a rule,
+// such as a builtin that returns an error, as an error result of the rule. The other
+// rules are still evaluated. By default, an evaluation error aborts the check.
+func (e *Engine) EnableBuiltinErrors() {
+	e.builtinErrors = true
+}
+
+// EnableRawResults keeps the result set of every query as it was returned by
+// OPA, which is what the raw output reports. The result sets are not kept by
+// default, since they hold on to every value that the policies returned.
+func (e *Engine) EnableRawResults() {
+	e.rawResults = true
+}
+
+// EnablePolicySource enables adding the policy files that define the rule of
+// each result to the result.
+func (e *Engine) EnablePolicySource() {
+	e.policySource = true
+}
+
+// EnablePolicyRoot enables adding the policy path or bundle that the rule of
+// each result was loaded from to the policy_root key of the metadata of the
+// result. This tells apart results of shared policies from local policies when
+// policies are loaded from multiple paths.
+func (e *Engine) EnablePolicyRoot() {
+	e.policyRoot = true
+}
+
+// Reload loads the policies and data again from the paths that the engine was loaded
+// from, and replaces the loaded policies and data with them. This allows long-running
+// processes to pick up changes to the policies. The replacement is atomic: when loading
+// fails, an error is returned and the engine keeps the policies and data it had.
+//
+// Check, CheckCombined, CheckCombinedBy and the methods that return the loaded policies
+// and data, such as Namespaces and Rules, are safe to call concurrently with Reload.
+// A check that is in progress finishes with the policies it started with.
+func (e *Engine) Reload(ctx context.Context) error {
+	if e.reload == nil {
+		return fmt.Errorf("engine was not loaded from a source that can be reloaded")
+	}
+
+	engine, err := e.reload(ctx)
+	if err != nil {
+		return fmt.Errorf("reload: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.modules = engine.modules
+	e.compiler = engine.compiler
+	e.store = engine.store
+	e.policies = engine.policies
+	e.docs = engine.docs
+	e.bundleData = engine.bundleData
+	e.roots = engine.roots
+	e.dataPaths = engine.dataPaths
+	e.wasmBundle = engine.wasmBundle
+	e.wasmBundlePath = engine.wasmBundlePath
+
+	return nil
+}
+
+// Check executes all of the loaded policies against the input and returns the results.
+func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, namespace string) ([]output.CheckResult, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	// The configurations are stored in a map, so they are evaluated in the order
+	// of their paths to keep the order of the results consistent between runs.
+	var paths []string
+	for path := range configs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var checkResults []output.CheckResult
+	for _, path := range paths {
+		config := configs[path]
+
+		// It is possible for a configuration to have multiple configurations. An example of this
+		// are multi-document yaml files where a single filepath represents multiple configs.
+		//
+		// If the current configuration contains multiple configurations, evaluate each policy
+		// independent from one another and aggregate the results under the same file name.
+		// Each result records the index of the document that produced it.
+		if subconfigs, exist := config.([]interface{}); exist {
+
+			checkResult := output.CheckResult{
+				FileName:  path,
+				Namespace: namespace,
+			}
+			for index, subconfig := range subconfigs {
+				result, err := e.check(ctx, e.store, path, subconfig, namespace)
+				if err != nil {
+					return nil, fmt.Errorf("check: %w", err)
+				}
+
+				checkResult.Successes = checkResult.Successes + result.Successes
+				checkResult.Failures = append(checkResult.Failures, withDocument(result.Failures, index)...)
+				checkResult.Warnings = append(checkResult.Warnings, withDocument(result.Warnings, index)...)
+				checkResult.Exceptions = append(checkResult.Exceptions, withDocument(result.Exceptions, index)...)
+				checkResult.Errors = append(checkResult.Errors, withDocument(result.Errors, index)...)
+				checkResult.Queries = append(checkResult.Queries, result.Queries...)
+			}
+			checkResults = append(checkResults, checkResult)
+			continue
+		}
+
+		checkResult, err := e.check(ctx, e.store, path, config, namespace)
+		if err != nil {
+			return nil, fmt.Errorf("check: %w", err)
+		}
+
+		checkResults = append(checkResults, checkResult)
+	}
+
+	return checkResults, nil
+}
+
+// CheckCombined combines the input and evaluates the policies against the combined result.
+func (e *Engine) CheckCombined(ctx context.Context, configs map[string]interface{}, namespace string) (output.CheckResult, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	combinedConfigs := parser.CombineConfigurations(configs)
+
+	result, err := e.check(ctx, e.store, "Combined", combinedConfigs["Combined"], namespace)
+	if err != nil {
+		return output.CheckResult{}, fmt.Errorf("check: %w", err)
+	}
+
+	return result, nil
+}
+
+// CheckCombinedBy combines the input into groups using the given expression and evaluates
+// the policies against each group. See parser.CombineConfigurationsBy for the supported
+// expressions. The results are sorted by the name of the group.
+func (e *Engine) CheckCombinedBy(ctx context.Context, configs map[string]interface{}, namespace string, expression string) ([]output.CheckResult, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	groups, err := parser.CombineConfigurationsBy(configs, expression)
+	if err != nil {
+		return nil, fmt.Errorf("combine configurations: %w", err)
+	}
+
+	var names []string
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []output.CheckResult
+	for _, name := range names {
+		store, err := e.groupStore(name)
+		if err != nil {
+			return nil, fmt.Errorf("load data of group %v: %w", name, err)
+		}
+
+		result, err := e.check(ctx, store, name, groups[name], namespace)
+		if err != nil {
+			return nil, fmt.Errorf("check: %w", err)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// groupStore returns the store to evaluate the given group of CheckCombinedBy with. When the
+// group data directory contains a directory for the key of the group, the data files directly
+// in that directory are loaded together with the data paths. Otherwise, the store of the
+// engine is used.
+func (e *Engine) groupStore(group string) (storage.Store, error) {
+	if e.groupData == "" || !strings.HasPrefix(group, parser.CombinedDefaultGroup+"/") {
+		return e.store, nil
+	}
+
+	// The key of a group can come from the documents themselves, so the directory
+	// must not be outside of the group data directory (e.g. a key of ../secrets).
+	key := strings.TrimPrefix(group, parser.CombinedDefaultGroup+"/")
+	root := filepath.Clean(e.groupData)
+	groupDir := filepath.Join(root, filepath.FromSlash(key))
+	if !strings.HasPrefix(groupDir, root+string(filepath.Separator)) {
+		return e.store, nil
+	}
+
+	// The files of nested directories belong to the groups with the longer keys,
+	// so only the files directly in the directory of the group are loaded.
+	entries, err := ioutil.ReadDir(groupDir)
+	if os.IsNotExist(err) {
+		return e.store, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("read group data directory: %w", err)
+	}
+
+	var groupFiles []string
+	for _, entry := range entries {
+		if entry.IsDir() || !contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(entry.Name())) {
+			continue
+		}
+
+		groupFiles = append(groupFiles, filepath.Join(groupDir, entry.Name()))
+	}
+
+	if len(groupFiles) == 0 {
+		return e.store, nil
+	}
+
+	dataPaths := append(append([]string{}, e.dataPaths...), groupFiles...)
+	store, _, err := loadDocuments(dataPaths, e.bundleData)
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Namespaces returns all of the namespaces in the engine.
+func (e *Engine) Namespaces() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var namespaces []string
+	for _, module := range e.modules {
+		namespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		if contains(namespaces, namespace) {
+			continue
+		}
+
+		namespaces = append(namespaces, namespace)
+	}
+
+	sort.Strings(namespaces)
+
+	return namespaces
+}
+
+// Documents returns all of the documents loaded into the engine.
+// The result is a map where the key is the filepath of the document
+// and its value is the raw contents of the loaded document.
+func (e *Engine) Documents() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.docs
+}
+
+// Policies returns all of the policies loaded into the engine.
+// The result is a map where the key is the filepath of the policy
+// and its value is the raw contents of the loaded policy.
+func (e *Engine) Policies() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.policies
+}
+
+// Compiler returns the compiler from the loaded policies.
+func (e *Engine) Compiler() *ast.Compiler {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.compiler
+}
+
+// Store returns the store from the loaded documents.
+func (e *Engine) Store() storage.Store {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.store
+}
+
+// Modules returns the modules from the loaded policies.
+func (e *Engine) Modules() map[string]*ast.Module {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.modules
+}
+
+// Runtime returns the runtime of the engine.
+func (e *Engine) Runtime() *ast.Term {
+	env := ast.NewObject()
+	for _, pair := range os.Environ() {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) == 1 {
+			env.Insert(ast.StringTerm(parts[0]), ast.NullTerm())
+		} else if len(parts) > 1 {
+			env.Insert(ast.StringTerm(parts[0]), ast.StringTerm(parts[1]))
+		}
+	}
+
+	obj := ast.NewObject()
+	obj.Insert(ast.StringTerm("env"), ast.NewTerm(env))
+	obj.Insert(ast.StringTerm("version"), ast.StringTerm(version.Version))
+	obj.Insert(ast.StringTerm("commit"), ast.StringTerm(version.Vcs))
+
+	return ast.NewTerm(obj)
+}
+
+// getRules returns the unique, sorted list of rules in the given namespace that are evaluated
+// by Check (e.g. warn and deny), as well as the total number of times these rules appear
+// in the policies.
+func (e *Engine) getRules(namespace string) ([]string, int) {
+
+	// The modules are stored in a map, so they are sorted by their path to
+	// guarantee that the rules are always discovered in the same order.
+	var modulePaths []string
+	for path := range e.modules {
+		modulePaths = append(modulePaths, path)
+	}
+	sort.Strings(modulePaths)
+
+	var rules []string
+	var ruleCount int
+	for _, modulePath := range modulePaths {
+		module := e.modules[modulePath]
+		currentNamespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		if currentNamespace != namespace {
+			continue
+		}
+
+		// When performing policy evaluation using Check, there are a few rules that are special (e.g. warn and deny).
+		// In order to validate the inputs against the policies, these rules need to be identified and how often
+		// they appear in the policies.
+		for r := range module.Rules {
+			currentRule := module.Rules[r].Head.Name.String()
+
+			if !isFailure(currentRule) && !isWarning(currentRule) {
+				continue
+			}
+
+			if e.rule != "" && currentRule != e.rule {
+				continue
+			}
+
+			if e.isShadowed(namespace, currentRule) {
+				continue
+			}
+
+			// When checking the policies we want a unique list of rules to evaluate them one by one, but we also want
+			// to keep track of how many rules we will be evaluating so we can calculate the final result.
+			//
+			// For example, a policy can have two deny rules that both contain different bodies. In this case the list
+			// of rules will only contain deny, but the rule count would be two.
+			ruleCount++
+
+			if !contains(rules, currentRule) {
+				rules = append(rules, currentRule)
+			}
+		}
+	}
+
+	sort.Strings(rules)
+
+	return rules, ruleCount
+}
+
+// isShadowed returns true when the given rule is defined in a namespace that
+// comes before the given namespace in the fallback chain.
+func (e *Engine) isShadowed(namespace string, rule string) bool {
+	if !contains(e.fallback, namespace) {
+		return false
+	}
+
+	for _, fallbackNamespace := range e.fallback {
+		if fallbackNamespace == namespace {
+			return false
+		}
+
+		if e.definesRule(fallbackNamespace, rule) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// definesRule returns true when a module in the given namespace
+// has a rule with the given name.
+func (e *Engine) definesRule(namespace string, rule string) bool {
+	for _, module := range e.modules {
+		currentNamespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		if currentNamespace != namespace {
+			continue
+		}
+
+		for _, moduleRule := range module.Rules {
+			if moduleRule.Head.Name.String() == rule {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// The kinds of rules that are evaluated by Check.
+const (
+	RuleKindFailure   = "failure"
+	RuleKindWarning   = "warning"
+	RuleKindException = "exception"
+)
+
+// RuleDefinition describes a rule that is evaluated by Check.
+type RuleDefinition struct {
+	Namespace string `json:"namespace"`
+	Rule      string `json:"rule"`
+	Kind      string `json:"kind"`
+
+	// Sources are the policy files that define the rule.
+	Sources []string `json:"sources"`
+}
+
+// RuleDefinitions returns the rules in the given namespace that Check evaluates,
+// which are the warn and deny rules along with the exception rule, without
+// evaluating them. The rules are sorted by name.
+func (e *Engine) RuleDefinitions(namespace string) []RuleDefinition {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	rules, _ := e.getRules(namespace)
+
+	var definitions []RuleDefinition
+	for _, rule := range rules {
+		kind := RuleKindFailure
+		if isWarning(rule) {
+			kind = RuleKindWarning
+		}
+
+		definitions = append(definitions, RuleDefinition{
+			Namespace: namespace,
+			Rule:      rule,
+			Kind:      kind,
+			Sources:   e.getRuleSources(namespace, rule),
+		})
+	}
+
+	if e.definesRule(namespace, "exception") {
+		definitions = append(definitions, RuleDefinition{
+			Namespace: namespace,
+			Rule:      "exception",
+			Kind:      RuleKindException,
+			Sources:   e.getRuleSources(namespace, "exception"),
+		})
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Rule < definitions[j].Rule
+	})
+
+	return definitions
+}
+
+// Deprecation describes a rule that uses a deprecated name.
+type Deprecation struct {
+	Namespace   string
+	Rule        string
+	Replacement string
+
+	// Location is the file and line of the rule in the policy,
+	// e.g. policy/deny.rego:12.
+	Location string
+}
+
+// Deprecations returns the rules in the given namespace that use a deprecated name,
+// such as violation rules that should be renamed to deny. The rules are returned
+// in the order that they appear in the policies, sorted by the policy path.
+func (e *Engine) Deprecations(namespace string) []Deprecation {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var modulePaths []string
+	for path := range e.modules {
+		modulePaths = append(modulePaths, path)
+	}
+	sort.Strings(modulePaths)
+
+	var deprecations []Deprecation
+	for _, modulePath := range modulePaths {
+		module := e.modules[modulePath]
+		currentNamespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		if currentNamespace != namespace {
+			continue
+		}
+
+		for _, rule := range module.Rules {
+			currentRule := rule.Head.Name.String()
+			if !isDeprecated(currentRule) {
+				continue
+			}
+
+			location := modulePath
+			if rule.Location != nil {
+				location = fmt.Sprintf("%s:%d", modulePath, rule.Location.Row)
+			}
+
+			deprecations = append(deprecations, Deprecation{
+				Namespace:   namespace,
+				Rule:        currentRule,
+				Replacement: "deny" + strings.TrimPrefix(currentRule, "violation"),
+				Location:    location,
+			})
+		}
+	}
+
+	return deprecations
+}
+
+// InvalidMessage describes a rule that returned a value that is not a message,
+// such as deny[42]. The value is still reported, using it as the message.
+type InvalidMessage struct {
+	Query string
+	Value string
+}
+
+// InvalidMessages returns the rules that returned a value that is not a message
+// in the checks of the engine, in the order that they were first returned. Every
+// query and value is only returned once, regardless of the number of documents.
+func (e *Engine) InvalidMessages() []InvalidMessage {
+	e.messagesMu.Lock()
+	defer e.messagesMu.Unlock()
+
+	return append([]InvalidMessage(nil), e.invalidMessages...)
+}
+
+func (e *Engine) addInvalidMessage(message InvalidMessage) {
+	e.messagesMu.Lock()
+	defer e.messagesMu.Unlock()
+
+	if e.seenInvalidMessages == nil {
+		e.seenInvalidMessages = make(map[InvalidMessage]bool)
+	}
+
+	if e.seenInvalidMessages[message] {
+		return
+	}
 
-	return engine, nil
+	e.seenInvalidMessages[message] = true
+	e.invalidMessages = append(e.invalidMessages, message)
 }
 
-func (e *Engine) EnableTracing() {
-	e.trace = true
+// UntestedRule describes a rule that is not referenced by any test.
+type UntestedRule struct {
+	Namespace string
+	Rule      string
+
+	// Location is the file and line of the first definition of the
+	// rule in the policies, e.g. policy/deny.rego:12.
+	Location string
 }
 
-// Check executes all of the loaded policies against the input and returns the results.
-func (e *Engine) Check(ctx context.Context, configs map[string]interface{}, namespace string) ([]output.CheckResult, error) {
-	var checkResults []output.CheckResult
-	for path, config := range configs {
+// UntestedRules returns the rules that are evaluated by Check (e.g. warn and deny)
+// that are not referenced by any test. A rule is referenced by a test when the body
+// of a test_ rule refers to the rule, either from the same package, as is common
+// for tests in _test.rego files, or through data from any other package. The rules
+// in _test.rego files are not checked themselves. The rules are returned in the
+// order that they appear in the policies, sorted by the policy path.
+func (e *Engine) UntestedRules() []UntestedRule {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	modules := e.compiler.Modules
+
+	var modulePaths []string
+	for path := range modules {
+		modulePaths = append(modulePaths, path)
+	}
+	sort.Strings(modulePaths)
+
+	// The compiler resolves the references of the rules, including the
+	// references to rules in the same package, to their full data path.
+	var tests []*ast.Rule
+	for _, modulePath := range modulePaths {
+		for _, rule := range modules[modulePath].Rules {
+			if strings.HasPrefix(rule.Head.Name.String(), "test_") {
+				tests = append(tests, rule)
+			}
+		}
+	}
 
-		// It is possible for a configuration to have multiple configurations. An example of this
-		// are multi-document yaml files where a single filepath represents multiple configs.
-		//
-		// If the current configuration contains multiple configurations, evaluate each policy
-		// independent from one another and aggregate the results under the same file name.
-		if subconfigs, exist := config.([]interface{}); exist {
+	var untested []UntestedRule
+	var checked []string
+	for _, modulePath := range modulePaths {
+		if strings.HasSuffix(modulePath, "_test.rego") {
+			continue
+		}
 
-			checkResult := output.CheckResult{
-				FileName:  path,
-				Namespace: namespace,
+		module := modules[modulePath]
+		namespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		for _, rule := range module.Rules {
+			currentRule := rule.Head.Name.String()
+			if !isFailure(currentRule) && !isWarning(currentRule) {
+				continue
 			}
-			for _, subconfig := range subconfigs {
-				result, err := e.check(ctx, path, subconfig, namespace)
-				if err != nil {
-					return nil, fmt.Errorf("check: %w", err)
-				}
 
-				checkResult.Successes = checkResult.Successes + result.Successes
-				checkResult.Failures = append(checkResult.Failures, result.Failures...)
-				checkResult.Warnings = append(checkResult.Warnings, result.Warnings...)
-				checkResult.Exceptions = append(checkResult.Exceptions, result.Exceptions...)
-				checkResult.Queries = append(checkResult.Queries, result.Queries...)
+			ruleRef := module.Package.Path.Append(ast.StringTerm(currentRule))
+			if contains(checked, ruleRef.String()) {
+				continue
 			}
-			checkResults = append(checkResults, checkResult)
-			continue
-		}
+			checked = append(checked, ruleRef.String())
 
-		checkResult, err := e.check(ctx, path, config, namespace)
-		if err != nil {
-			return nil, fmt.Errorf("check: %w", err)
-		}
+			if isReferenced(tests, ruleRef) {
+				continue
+			}
 
-		checkResults = append(checkResults, checkResult)
+			location := modulePath
+			if rule.Location != nil {
+				location = fmt.Sprintf("%s:%d", modulePath, rule.Location.Row)
+			}
+
+			untested = append(untested, UntestedRule{
+				Namespace: namespace,
+				Rule:      currentRule,
+				Location:  location,
+			})
+		}
 	}
 
-	return checkResults, nil
+	return untested
 }
 
-// CheckCombined combines the input and evaluates the policies against the combined result.
-func (e *Engine) CheckCombined(ctx context.Context, configs map[string]interface{}, namespace string) (output.CheckResult, error) {
-	combinedConfigs := parser.CombineConfigurations(configs)
+// isReferenced returns true when one of the given rules refers to the given
+// reference, or to a value inside of it.
+func isReferenced(rules []*ast.Rule, ref ast.Ref) bool {
+	var referenced bool
+	for _, rule := range rules {
+		ast.WalkRefs(rule, func(r ast.Ref) bool {
+			if r.HasPrefix(ref) {
+				referenced = true
+			}
 
-	result, err := e.check(ctx, "Combined", combinedConfigs["Combined"], namespace)
-	if err != nil {
-		return output.CheckResult{}, fmt.Errorf("check: %w", err)
+			return referenced
+		})
+
+		if referenced {
+			return true
+		}
 	}
 
-	return result, nil
+	return false
 }
 
-// Namespaces returns all of the namespaces in the engine.
-func (e *Engine) Namespaces() []string {
-	var namespaces []string
-	for _, module := range e.Modules() {
-		namespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
-		if contains(namespaces, namespace) {
+// getRuleSources returns the sorted list of policy files that define
+// the given rule in the given namespace.
+func (e *Engine) getRuleSources(namespace string, rule string) []string {
+	var sources []string
+	for path, module := range e.modules {
+		currentNamespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		if currentNamespace != namespace {
 			continue
 		}
 
-		namespaces = append(namespaces, namespace)
+		for _, moduleRule := range module.Rules {
+			if moduleRule.Head.Name.String() != rule {
+				continue
+			}
+
+			source := filepath.ToSlash(filepath.Clean(path))
+			if moduleRule.Location != nil && moduleRule.Location.File != "" {
+				source = filepath.ToSlash(filepath.Clean(moduleRule.Location.File))
+			}
+
+			if !contains(sources, source) {
+				sources = append(sources, source)
+			}
+		}
 	}
 
-	return namespaces
-}
+	sort.Strings(sources)
 
-// Documents returns all of the documents loaded into the engine.
-// The result is a map where the key is the filepath of the document
-// and its value is the raw contents of the loaded document.
-func (e *Engine) Documents() map[string]string {
-	return e.docs
+	return sources
 }
 
-// Policies returns all of the policies loaded into the engine.
-// The result is a map where the key is the filepath of the policy
-// and its value is the raw contents of the loaded policy.
-func (e *Engine) Policies() map[string]string {
-	return e.policies
-}
+// getRuleDescription returns the description of the given rule in the given
+// namespace, which is the block of comments directly above the first definition
+// of the rule in the policies sorted by path. When the block is a METADATA
+// block, the description (or else the title) field is used.
+func (e *Engine) getRuleDescription(namespace string, rule string) string {
+	var modulePaths []string
+	for path := range e.modules {
+		modulePaths = append(modulePaths, path)
+	}
+	sort.Strings(modulePaths)
 
-// Compiler returns the compiler from the loaded policies.
-func (e *Engine) Compiler() *ast.Compiler {
-	return e.compiler
-}
+	for _, modulePath := range modulePaths {
+		module := e.modules[modulePath]
+		currentNamespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
+		if currentNamespace != namespace {
+			continue
+		}
 
-// Store returns the store from the loaded documents.
-func (e *Engine) Store() storage.Store {
-	return e.store
-}
+		for _, moduleRule := range module.Rules {
+			if moduleRule.Head.Name.String() != rule || moduleRule.Location == nil {
+				continue
+			}
 
-// Modules returns the modules from the loaded policies.
-func (e *Engine) Modules() map[string]*ast.Module {
-	return e.modules
+			if description := commentDescription(module.Comments, moduleRule.Location.Row); description != "" {
+				return description
+			}
+		}
+	}
+
+	return ""
 }
 
-// Runtime returns the runtime of the engine.
-func (e *Engine) Runtime() *ast.Term {
-	env := ast.NewObject()
-	for _, pair := range os.Environ() {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) == 1 {
-			env.Insert(ast.StringTerm(parts[0]), ast.NullTerm())
-		} else if len(parts) > 1 {
-			env.Insert(ast.StringTerm(parts[0]), ast.StringTerm(parts[1]))
+// commentDescription returns the text of the block of comments that ends on the
+// line above the given row, with the lines of the block joined by spaces.
+func commentDescription(comments []*ast.Comment, row int) string {
+	rows := make(map[int]string)
+	for _, comment := range comments {
+		if comment.Location != nil {
+			rows[comment.Location.Row] = strings.TrimSpace(string(comment.Text))
 		}
 	}
 
-	obj := ast.NewObject()
-	obj.Insert(ast.StringTerm("env"), ast.NewTerm(env))
-	obj.Insert(ast.StringTerm("version"), ast.StringTerm(version.Version))
-	obj.Insert(ast.StringTerm("commit"), ast.StringTerm(version.Vcs))
+	var lines []string
+	for current := row - 1; ; current-- {
+		line, ok := rows[current]
+		if !ok {
+			break
+		}
 
-	return ast.NewTerm(obj)
+		lines = append([]string{line}, lines...)
+	}
+
+	if len(lines) > 0 && lines[0] == "METADATA" {
+		fields := make(map[string]string)
+		for _, line := range lines[1:] {
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) == 2 {
+				fields[strings.TrimSpace(parts[0])] = strings.Trim(strings.TrimSpace(parts[1]), `"'`)
+			}
+		}
+
+		if fields["description"] != "" {
+			return fields["description"]
+		}
+
+		return fields["title"]
+	}
+
+	return strings.TrimSpace(strings.Join(lines, " "))
 }
 
-func (e *Engine) check(ctx context.Context, path string, config interface{}, namespace string) (output.CheckResult, error) {
-	var rules []string
-	var ruleCount int
-	for _, module := range e.Modules() {
+// getRuleRoots returns the sorted list of policy paths and bundles that
+// define the given rule in the given namespace.
+func (e *Engine) getRuleRoots(namespace string, rule string) []string {
+	var roots []string
+	for path, module := range e.modules {
 		currentNamespace := strings.Replace(module.Package.Path.String(), "data.", "", 1)
 		if currentNamespace != namespace {
 			continue
 		}
 
-		// When performing policy evaluation using Check, there are a few rules that are special (e.g. warn and deny).
-		// In order to validate the inputs against the policies, these rules need to be identified and how often
-		// they appear in the policies.
-		for r := range module.Rules {
-			currentRule := module.Rules[r].Head.Name.String()
-
-			if !isFailure(currentRule) && !isWarning(currentRule) {
+		for _, moduleRule := range module.Rules {
+			root, ok := e.roots[path]
+			if moduleRule.Head.Name.String() != rule || !ok {
 				continue
 			}
 
-			// When checking the policies we want a unique list of rules to evaluate them one by one, but we also want
-			// to keep track of how many rules we will be evaluating so we can calculate the final result.
-			//
-			// For example, a policy can have two deny rules that both contain different bodies. In this case the list
-			// of rules will only contain deny, but the rule count would be two.
-			ruleCount++
-
-			if !contains(rules, currentRule) {
-				rules = append(rules, currentRule)
+			if !contains(roots, root) {
+				roots = append(roots, root)
 			}
 		}
 	}
 
+	sort.Strings(roots)
+
+	return roots
+}
+
+// withPolicyRoot adds the policy roots to the metadata of the results. A rule can
+// be defined in several roots, in which case all of them are added as a list, as
+// the root of the rule body that produced the result is not known.
+func withPolicyRoot(results []output.Result, roots []string) []output.Result {
+	if len(roots) == 0 {
+		return results
+	}
+
+	var root interface{} = roots[0]
+	if len(roots) > 1 {
+		root = roots
+	}
+
+	for r := range results {
+		if results[r].Metadata == nil {
+			results[r].Metadata = make(map[string]interface{})
+		}
+
+		results[r].Metadata["policy_root"] = root
+	}
+
+	return results
+}
+
+func (e *Engine) check(ctx context.Context, store storage.Store, path string, config interface{}, namespace string) (output.CheckResult, error) {
+	rules, ruleCount := e.getRules(namespace)
+
 	checkResult := output.CheckResult{
 		FileName:  path,
 		Namespace: namespace,
 	}
+
+	input, err := parseInput(config)
+	if err != nil {
+		return output.CheckResult{}, fmt.Errorf("parse input: %w", err)
+	}
+
 	var successes int
 	for _, rule := range rules {
 
@@ -273,11 +1376,22 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 		// is queried, so the severity prefix must be removed.
 		exceptionQuery := fmt.Sprintf("data.%s.exception[_][_] == %q", namespace, removeRulePrefix(rule))
 
-		exceptionQueryResult, err := e.query(ctx, config, exceptionQuery)
+		description := e.getRuleDescription(namespace, rule)
+
+		exceptionQueryResult, err := e.queryException(ctx, store, input, namespace, rule, exceptionQuery)
+		if err != nil && e.builtinErrors {
+			checkResult.Errors = append(checkResult.Errors, output.Result{Message: err.Error(), Rule: rule, Description: description})
+			continue
+		}
 		if err != nil {
 			return output.CheckResult{}, fmt.Errorf("query exception: %w", err)
 		}
 
+		var policySource []string
+		if e.policySource {
+			policySource = e.getRuleSources(namespace, rule)
+		}
+
 		var exceptions []output.Result
 		for _, exceptionResult := range exceptionQueryResult.Results {
 
@@ -286,12 +1400,19 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 			// which exception was trigged.
 			if exceptionResult.Passed() {
 				exceptionResult.Message = exceptionQuery
+				exceptionResult.Rule = rule
+				exceptionResult.PolicySource = policySource
+				exceptionResult.Description = description
 				exceptions = append(exceptions, exceptionResult)
 			}
 		}
 
 		ruleQuery := fmt.Sprintf("data.%s.%s", namespace, rule)
-		ruleQueryResult, err := e.query(ctx, config, ruleQuery)
+		ruleQueryResult, err := e.query(ctx, store, input, ruleQuery)
+		if err != nil && e.builtinErrors {
+			checkResult.Errors = append(checkResult.Errors, output.Result{Message: err.Error(), Rule: rule, Description: description})
+			continue
+		}
 		if err != nil {
 			return output.CheckResult{}, fmt.Errorf("query rule: %w", err)
 		}
@@ -311,6 +1432,9 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 				continue
 			}
 
+			ruleResult.Rule = rule
+			ruleResult.PolicySource = policySource
+			ruleResult.Description = description
 			if isFailure(rule) {
 				failures = append(failures, ruleResult)
 			} else {
@@ -318,6 +1442,13 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 			}
 		}
 
+		if e.policyRoot {
+			roots := e.getRuleRoots(namespace, rule)
+			failures = withPolicyRoot(failures, roots)
+			warnings = withPolicyRoot(warnings, roots)
+			exceptions = withPolicyRoot(exceptions, e.getRuleRoots(namespace, "exception"))
+		}
+
 		checkResult.Failures = append(checkResult.Failures, failures...)
 		checkResult.Warnings = append(checkResult.Warnings, warnings...)
 		checkResult.Exceptions = append(checkResult.Exceptions, exceptions...)
@@ -331,7 +1462,7 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 	//
 	// In the event that the total number of results is less than the total number of rules, we can safely assume
 	// that the difference were successful results.
-	resultCount := len(checkResult.Failures) + len(checkResult.Warnings) + len(checkResult.Exceptions) + successes
+	resultCount := len(checkResult.Failures) + len(checkResult.Warnings) + len(checkResult.Exceptions) + len(checkResult.Errors) + successes
 	if resultCount < ruleCount {
 		successes += ruleCount - resultCount
 	}
@@ -340,19 +1471,68 @@ func (e *Engine) check(ctx context.Context, path string, config interface{}, nam
 	return checkResult, nil
 }
 
+// parseInput converts the input into the value that the queries are evaluated
+// against. The input is converted once for all of the queries of a check, rather
+// than by every query, which keeps large inputs such as combined files from being
+// converted, and held in memory, again for every rule. An input without a value,
+// such as an empty document, is null, as it was when the input was passed to rego
+// without converting it first.
+func parseInput(config interface{}) (ast.Value, error) {
+	if config == nil {
+		return ast.Null{}, nil
+	}
+
+	if err := util.RoundTrip(&config); err != nil {
+		return nil, fmt.Errorf("round trip: %w", err)
+	}
+
+	value, err := ast.InterfaceToValue(config)
+	if err != nil {
+		return nil, fmt.Errorf("convert to value: %w", err)
+	}
+
+	return value, nil
+}
+
 // query is a low-level method that returns the result of executing a single query against the input.
 //
 // Example queries could include:
 // data.main.deny to query the deny rule in the main namespace
 // data.main.warn to query the warn rule in the main namespace
-func (e *Engine) query(ctx context.Context, input interface{}, query string) (output.QueryResult, error) {
+func (e *Engine) query(ctx context.Context, store storage.Store, input ast.Value, query string) (output.QueryResult, error) {
 	options := []func(r *rego.Rego){
-		rego.Input(input),
 		rego.Query(query),
-		rego.Compiler(e.Compiler()),
-		rego.Store(e.Store()),
+		rego.Store(store),
 		rego.Runtime(e.Runtime()),
-		rego.Trace(e.trace),
+		rego.ParsedInput(input),
+	}
+
+	// Policies compiled to WASM are evaluated by the WASM module of the bundle
+	// rather than by the compiled Rego sources, which do not support tracing.
+	if e.wasmBundle != nil {
+		if e.trace {
+			return output.QueryResult{}, fmt.Errorf("tracing is not supported for the WASM-compiled policies of bundle %v", e.wasmBundlePath)
+		}
+
+		options = append(options, rego.Target("wasm"), rego.ParsedBundle(e.wasmBundlePath, e.wasmBundle))
+	} else {
+		options = append(options, rego.Compiler(e.compiler))
+	}
+
+	// Builtins that fail are undefined by default, which hides the error. When errors
+	// are reported as results, the builtin errors are returned by the evaluation instead.
+	if e.builtinErrors {
+		options = append(options, rego.StrictBuiltinErrors(true))
+	}
+
+	// Structured traces need access to the individual trace events, which are
+	// collected by a separate tracer rather than the built-in text tracing.
+	jsonTrace := e.trace && e.traceFormat == TraceFormatJSON
+	tracer := topdown.NewBufferTracer()
+	if jsonTrace {
+		options = append(options, rego.QueryTracer(tracer))
+	} else {
+		options = append(options, rego.Trace(e.trace))
 	}
 
 	regoInstance := rego.New(options...)
@@ -361,15 +1541,23 @@ func (e *Engine) query(ctx context.Context, input interface{}, query string) (ou
 		return output.QueryResult{}, fmt.Errorf("evaluating policy: %w", err)
 	}
 
-	// After the evaluation of the policy, the results of the trace (stdout) will be populated
-	// for the query. Once populated, format the trace results into a human readable format.
-	buf := new(bytes.Buffer)
-	rego.PrintTrace(buf, regoInstance)
-
 	var traces []string
-	for _, line := range strings.Split(buf.String(), "\n") {
-		if len(line) > 0 {
-			traces = append(traces, line)
+	if jsonTrace {
+		traces, err = JSONTrace(*tracer)
+		if err != nil {
+			return output.QueryResult{}, fmt.Errorf("json trace: %w", err)
+		}
+	} else {
+
+		// After the evaluation of the policy, the results of the trace (stdout) will be populated
+		// for the query. Once populated, format the trace results into a human readable format.
+		buf := new(bytes.Buffer)
+		rego.PrintTrace(buf, regoInstance)
+
+		for _, line := range strings.Split(buf.String(), "\n") {
+			if len(line) > 0 {
+				traces = append(traces, line)
+			}
 		}
 	}
 
@@ -377,6 +1565,13 @@ func (e *Engine) query(ctx context.Context, input interface{}, query string) (ou
 	for _, result := range resultSet {
 		for _, expression := range result.Expressions {
 
+			// Complete rules that return a single string (e.g. deny = msg) are
+			// treated the same as a partial rule that returned one message.
+			if val, ok := expression.Value.(string); ok && val != "" {
+				results = append(results, output.Result{Message: val})
+				continue
+			}
+
 			// Rego rules that are intended for evaluation should return a slice of values.
 			// For example, deny[msg] or violation[{"msg": msg}].
 			//
@@ -409,6 +1604,16 @@ func (e *Engine) query(ctx context.Context, input interface{}, query string) (ou
 					}
 
 					results = append(results, result)
+
+				// Policies that mistakenly return another type of value (e.g. deny[42])
+				// are still reported, using the value as the message.
+				default:
+					e.addInvalidMessage(InvalidMessage{Query: query, Value: fmt.Sprint(val)})
+
+					result := output.Result{
+						Message: fmt.Sprint(val),
+					}
+					results = append(results, result)
 				}
 			}
 		}
@@ -420,9 +1625,81 @@ func (e *Engine) query(ctx context.Context, input interface{}, query string) (ou
 		Traces:  traces,
 	}
 
+	if e.rawResults {
+		if resultSet == nil {
+			resultSet = rego.ResultSet{}
+		}
+
+		queryResult.Raw = resultSet
+	}
+
+	return queryResult, nil
+}
+
+// queryException evaluates the exception query of the given rule. The WASM module of a
+// bundle only evaluates the rules that are its entrypoints, so for WASM-compiled policies
+// the exception rule itself is evaluated and the rule is looked up in its result.
+func (e *Engine) queryException(ctx context.Context, store storage.Store, input ast.Value, namespace string, rule string, exceptionQuery string) (output.QueryResult, error) {
+	if e.wasmBundle == nil {
+		return e.query(ctx, store, input, exceptionQuery)
+	}
+
+	queryResult := output.QueryResult{Query: exceptionQuery}
+	if e.rawResults {
+		queryResult.Raw = rego.ResultSet{}
+	}
+
+	if !e.definesRule(namespace, "exception") {
+		return queryResult, nil
+	}
+
+	regoInstance := rego.New(
+		rego.Query(fmt.Sprintf("data.%s.exception", namespace)),
+		rego.Store(store),
+		rego.Runtime(e.Runtime()),
+		rego.ParsedInput(input),
+		rego.Target("wasm"),
+		rego.ParsedBundle(e.wasmBundlePath, e.wasmBundle),
+	)
+
+	resultSet, err := regoInstance.Eval(ctx)
+	if err != nil {
+		return output.QueryResult{}, fmt.Errorf("evaluating policy: %w", err)
+	}
+
+	// The exception rule is a set of lists of rule names, e.g. [["run_as_root"]].
+	// A match is a single passing result, the same as the exception query returns.
+	name := removeRulePrefix(rule)
+	for _, result := range resultSet {
+		for _, expression := range result.Expressions {
+			exceptions, _ := expression.Value.([]interface{})
+			for _, exception := range exceptions {
+				rules, _ := exception.([]interface{})
+				for _, exceptedRule := range rules {
+					if exceptedRule == name && len(queryResult.Results) == 0 {
+						queryResult.Results = append(queryResult.Results, output.Result{})
+					}
+				}
+			}
+		}
+	}
+
+	if e.rawResults && resultSet != nil {
+		queryResult.Raw = resultSet
+	}
+
 	return queryResult, nil
 }
 
+func withDocument(results []output.Result, index int) []output.Result {
+	for r := range results {
+		document := index
+		results[r].Document = &document
+	}
+
+	return results
+}
+
 func isWarning(rule string) bool {
 	warningRegex := regexp.MustCompile("^warn(_[a-zA-Z0-9]+)*$")
 	return warningRegex.MatchString(rule)
@@ -433,6 +1710,36 @@ func isFailure(rule string) bool {
 	return failureRegex.MatchString(rule)
 }
 
+// mergeDocuments merges the source documents into the destination documents. Objects
+// are merged recursively, while any other value that exists in both is a conflict.
+func mergeDocuments(destination map[string]interface{}, source map[string]interface{}) error {
+	for key, value := range source {
+		existing, ok := destination[key]
+		if !ok {
+			destination[key] = value
+			continue
+		}
+
+		existingObject, existingIsObject := existing.(map[string]interface{})
+		valueObject, valueIsObject := value.(map[string]interface{})
+		if !existingIsObject || !valueIsObject {
+			return fmt.Errorf("conflicting values for %v", key)
+		}
+
+		if err := mergeDocuments(existingObject, valueObject); err != nil {
+			return fmt.Errorf("%v: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// isDeprecated returns true if the rule uses the legacy violation name, which
+// is evaluated the same as deny.
+func isDeprecated(rule string) bool {
+	return isFailure(rule) && strings.HasPrefix(rule, "violation")
+}
+
 func contains(collection []string, item string) bool {
 	for _, value := range collection {
 		if strings.EqualFold(value, item) {
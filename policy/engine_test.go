@@ -1,9 +1,20 @@
 package policy
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"testing/fstest"
 
+	"github.com/open-policy-agent/conftest/output"
 	"github.com/open-policy-agent/conftest/parser"
 )
 
@@ -76,6 +87,47 @@ func TestTracing(t *testing.T) {
 		}
 	})
 
+	t.Run("with json tracing", func(t *testing.T) {
+		ctx := context.Background()
+
+		policies := []string{"../examples/kubernetes/policy"}
+		engine, err := Load(ctx, policies)
+		if err != nil {
+			t.Fatalf("loading policies: %v", err)
+		}
+
+		engine.EnableTracing()
+		engine.SetTraceFormat(TraceFormatJSON)
+
+		configFiles := []string{"../examples/kubernetes/service.yaml"}
+		configs, err := parser.ParseConfigurations(configFiles)
+		if err != nil {
+			t.Fatalf("loading configs: %v", err)
+		}
+
+		results, err := engine.Check(ctx, configs, "main")
+		if err != nil {
+			t.Fatalf("could not process policy file: %s", err)
+		}
+
+		for _, query := range results[0].Queries {
+			if len(query.Traces) == 0 {
+				t.Errorf("Tracing error: Expected trace objects, got 0 instead")
+			}
+
+			for _, trace := range query.Traces {
+				var event TraceEvent
+				if err := json.Unmarshal([]byte(trace), &event); err != nil {
+					t.Fatalf("Tracing error: Expected a JSON trace event, got %v", trace)
+				}
+
+				if event.Op == "" {
+					t.Errorf("Tracing error: Expected an op, got none in %v", trace)
+				}
+			}
+		}
+	})
+
 	t.Run("without tracing", func(t *testing.T) {
 		ctx := context.Background()
 
@@ -245,3 +297,1466 @@ func TestIsFailure(t *testing.T) {
 		})
 	}
 }
+
+// writePolicy writes the policy to a policy.rego file in a temporary
+// directory, and returns the directory.
+func writePolicy(t *testing.T, policy string) string {
+	return writePolicies(t, map[string]string{"policy.rego": policy})
+}
+
+// writePolicies writes the policies, along with any data files, keyed by
+// their path in the directory to a temporary directory, and returns the directory.
+func writePolicies(t *testing.T, policies map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, policy := range policies {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatalf("create directory: %v", err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(policy), 0600); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+	}
+
+	return dir
+}
+
+func TestCompleteRules(t *testing.T) {
+	testCases := []struct {
+		name             string
+		policy           string
+		expectedFailures []string
+	}{
+		{
+			name: "complete rule returning a string",
+			policy: `package main
+
+deny = msg {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`,
+			expectedFailures: []string{"deployments are not allowed"},
+		},
+		{
+			name: "complete rule that does not match",
+			policy: `package main
+
+deny = msg {
+	input.kind == "Service"
+	msg := "services are not allowed"
+}`,
+		},
+		{
+			name: "partial rule",
+			policy: `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`,
+			expectedFailures: []string{"deployments are not allowed"},
+		},
+		{
+			name: "partial rule returning a number",
+			policy: `package main
+
+deny[42] {
+	input.kind == "Deployment"
+}`,
+			expectedFailures: []string{"42"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			engine, err := Load(ctx, []string{writePolicy(t, testCase.policy)})
+			if err != nil {
+				t.Fatalf("loading policies: %v", err)
+			}
+
+			configs := map[string]interface{}{
+				"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+			}
+
+			results, err := engine.Check(ctx, configs, "main")
+			if err != nil {
+				t.Fatalf("could not process policy file: %s", err)
+			}
+
+			var actualFailures []string
+			for _, failure := range results[0].Failures {
+				actualFailures = append(actualFailures, failure.Message)
+			}
+
+			if !reflect.DeepEqual(actualFailures, testCase.expectedFailures) {
+				t.Errorf("Unexpected failures. expected %v actual %v", testCase.expectedFailures, actualFailures)
+			}
+
+			if len(actualFailures) == 0 && results[0].Successes != 1 {
+				t.Errorf("Unexpected successes. expected 1 actual %v", results[0].Successes)
+			}
+		})
+	}
+}
+
+func TestEmptyDocumentInput(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[msg] {
+	input == null
+	msg := "the document is empty"
+}`
+
+	engine, err := Load(ctx, []string{writePolicy(t, policy)})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"empty.yaml": nil,
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Message != "the document is empty" {
+		t.Errorf("expected the empty document to be null, got failures %v", results[0].Failures)
+	}
+}
+
+func TestInvalidMessages(t *testing.T) {
+	ctx := context.Background()
+
+	policy := `package main
+
+deny[42] {
+	input.kind == "Deployment"
+}
+
+warn[msg] {
+	msg := "a message"
+}`
+
+	engine, err := Load(ctx, []string{writePolicy(t, policy)})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	if messages := engine.InvalidMessages(); len(messages) != 0 {
+		t.Errorf("expected no invalid messages before a check, got %v", messages)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+		"other.yaml":      map[string]interface{}{"kind": "Deployment"},
+	}
+
+	if _, err := engine.Check(ctx, configs, "main"); err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	expected := []InvalidMessage{{Query: "data.main.deny", Value: "42"}}
+	if actual := engine.InvalidMessages(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected invalid messages. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestOrdering(t *testing.T) {
+	ctx := context.Background()
+
+	policies := []string{"../examples/kubernetes/policy"}
+	engine, err := Load(ctx, policies)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	expectedRules := []string{"deny", "violation", "warn"}
+	for i := 0; i < 10; i++ {
+		rules, _ := engine.getRules("main")
+		if !reflect.DeepEqual(rules, expectedRules) {
+			t.Fatalf("Unexpected rules. expected %v actual %v", expectedRules, rules)
+		}
+	}
+
+	configFiles := []string{"../examples/kubernetes/service.yaml", "../examples/kubernetes/deployment.yaml"}
+	configs, err := parser.ParseConfigurations(configFiles)
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	expected, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if expected[0].FileName != "../examples/kubernetes/deployment.yaml" {
+		t.Errorf("Unexpected first result. expected %v actual %v", "../examples/kubernetes/deployment.yaml", expected[0].FileName)
+	}
+
+	for i := 0; i < 10; i++ {
+		actual, err := engine.Check(ctx, configs, "main")
+		if err != nil {
+			t.Fatalf("could not process policy file: %s", err)
+		}
+
+		if !reflect.DeepEqual(expected, actual) {
+			t.Fatalf("Unexpected results. expected %v actual %v", expected, actual)
+		}
+	}
+}
+
+func TestLibraries(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+import data.lib.kubernetes
+
+deny[msg] {
+	kubernetes.is_deployment
+	msg := "deployments are not allowed"
+}`)
+
+	libraryDir := writePolicies(t, map[string]string{"kubernetes.rego": `package lib.kubernetes
+
+is_deployment {
+	input.kind == "Deployment"
+}
+
+deny[msg] {
+	msg := "library rules should not be evaluated"
+}`})
+
+	engine, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{Libraries: []string{libraryDir}})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	if namespaces := engine.Namespaces(); !reflect.DeepEqual(namespaces, []string{"main"}) {
+		t.Errorf("Unexpected namespaces. expected %v actual %v", []string{"main"}, namespaces)
+	}
+
+	if rules, _ := engine.getRules("lib.kubernetes"); len(rules) != 0 {
+		t.Errorf("Unexpected library rules. expected none actual %v", rules)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	const expectedFailures = 1
+	actualFailures := len(results[0].Failures)
+	if actualFailures != expectedFailures {
+		t.Errorf("Library test failure. Got %v failures, expected %v", actualFailures, expectedFailures)
+	}
+}
+
+func TestLibrariesOverlappingPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`)
+
+	libraryDir := writePolicies(t, map[string]string{"main.rego": `package main
+
+deny[msg] {
+	msg := "library rules should not be evaluated"
+}`})
+
+	testCases := []struct {
+		desc      string
+		libraries []string
+		expected  string
+	}{
+		{
+			desc:      "same package",
+			libraries: []string{libraryDir},
+			expected:  "declares package main, which is also declared by the policy",
+		},
+		{
+			desc:      "same path",
+			libraries: []string{policyDir},
+			expected:  "is also loaded as a policy",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{Libraries: tc.libraries})
+			if err == nil {
+				t.Fatal("expected an error when a library overlaps the policies")
+			}
+
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("Unexpected error. expected %q actual %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutPolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("path does not exist", func(t *testing.T) {
+		policyDir := filepath.Join(t.TempDir(), "missing")
+
+		_, err := Load(ctx, []string{policyDir})
+		if err == nil {
+			t.Fatal("expected an error when loading a missing policy path")
+		}
+
+		if !strings.Contains(err.Error(), "does not exist") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	t.Run("path without policies", func(t *testing.T) {
+		policyDir := t.TempDir()
+
+		_, err := Load(ctx, []string{policyDir})
+		if err == nil {
+			t.Fatal("expected an error when loading a policy path without policies")
+		}
+
+		if !strings.Contains(err.Error(), "no policies found") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+}
+
+func TestLoadFS(t *testing.T) {
+	ctx := context.Background()
+
+	fsys := fstest.MapFS{
+		"policy/deny.rego": &fstest.MapFile{Data: []byte(`package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`)},
+		"policy/README.md": &fstest.MapFile{Data: []byte("not a policy")},
+	}
+
+	engine, err := LoadFS(ctx, fsys, []string{"policy"})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	if _, ok := engine.Policies()["policy/deny.rego"]; !ok {
+		t.Errorf("Unexpected policies. expected policy/deny.rego actual %v", engine.Policies())
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	const expectedFailures = 1
+	actualFailures := len(results[0].Failures)
+	if actualFailures != expectedFailures {
+		t.Errorf("LoadFS test failure. Got %v failures, expected %v", actualFailures, expectedFailures)
+	}
+
+	if _, err := LoadFS(ctx, fsys, []string{"missing"}); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("Unexpected error for missing path: %v", err)
+	}
+}
+
+func TestMultiDocumentIndex(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[msg] {
+	msg := sprintf("%v is not allowed", [input.kind])
+}`)
+
+	configDir := t.TempDir()
+	config := "kind: Service\n---\nkind: Deployment\n---\nkind: ConfigMap\n"
+	configPath := filepath.Join(configDir, "manifests.yaml")
+	if err := ioutil.WriteFile(configPath, []byte(config), 0600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs, err := parser.ParseConfigurations([]string{configPath})
+	if err != nil {
+		t.Fatalf("loading configs: %v", err)
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	var actual []int
+	for _, failure := range results[0].Failures {
+		if failure.Document == nil {
+			t.Fatalf("Unexpected document. expected an index for %v", failure.Message)
+		}
+
+		actual = append(actual, *failure.Document)
+	}
+
+	expected := []int{0, 1, 2}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected document indices. expected %v actual %v", expected, actual)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := output.NewJSON(buf).Output(results); err != nil {
+		t.Fatalf("output json: %v", err)
+	}
+
+	for _, index := range expected {
+		if !strings.Contains(buf.String(), fmt.Sprintf(`"document": %d`, index)) {
+			t.Errorf("Unexpected output. expected document %v in %v", index, buf.String())
+		}
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[msg] {
+	response := http.send({"method": "get", "url": "https://example.com"})
+	response.status_code != 200
+	msg := "unexpected status code"
+}`)
+
+	capabilities, err := LoadCapabilities(CapabilitiesSafe)
+	if err != nil {
+		t.Fatalf("loading capabilities: %v", err)
+	}
+
+	_, err = LoadWithOptions(ctx, []string{policyDir}, nil, Options{Capabilities: capabilities})
+	if err == nil {
+		t.Fatal("expected an error when a policy uses a disallowed builtin")
+	}
+
+	if !strings.Contains(err.Error(), "http.send") {
+		t.Errorf("Unexpected error. expected the disallowed builtin to be named, got %v", err)
+	}
+
+	if _, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{}); err != nil {
+		t.Errorf("Unexpected error without capabilities: %v", err)
+	}
+}
+
+func TestExcludeTestFiles(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicies(t, map[string]string{
+		"policy.rego": `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`,
+		"policy_test.rego": `package main
+
+deny_helper[msg] {
+	msg := "test helpers should not be evaluated"
+}`,
+	})
+
+	testCases := []struct {
+		options  Options
+		expected []string
+	}{
+		{options: Options{ExcludeTestFiles: true}, expected: []string{"deny"}},
+		{options: Options{}, expected: []string{"deny", "deny_helper"}},
+	}
+
+	for _, testCase := range testCases {
+		engine, err := LoadWithOptions(ctx, []string{policyDir}, nil, testCase.options)
+		if err != nil {
+			t.Fatalf("loading policies: %v", err)
+		}
+
+		if rules, _ := engine.getRules("main"); !reflect.DeepEqual(rules, testCase.expected) {
+			t.Errorf("Unexpected rules. expected %v actual %v", testCase.expected, rules)
+		}
+	}
+}
+
+func TestDeprecations(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}
+
+violation_latest[{"msg": msg}] {
+	input.image == "nginx:latest"
+	msg := "images must not use the latest tag"
+}`)
+	policyPath := filepath.Join(policyDir, "policy.rego")
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	expected := []Deprecation{
+		{
+			Namespace:   "main",
+			Rule:        "violation_latest",
+			Replacement: "deny_latest",
+			Location:    policyPath + ":8",
+		},
+	}
+
+	actual := engine.Deprecations("main")
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected deprecations. expected %v actual %v", expected, actual)
+	}
+
+	if deprecations := engine.Deprecations("other"); len(deprecations) > 0 {
+		t.Errorf("Unexpected deprecations for other namespace: %v", deprecations)
+	}
+}
+
+func TestLoadSource(t *testing.T) {
+	ctx := context.Background()
+
+	source := `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`
+
+	engine, err := LoadSourceWithOptions(ctx, "stdin.rego", source, nil, Options{})
+	if err != nil {
+		t.Fatalf("loading policy source: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy source: %s", err)
+	}
+
+	const expectedFailures = 1
+	actualFailures := len(results[0].Failures)
+	if actualFailures != expectedFailures {
+		t.Errorf("LoadSource test failure. Got %v failures, expected %v", actualFailures, expectedFailures)
+	}
+
+	invalidSources := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{name: "empty", source: "", expected: "source is empty"},
+		{name: "missing package", source: "deny[msg] { msg := \"denied\" }", expected: "stdin.rego:1"},
+		{name: "compile error", source: "package main\n\ndeny[msg] {\n\tmsg := undefined_function(input)\n}", expected: "stdin.rego:4"},
+	}
+
+	for _, invalidSource := range invalidSources {
+		t.Run(invalidSource.name, func(t *testing.T) {
+			_, err := LoadSourceWithOptions(ctx, "stdin.rego", invalidSource.source, nil, Options{})
+			if err == nil || !strings.Contains(err.Error(), invalidSource.expected) {
+				t.Errorf("Unexpected error. expected %v actual %v", invalidSource.expected, err)
+			}
+		})
+	}
+}
+
+func TestBundles(t *testing.T) {
+	ctx := context.Background()
+
+	bundleDir := writePolicies(t, map[string]string{
+		".manifest": `{"revision": "1"}`,
+		"kinds/policy.rego": `package main
+
+deny[msg] {
+	input.kind == data.kinds.forbidden[_]
+	msg := sprintf("%v is not allowed", [input.kind])
+}`,
+		"kinds/data.json": `{"forbidden": ["Deployment"]}`,
+	})
+
+	engine, err := LoadWithOptions(ctx, nil, nil, Options{Bundles: []string{bundleDir}})
+	if err != nil {
+		t.Fatalf("loading bundle: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+		"service.yaml":    map[string]interface{}{"kind": "Service"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process bundle: %s", err)
+	}
+
+	var actualFailures []string
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			actualFailures = append(actualFailures, failure.Message)
+		}
+	}
+
+	expectedFailures := []string{"Deployment is not allowed"}
+	if !reflect.DeepEqual(expectedFailures, actualFailures) {
+		t.Errorf("Unexpected failures. expected %v actual %v", expectedFailures, actualFailures)
+	}
+}
+
+func TestWasmBundles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("wasm module", func(t *testing.T) {
+		policyDir := writePolicies(t, map[string]string{"policy.wasm": "\x00asm\x01\x00\x00\x00"})
+
+		_, err := Load(ctx, []string{filepath.Join(policyDir, "policy.wasm")})
+		if err == nil || !strings.Contains(err.Error(), "use the bundle built with opa build -t wasm") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	})
+
+	bundleDir := writePolicies(t, map[string]string{
+		".manifest": `{"revision": "1"}`,
+		"policy.rego": `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`,
+		"policy.wasm": "\x00asm\x01\x00\x00\x00",
+	})
+
+	t.Run("bundle with a wasm module", func(t *testing.T) {
+		engine, err := LoadWithOptions(ctx, nil, nil, Options{Bundles: []string{bundleDir}})
+		if !wasmSupported {
+			if err == nil || !strings.Contains(err.Error(), "opa_wasm build tag") {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			return
+		}
+
+		if err != nil {
+			t.Fatalf("loading bundle: %v", err)
+		}
+
+		if rules := engine.Rules("main"); !reflect.DeepEqual([]string{"deny"}, rules) {
+			t.Errorf("Unexpected rules. expected [deny] actual %v", rules)
+		}
+	})
+
+	t.Run("bundle with a wasm module and other policies", func(t *testing.T) {
+		policyDir := writePolicies(t, map[string]string{"policy.rego": "package other"})
+
+		_, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{Bundles: []string{bundleDir}})
+		expected := "cannot be combined with other policies"
+		if !wasmSupported {
+			expected = "opa_wasm build tag"
+		}
+
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("Unexpected error. expected %v actual %v", expected, err)
+		}
+	})
+}
+
+func TestGroupData(t *testing.T) {
+	ctx := context.Background()
+
+	dir := writePolicies(t, map[string]string{
+		"policy/policy.rego": `package main
+
+deny[msg] {
+	kind := input[_].contents.kind
+	kind == data.forbidden[_][_]
+	msg := sprintf("%v is not allowed", [kind])
+}`,
+		"data/global.json":                       `{"forbidden": {"global": ["Pod"]}}`,
+		"groups/services/payments/payments.json": `{"forbidden": {"payments": ["Deployment"]}}`,
+	})
+
+	options := Options{GroupData: filepath.Join(dir, "groups")}
+	engine, err := LoadWithOptions(ctx, []string{filepath.Join(dir, "policy")}, []string{filepath.Join(dir, "data")}, options)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"services/payments/deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+		"services/payments/pod.yaml":        map[string]interface{}{"kind": "Pod"},
+		"services/orders/deployment.yaml":   map[string]interface{}{"kind": "Deployment"},
+		"services/orders/pod.yaml":          map[string]interface{}{"kind": "Pod"},
+	}
+
+	results, err := engine.CheckCombinedBy(ctx, configs, "main", "dir")
+	if err != nil {
+		t.Fatalf("check combined by directory: %v", err)
+	}
+
+	actual := make(map[string][]string)
+	for _, result := range results {
+		var messages []string
+		for _, failure := range result.Failures {
+			messages = append(messages, failure.Message)
+		}
+		sort.Strings(messages)
+
+		actual[result.FileName] = messages
+	}
+
+	// The group data is loaded alongside the global data, and the
+	// groups without a data directory fall back to the global data.
+	expected := map[string][]string{
+		"Combined/services/orders":   {"Pod is not allowed"},
+		"Combined/services/payments": {"Deployment is not allowed", "Pod is not allowed"},
+	}
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected failures. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestMergeDocuments(t *testing.T) {
+	destination := map[string]interface{}{
+		"kinds": map[string]interface{}{"forbidden": []interface{}{"Deployment"}},
+	}
+
+	source := map[string]interface{}{
+		"kinds":   map[string]interface{}{"allowed": []interface{}{"Service"}},
+		"regions": []interface{}{"eu-west-1"},
+	}
+
+	if err := mergeDocuments(destination, source); err != nil {
+		t.Fatalf("merge documents: %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"kinds": map[string]interface{}{
+			"forbidden": []interface{}{"Deployment"},
+			"allowed":   []interface{}{"Service"},
+		},
+		"regions": []interface{}{"eu-west-1"},
+	}
+
+	if !reflect.DeepEqual(expected, destination) {
+		t.Errorf("Unexpected documents. expected %v actual %v", expected, destination)
+	}
+
+	conflicting := map[string]interface{}{
+		"kinds": map[string]interface{}{"forbidden": []interface{}{"Service"}},
+	}
+
+	if err := mergeDocuments(destination, conflicting); err == nil {
+		t.Error("expected an error for conflicting documents")
+	}
+}
+
+func TestPolicySource(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicies(t, map[string]string{
+		"deployment.rego": `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`,
+		"service.rego": `package main
+
+deny[msg] {
+	input.kind == "Service"
+	msg := "services are not allowed"
+}`,
+		"warn.rego": `package main
+
+warn[msg] {
+	msg := "always warn"
+}`,
+	})
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if source := results[0].Failures[0].PolicySource; source != nil {
+		t.Errorf("Unexpected policy source when not enabled: %v", source)
+	}
+
+	engine.EnablePolicySource()
+	results, err = engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	expected := []string{
+		filepath.ToSlash(filepath.Join(policyDir, "deployment.rego")),
+		filepath.ToSlash(filepath.Join(policyDir, "service.rego")),
+	}
+	if actual := results[0].Failures[0].PolicySource; !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected policy source. expected %v actual %v", expected, actual)
+	}
+
+	expectedWarning := []string{filepath.ToSlash(filepath.Join(policyDir, "warn.rego"))}
+	if actual := results[0].Warnings[0].PolicySource; !reflect.DeepEqual(expectedWarning, actual) {
+		t.Errorf("Unexpected policy source. expected %v actual %v", expectedWarning, actual)
+	}
+}
+
+func TestSetRule(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny_kind[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}
+
+deny_name[msg] {
+	input.metadata.name == "test"
+	msg := "test is not a valid name"
+}
+
+warn_kind[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are discouraged"
+}`)
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	engine.SetRule("deny_kind")
+	if rules := engine.Rules("main"); !reflect.DeepEqual(rules, []string{"deny_kind"}) {
+		t.Errorf("Unexpected rules. expected [deny_kind] actual %v", rules)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment", "metadata": map[string]interface{}{"name": "test"}},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 || len(results[0].Warnings) != 0 || results[0].Successes != 0 {
+		t.Errorf("Unexpected results. expected a single failure actual %+v", results[0])
+	}
+
+	engine.SetRule("deny")
+	if rules := engine.Rules("main"); len(rules) > 0 {
+		t.Errorf("Unexpected rules for a partial name: %v", rules)
+	}
+}
+
+func TestBuiltinErrors(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny_invalid_config[msg] {
+	config := json.unmarshal(input.config)
+	config.debug
+	msg := "debug must not be enabled"
+}
+
+deny_kind[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`)
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+	engine.EnableBuiltinErrors()
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment", "config": "{not json"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Errors) != 1 || results[0].Errors[0].Rule != "deny_invalid_config" {
+		t.Errorf("Unexpected errors. expected an error for deny_invalid_config actual %v", results[0].Errors)
+	}
+
+	if len(results[0].Failures) != 1 {
+		t.Errorf("Unexpected failures. expected the other rules to be evaluated actual %v", results[0].Failures)
+	}
+}
+
+func TestReload(t *testing.T) {
+	ctx := context.Background()
+
+	reloadPolicy := func(msg string) string {
+		return fmt.Sprintf(`package main
+
+deny[msg] {
+	msg := %q
+}`, msg)
+	}
+
+	policyDir := writePolicy(t, reloadPolicy("before reload"))
+	updatePolicy := func(policy string) {
+		if err := ioutil.WriteFile(filepath.Join(policyDir, "policy.rego"), []byte(policy), 0600); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+	}
+
+	engine, err := LoadWithData(ctx, []string{policyDir}, nil)
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	// Checks that run while the engine is reloaded must not be affected by it,
+	// and neither must the methods that read the loaded policies, which the
+	// race detector reports when they are not guarded (go test -race).
+	done := make(chan error)
+	go func() {
+		for i := 0; i < 50; i++ {
+			if _, err := engine.Check(ctx, configs, "main"); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	go func() {
+		for i := 0; i < 50; i++ {
+			if namespaces := engine.Namespaces(); len(namespaces) != 1 || namespaces[0] != "main" {
+				done <- fmt.Errorf("unexpected namespaces: %v", namespaces)
+				return
+			}
+
+			if rules := engine.Rules("main"); len(rules) != 1 {
+				done <- fmt.Errorf("unexpected rules: %v", rules)
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	updatePolicy(reloadPolicy("after reload"))
+	if err := engine.Reload(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("read engine during reload: %v", err)
+		}
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Message != "after reload" {
+		t.Errorf("Unexpected results. expected the failure of the reloaded policy actual %+v", results[0])
+	}
+
+	// A policy that fails to compile keeps the policies that were loaded.
+	updatePolicy("package main\n\ndeny[msg] {")
+
+	if err := engine.Reload(ctx); err == nil {
+		t.Fatal("expected an error when reloading an invalid policy")
+	}
+
+	results, err = engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Message != "after reload" {
+		t.Errorf("Unexpected results. expected the failure of the last loaded policy actual %+v", results[0])
+	}
+}
+
+func TestMessageIDAndArgs(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[{"msg": msg, "id": "K8S-001", "args": [name]}] {
+	input.kind == "Deployment"
+	name := input.metadata.name
+	msg := sprintf("Deployment %v must not run as root", [name])
+}`)
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment", "metadata": map[string]interface{}{"name": "hello-kubernetes"}},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Failures) != 1 {
+		t.Fatalf("Unexpected results. expected a single failure actual %+v", results[0])
+	}
+
+	failure := results[0].Failures[0]
+	if failure.Message != "Deployment hello-kubernetes must not run as root" {
+		t.Errorf("Unexpected message. expected the message in the default language actual %v", failure.Message)
+	}
+
+	expected := map[string]interface{}{
+		"id":   "K8S-001",
+		"args": []interface{}{"hello-kubernetes"},
+	}
+
+	if !reflect.DeepEqual(expected, failure.Metadata) {
+		t.Errorf("Unexpected metadata. expected %v actual %v", expected, failure.Metadata)
+	}
+}
+
+func TestFailOnCompileWarnings(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[msg] {
+	unused := input.metadata.name
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`)
+
+	if _, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{}); err != nil {
+		t.Fatalf("Unexpected error when compiler warnings are ignored: %v", err)
+	}
+
+	_, err := LoadWithOptions(ctx, []string{policyDir}, nil, Options{FailOnCompileWarnings: true})
+	if err == nil {
+		t.Fatal("expected an error when failing on compiler warnings")
+	}
+
+	if !strings.Contains(err.Error(), "compiler warnings") || !strings.Contains(err.Error(), "policy.rego:4") {
+		t.Errorf("Unexpected error. expected the warning and its location, got %v", err)
+	}
+}
+
+func TestNamespaceFallback(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicies(t, map[string]string{
+		"common.rego": `package org.common
+
+deny_latest_tag[msg] {
+	input.image == "nginx:latest"
+	msg := "common: the latest tag is not allowed"
+}
+
+deny_privileged[msg] {
+	input.privileged
+	msg := "common: privileged containers are not allowed"
+}`,
+		"team.rego": `package org.team
+
+deny_latest_tag[msg] {
+	input.image == "nginx:latest"
+	msg := "team: the latest tag is not allowed"
+}`,
+	})
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"pod.yaml": map[string]interface{}{"image": "nginx:latest", "privileged": true},
+	}
+
+	engine.SetNamespaceFallback([]string{"org.team", "org.common"})
+
+	if rules := engine.Rules("org.common"); !reflect.DeepEqual(rules, []string{"deny_privileged"}) {
+		t.Errorf("Unexpected common rules. expected [deny_privileged] actual %v", rules)
+	}
+
+	var messages []string
+	for _, namespace := range []string{"org.team", "org.common"} {
+		results, err := engine.Check(ctx, configs, namespace)
+		if err != nil {
+			t.Fatalf("could not process policy file: %s", err)
+		}
+
+		for _, failure := range results[0].Failures {
+			messages = append(messages, failure.Message)
+		}
+	}
+
+	expected := []string{"team: the latest tag is not allowed", "common: privileged containers are not allowed"}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Unexpected failures. expected %v actual %v", expected, messages)
+	}
+
+	engine.SetNamespaceFallback(nil)
+	if rules := engine.Rules("org.common"); !reflect.DeepEqual(rules, []string{"deny_latest_tag", "deny_privileged"}) {
+		t.Errorf("Unexpected common rules without a fallback. expected [deny_latest_tag deny_privileged] actual %v", rules)
+	}
+}
+
+func TestUntestedRules(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicies(t, map[string]string{
+		"policy.rego": `package main
+
+deny_root[msg] {
+	input.user == "root"
+	msg := "containers must not run as root"
+}
+
+deny_latest[msg] {
+	input.image == "nginx:latest"
+	msg := "images must not use the latest tag"
+}
+
+warn_limits[msg] {
+	not input.limits
+	msg := "containers should have limits"
+}
+
+warn_limits[msg] {
+	input.limits == {}
+	msg := "containers should have limits"
+}
+
+is_root {
+	input.user == "root"
+}`,
+		"policy_test.rego": `package main
+
+test_deny_root {
+	deny_root with input as {"user": "root"}
+}`,
+		"other_test.rego": `package other
+
+test_latest {
+	count(data.main.deny_latest) == 0 with input as {"image": "nginx:1.25"}
+}`,
+	})
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	expected := []UntestedRule{
+		{
+			Namespace: "main",
+			Rule:      "warn_limits",
+			Location:  filepath.Join(policyDir, "policy.rego") + ":13",
+		},
+	}
+
+	actual := engine.UntestedRules()
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected untested rules. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestRuleDefinitions(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicies(t, map[string]string{
+		"deny.rego": `package main
+
+deny_root[msg] {
+	input.user == "root"
+	msg := "containers must not run as root"
+}
+
+warn[msg] {
+	not input.limits
+	msg := "containers should have limits"
+}`,
+		"warn.rego": `package main
+
+warn[msg] {
+	input.limits == {}
+	msg := "containers should have limits"
+}`,
+		"exception.rego": `package main
+
+exception[rules] {
+	input.name == "debug"
+	rules := ["root"]
+}
+
+is_root {
+	input.user == "root"
+}`,
+	})
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	source := func(name string) string {
+		return filepath.ToSlash(filepath.Join(policyDir, name))
+	}
+
+	expected := []RuleDefinition{
+		{Namespace: "main", Rule: "deny_root", Kind: RuleKindFailure, Sources: []string{source("deny.rego")}},
+		{Namespace: "main", Rule: "exception", Kind: RuleKindException, Sources: []string{source("exception.rego")}},
+		{Namespace: "main", Rule: "warn", Kind: RuleKindWarning, Sources: []string{source("deny.rego"), source("warn.rego")}},
+	}
+
+	actual := engine.RuleDefinitions("main")
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected rule definitions. expected %v actual %v", expected, actual)
+	}
+
+	if definitions := engine.RuleDefinitions("other"); len(definitions) != 0 {
+		t.Errorf("Unexpected rule definitions in other namespace. expected none actual %v", definitions)
+	}
+}
+
+func TestRuleDescriptions(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicies(t, map[string]string{
+		"policy.rego": `package main
+
+# Containers must not run
+# as root.
+deny_root[msg] {
+	input.user == "root"
+	msg := "containers must not run as root"
+}
+
+# METADATA
+# title: Limits
+# description: Containers should have limits
+warn_limits[msg] {
+	not input.limits
+	msg := "containers should have limits"
+}
+
+# This comment is not attached to the rule.
+
+deny_latest[msg] {
+	input.tag == "latest"
+	msg := "images must not use the latest tag"
+}`,
+	})
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"pod.yaml": map[string]interface{}{"user": "root", "tag": "latest"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policies: %s", err)
+	}
+
+	expected := map[string]string{
+		"deny_root":   "Containers must not run as root.",
+		"warn_limits": "Containers should have limits",
+		"deny_latest": "",
+	}
+
+	actual := make(map[string]string)
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			actual[failure.Rule] = failure.Description
+		}
+		for _, warning := range result.Warnings {
+			actual[warning.Rule] = warning.Description
+		}
+	}
+
+	if !reflect.DeepEqual(expected, actual) {
+		t.Errorf("Unexpected descriptions. expected %v actual %v", expected, actual)
+	}
+}
+
+func TestPolicyRoot(t *testing.T) {
+	ctx := context.Background()
+
+	baseDir := writePolicies(t, map[string]string{"base.rego": `package main
+
+deny_shared[msg] {
+	input.user == "root"
+	msg := "containers must not run as root"
+}
+
+warn[msg] {
+	not input.limits
+	msg := "missing resource limits"
+}`})
+
+	overlayDir := writePolicies(t, map[string]string{"overlay.rego": `package main
+
+deny_local[msg] {
+	not input.labels.team
+	msg := "the team label is required"
+}
+
+warn[msg] {
+	not input.labels.owner
+	msg := "missing owner label"
+}`})
+
+	engine, err := LoadWithOptions(ctx, []string{baseDir, overlayDir}, nil, Options{})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+	engine.EnablePolicyRoot()
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"user": "root", "labels": map[string]interface{}{}},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policies: %s", err)
+	}
+
+	actualFailures := make(map[string]interface{})
+	actualWarnings := make(map[string]interface{})
+	for _, result := range results {
+		for _, failure := range result.Failures {
+			actualFailures[failure.Message] = failure.Metadata["policy_root"]
+		}
+
+		for _, warning := range result.Warnings {
+			actualWarnings[warning.Message] = warning.Metadata["policy_root"]
+		}
+	}
+
+	expectedFailures := map[string]interface{}{
+		"containers must not run as root": baseDir,
+		"the team label is required":      overlayDir,
+	}
+	if !reflect.DeepEqual(expectedFailures, actualFailures) {
+		t.Errorf("Unexpected failure policy roots. expected %v actual %v", expectedFailures, actualFailures)
+	}
+
+	// The warn rule is defined in both roots, so the root of a warning is not known.
+	bothRoots := []string{baseDir, overlayDir}
+	sort.Strings(bothRoots)
+	expectedWarnings := map[string]interface{}{
+		"missing resource limits": bothRoots,
+		"missing owner label":     bothRoots,
+	}
+	if !reflect.DeepEqual(expectedWarnings, actualWarnings) {
+		t.Errorf("Unexpected warning policy roots. expected %v actual %v", expectedWarnings, actualWarnings)
+	}
+}
+
+func TestRawResults(t *testing.T) {
+	ctx := context.Background()
+
+	policyDir := writePolicy(t, `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`)
+
+	engine, err := Load(ctx, []string{policyDir})
+	if err != nil {
+		t.Fatalf("loading policies: %v", err)
+	}
+
+	configs := map[string]interface{}{
+		"deployment.yaml": map[string]interface{}{"kind": "Deployment"},
+	}
+
+	results, err := engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	for _, query := range results[0].Queries {
+		if query.Raw != nil {
+			t.Errorf("Unexpected raw result of %v. expected none actual %v", query.Query, query.Raw)
+		}
+	}
+
+	engine.EnableRawResults()
+	results, err = engine.Check(ctx, configs, "main")
+	if err != nil {
+		t.Fatalf("could not process policy file: %s", err)
+	}
+
+	if len(results[0].Queries) == 0 {
+		t.Fatal("expected the queries of the check result")
+	}
+
+	for _, query := range results[0].Queries {
+		if query.Raw == nil {
+			t.Errorf("Expected the raw result of %v", query.Query)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-policy-agent/opa/topdown"
+)
+
+// The defined trace formats represent all of the supported formats
+// that traces can be rendered in.
+const (
+	TraceFormatPretty = "pretty"
+	TraceFormatJSON   = "json"
+)
+
+// TraceFormats returns the available trace formats.
+func TraceFormats() []string {
+	return []string{
+		TraceFormatPretty,
+		TraceFormatJSON,
+	}
+}
+
+// TraceEvent is the structured representation of a single trace event
+// that is used when traces are rendered as JSON.
+type TraceEvent struct {
+	Op       string         `json:"op"`
+	QueryID  uint64         `json:"query_id"`
+	ParentID uint64         `json:"parent_id"`
+	Node     string         `json:"node,omitempty"`
+	Location *TraceLocation `json:"location,omitempty"`
+	Message  string         `json:"message,omitempty"`
+}
+
+// TraceLocation is the location in a policy that a trace event refers to.
+type TraceLocation struct {
+	File string `json:"file"`
+	Row  int    `json:"row"`
+	Col  int    `json:"col"`
+}
+
+// JSONTrace converts the trace events into trace lines, where each line
+// is a single trace event serialized as JSON.
+func JSONTrace(events []*topdown.Event) ([]string, error) {
+	var traces []string
+	for _, event := range events {
+		traceEvent := TraceEvent{
+			Op:       string(event.Op),
+			QueryID:  event.QueryID,
+			ParentID: event.ParentID,
+			Message:  event.Message,
+		}
+
+		if event.Node != nil {
+			traceEvent.Node = fmt.Sprint(event.Node)
+		}
+
+		if event.Location != nil {
+			traceEvent.Location = &TraceLocation{
+				File: event.Location.File,
+				Row:  event.Location.Row,
+				Col:  event.Location.Col,
+			}
+		}
+
+		line, err := json.Marshal(traceEvent)
+		if err != nil {
+			return nil, fmt.Errorf("marshal trace event: %w", err)
+		}
+
+		traces = append(traces, string(line))
+	}
+
+	return traces, nil
+}
@@ -0,0 +1,8 @@
+//go:build opa_wasm
+// +build opa_wasm
+
+package policy
+
+// wasmSupported reports whether the OPA WASM runtime is part of the build. The
+// runtime requires cgo, so it is only built with the opa_wasm build tag.
+const wasmSupported = true
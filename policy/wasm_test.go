@@ -0,0 +1,79 @@
+//go:build opa_wasm
+// +build opa_wasm
+
+package policy
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReloadWasmBundle(t *testing.T) {
+	ctx := context.Background()
+
+	bundleDir := writePolicies(t, map[string]string{
+		".manifest": `{"revision": "1"}`,
+		"policy.rego": `package main
+
+deny[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are not allowed"
+}`,
+		"policy.wasm": "\x00asm\x01\x00\x00\x00",
+	})
+
+	engine, err := LoadWithOptions(ctx, nil, nil, Options{Bundles: []string{bundleDir}})
+	if err != nil {
+		t.Fatalf("loading bundle: %v", err)
+	}
+
+	if engine.wasmBundle == nil || engine.wasmBundlePath != bundleDir {
+		t.Fatalf("Unexpected wasm bundle. expected %v actual %v", bundleDir, engine.wasmBundlePath)
+	}
+
+	// A new module in the bundle replaces the module that is evaluated.
+	module := []byte("\x00asm\x01\x00\x00\x00\x00\x08\x04name\x02\x01\x00")
+	if err := ioutil.WriteFile(filepath.Join(bundleDir, "policy.wasm"), module, 0600); err != nil {
+		t.Fatalf("write module: %v", err)
+	}
+
+	if err := engine.Reload(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if engine.wasmBundle == nil || len(engine.wasmBundle.WasmModules) != 1 || !bytes.Equal(module, engine.wasmBundle.WasmModules[0].Raw) {
+		t.Errorf("Unexpected wasm module after reload. expected %v actual %v", module, engine.wasmBundle)
+	}
+
+	// Without a module, the bundle is no longer evaluated by a WASM module.
+	if err := os.Remove(filepath.Join(bundleDir, "policy.wasm")); err != nil {
+		t.Fatalf("remove module: %v", err)
+	}
+
+	policy := `package main
+
+warn[msg] {
+	input.kind == "Deployment"
+	msg := "deployments are discouraged"
+}`
+	if err := ioutil.WriteFile(filepath.Join(bundleDir, "policy.rego"), []byte(policy), 0600); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+
+	if err := engine.Reload(ctx); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	if engine.wasmBundle != nil || engine.wasmBundlePath != "" {
+		t.Errorf("Unexpected wasm bundle after reload. expected none actual %v", engine.wasmBundlePath)
+	}
+
+	if rules := engine.Rules("main"); !reflect.DeepEqual([]string{"warn"}, rules) {
+		t.Errorf("Unexpected rules after reload. expected [warn] actual %v", rules)
+	}
+}
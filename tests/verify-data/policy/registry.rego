@@ -0,0 +1,14 @@
+package main
+
+import data.fixtures
+
+deny[msg] {
+	input.kind == "Pod"
+	image := input.spec.containers[_].image
+	not allowed_registry(image)
+	msg := sprintf("image %v is not from an allowed registry", [image])
+}
+
+allowed_registry(image) {
+	startswith(image, fixtures.registries[_])
+}
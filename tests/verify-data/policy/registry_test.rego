@@ -0,0 +1,9 @@
+package main
+
+test_allowed_registry {
+	count(deny) == 0 with input as {"kind": "Pod", "spec": {"containers": [{"image": "gcr.io/app:1.0"}]}}
+}
+
+test_disallowed_registry {
+	deny["image docker.io/app:1.0 is not from an allowed registry"] with input as {"kind": "Pod", "spec": {"containers": [{"image": "docker.io/app:1.0"}]}}
+}
@@ -0,0 +1,14 @@
+#!/usr/bin/env bats
+
+@test "Can use data fixtures in unit tests" {
+  run $CONFTEST verify --data fixtures
+
+  [ "$status" -eq 0 ]
+  [[ "$output" =~ "2 tests, 2 passed" ]]
+}
+
+@test "Fail unit tests that depend on data fixtures when the data is not loaded" {
+  run $CONFTEST verify
+
+  [ "$status" -eq 1 ]
+}